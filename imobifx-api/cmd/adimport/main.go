@@ -0,0 +1,74 @@
+// Command adimport creates ads in bulk from a CSV or JSONL file, with the
+// same rules and report as POST /api/ads/import. It uses the same
+// configuration as the API.
+//
+//	adimport [-format csv|jsonl] [-all-or-nothing] [-enrich-cep] [-geocode] <file|->
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/josinaldojr/imobifx-api/internal/app"
+	"github.com/josinaldojr/imobifx-api/internal/config"
+	"github.com/josinaldojr/imobifx-api/internal/http/requests"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	format := flag.String("format", "", "csv or jsonl (default: from the file extension)")
+	allOrNothing := flag.Bool("all-or-nothing", false, "store nothing if any row fails")
+	enrichCEP := flag.Bool("enrich-cep", false, "fill missing address fields from the CEP")
+	geocodeAds := flag.Bool("geocode", false, "geocode rows without coordinates")
+	force := flag.Bool("force", false, "import rows that look like duplicates")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("usage: adimport [flags] <file|->")
+	}
+
+	var r io.Reader = os.Stdin
+	if path := flag.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+		if *format == "" {
+			*format = requests.ImportFormatFromName(path)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := app.RunAdImport(ctx, cfg, r, *format, usecase.ImportAdsOptions{
+		AllOrNothing: *allOrNothing,
+		EnrichCEP:    *enrichCEP,
+		Geocode:      *geocodeAds,
+		Force:        *force,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+	if report.Failed > 0 {
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,45 @@
+// Command imagegc removes stored image files no ad points at and reports ads
+// pointing at files that no longer exist. It uses the same configuration as
+// the API.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/josinaldojr/imobifx-api/internal/app"
+	"github.com/josinaldojr/imobifx-api/internal/config"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dryRun := flag.Bool("dry-run", false, "only report, do not delete anything")
+	grace := flag.Duration("grace", cfg.ImageGCGrace, "ignore objects and rows younger than this")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := app.RunImageGC(ctx, cfg, *grace, *dryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+	if len(report.Missing) > 0 {
+		os.Exit(2)
+	}
+}
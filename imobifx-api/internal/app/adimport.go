@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/josinaldojr/imobifx-api/internal/config"
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/http/requests"
+	"github.com/josinaldojr/imobifx-api/internal/integrations/geocode"
+	"github.com/josinaldojr/imobifx-api/internal/integrations/viacep"
+	"github.com/josinaldojr/imobifx-api/internal/logging"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// RunAdImport imports one CSV or JSONL file outside the API process, for the
+// adimport command. It goes through the same parser and service as
+// POST /api/ads/import.
+func RunAdImport(ctx context.Context, cfg config.Config, r io.Reader, format string, opts usecase.ImportAdsOptions) (domain.AdImportReport, error) {
+	slog.SetDefault(logging.New(cfg))
+
+	rows, err := requests.ParseAdsImport(r, format)
+	if err != nil {
+		return domain.AdImportReport{}, err
+	}
+
+	db, err := repo.NewPostgres(cfg.DBDSN)
+	if err != nil {
+		return domain.AdImportReport{}, fmt.Errorf("db: %w", err)
+	}
+	defer db.Close()
+
+	images, uploads, _, err := newStorages(ctx, cfg)
+	if err != nil {
+		return domain.AdImportReport{}, fmt.Errorf("storage: %w", err)
+	}
+
+	var geocoder service.Geocoder
+	if cfg.Geocoder == "nominatim" {
+		geocoder = geocode.NewNominatim(cfg.GeocoderBaseURL, cfg.GeocoderUserAgent, cfg.GeocoderTimeout, cfg.GeocoderInterval)
+	}
+
+	addressSvc := service.NewAddressService(viacep.NewClient(cfg.ViaCepBaseURL, cfg.ViaCepTimeout))
+	adsSvc := service.NewAdsService(db, images, uploads, geocoder, cfg.MaxImageBytes, cfg.MaxImagesPerAd, cfg.AdsRetention, cfg.AdsDefaultTTL)
+	return service.NewImportService(adsSvc, addressSvc).Import(ctx, rows, opts)
+}
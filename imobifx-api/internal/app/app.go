@@ -14,10 +14,14 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/josinaldojr/imobifx-api/internal/config"
 	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/feeds"
 	"github.com/josinaldojr/imobifx-api/internal/http"
 	middlewares "github.com/josinaldojr/imobifx-api/internal/http/midlewares"
 
+	"github.com/josinaldojr/imobifx-api/internal/integrations/geocode"
 	"github.com/josinaldojr/imobifx-api/internal/integrations/viacep"
+	"github.com/josinaldojr/imobifx-api/internal/integrations/webhook"
+	"github.com/josinaldojr/imobifx-api/internal/jobs"
 	"github.com/josinaldojr/imobifx-api/internal/logging"
 	"github.com/josinaldojr/imobifx-api/internal/repo"
 	"github.com/josinaldojr/imobifx-api/internal/service"
@@ -32,16 +36,50 @@ func Run(cfg config.Config) error {
 
 	viaCEP := viacep.NewClient(cfg.ViaCepBaseURL, cfg.ViaCepTimeout)
 
+	images, uploads, staticDir, err := newStorages(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+
+	exports, err := newExportStorage(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("export storage: %w", err)
+	}
+
+	generators, err := newFeedGenerators(cfg.Feeds)
+	if err != nil {
+		return fmt.Errorf("feeds: %w", err)
+	}
+
+	var geocoder service.Geocoder
+	if cfg.Geocoder == "nominatim" {
+		geocoder = geocode.NewNominatim(cfg.GeocoderBaseURL, cfg.GeocoderUserAgent, cfg.GeocoderTimeout, cfg.GeocoderInterval)
+	}
+
 	addressSvc := service.NewAddressService(viaCEP)
-	adsSvc := service.NewAdsService(db, cfg.ImagesDir, cfg.MaxImageBytes)
+	adsSvc := service.NewAdsService(db, images, uploads, geocoder, cfg.MaxImageBytes, cfg.MaxImagesPerAd, cfg.AdsRetention, cfg.AdsDefaultTTL)
 	quotesSvc := service.NewQuotesService(db)
+	importSvc := service.NewImportService(adsSvc, addressSvc)
+	exportSvc := service.NewExportService(db, adsSvc, exports, cfg.ExportRetention)
+	feedSvc := service.NewFeedService(adsSvc, exports, feeds.Header{
+		Provider:    cfg.Feeds.Provider,
+		Email:       cfg.Feeds.ContactEmail,
+		ContactName: cfg.Feeds.ContactName,
+		Telephone:   cfg.Feeds.ContactPhone,
+	}, cfg.Feeds.PublicURL, cfg.Feeds.ListingURL, generators...)
+	webhookSvc := service.NewWebhookService(db, webhook.NewClient(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivate),
+		cfg.Webhooks.MaxAttempts, cfg.Webhooks.RetryBase, cfg.Webhooks.RetryMax, cfg.Webhooks.Retention)
+	eventHub := service.NewEventHub(db)
 
 	log := logging.New(cfg)
 	slog.SetDefault(log)
 
+	searchSvc := service.NewSavedSearchService(db, adsSvc, newSavedSearchNotifier(cfg.SavedSearchNotifier, log))
+
+	// Routes enforce their own body limit; the server takes the largest.
 	app := fiber.New(fiber.Config{
 		AppName:      "ImobiFX",
-		BodyLimit:    cfg.BodyLimitBytes,
+		BodyLimit:    max(cfg.BodyLimitBytes, cfg.UploadBodyLimitBytes),
 		ErrorHandler: errors.FiberErrorHandler,
 	})
 
@@ -49,13 +87,52 @@ func Run(cfg config.Config) error {
 	app.Use(recover.New(recover.Config{EnableStackTrace: true}))
 	app.Use(middlewares.AccessLog(log))
 
-	app.Static("/static/images", cfg.ImagesDir)
+	if staticDir != "" {
+		app.Static(localImagesURL, staticDir)
+	}
 
 	http.RegisterRoutes(app, http.Deps{
-		Config:  cfg,
-		Address: addressSvc,
-		Ads:     adsSvc,
-		Quotes:  quotesSvc,
+		Config:   cfg,
+		Address:  addressSvc,
+		Ads:      adsSvc,
+		Quotes:   quotesSvc,
+		Import:   importSvc,
+		Export:   exportSvc,
+		Feeds:    feedSvc,
+		Searches: searchSvc,
+		Webhooks: webhookSvc,
+		Events:   eventHub,
+	})
+
+	runner := jobs.NewRunner()
+	runner.Every("ads_schedule", cfg.ScheduleInterval, adsSvc.RunSchedule)
+	runner.Every("ad_images", cfg.ImageProcessInterval, adsSvc.ProcessPendingImages)
+	runner.Every("ad_exports", cfg.ExportProcessInterval, exportSvc.ProcessPendingExports)
+	runner.Every("saved_searches", cfg.SavedSearchInterval, searchSvc.ProcessNewAds)
+	runner.Every("webhooks", cfg.Webhooks.Interval, webhookSvc.ProcessOutbox)
+	// Run returns only when the listener fails; it is restarted a second later.
+	runner.Every("events", time.Second, eventHub.Run)
+	if len(generators) > 0 {
+		runner.Every("feeds", cfg.Feeds.Interval, feedSvc.Refresh)
+	}
+	runner.Every("image_gc", cfg.ImageGCInterval, func(ctx context.Context) error {
+		report, err := adsSvc.CollectImageGarbage(ctx, cfg.ImageGCGrace, false)
+		logImageGC(log, report)
+		return err
+	})
+	runner.Every("purge_deleted_ads", cfg.PurgeInterval, func(ctx context.Context) error {
+		n, err := adsSvc.PurgeDeleted(ctx)
+		if n > 0 {
+			log.Info("ads_purged", slog.Int("count", n))
+		}
+		return err
+	})
+	runner.Every("purge_outbox", cfg.PurgeInterval, func(ctx context.Context) error {
+		n, err := webhookSvc.PurgeEvents(ctx)
+		if n > 0 {
+			log.Info("outbox_events_purged", slog.Int64("count", n))
+		}
+		return err
 	})
 
 	errCh := make(chan error, 1)
@@ -68,10 +145,18 @@ func Run(cfg config.Config) error {
 
 	select {
 	case err := <-errCh:
+		eventHub.Close()
+		_ = runner.Stop(context.Background())
 		return err
 	case <-sigCh:
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		return app.ShutdownWithContext(ctx)
+		// Open event streams would otherwise hold the shutdown until timeout.
+		eventHub.Close()
+		if err := app.ShutdownWithContext(ctx); err != nil {
+			_ = runner.Stop(ctx)
+			return err
+		}
+		return runner.Stop(ctx)
 	}
 }
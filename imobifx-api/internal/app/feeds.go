@@ -0,0 +1,27 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/josinaldojr/imobifx-api/internal/config"
+	"github.com/josinaldojr/imobifx-api/internal/feeds"
+	"github.com/josinaldojr/imobifx-api/internal/feeds/vrsync"
+)
+
+// newFeedGenerators builds the generators of the feeds enabled in FEEDS.
+func newFeedGenerators(cfg config.FeedsConfig) ([]feeds.Generator, error) {
+	var out []feeds.Generator
+	for _, name := range cfg.Names {
+		switch name {
+		case "vrsync":
+			g, err := vrsync.New(vrsync.Options{UsageType: cfg.VRSyncUsage, PropertyType: cfg.VRSyncProperty})
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, g)
+		default:
+			return nil, fmt.Errorf("unknown feed %q", name)
+		}
+	}
+	return out, nil
+}
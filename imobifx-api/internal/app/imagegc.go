@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/josinaldojr/imobifx-api/internal/config"
+	"github.com/josinaldojr/imobifx-api/internal/logging"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+// RunImageGC runs a single image garbage collection pass outside the API
+// process, for the imagegc command.
+func RunImageGC(ctx context.Context, cfg config.Config, grace time.Duration, dryRun bool) (service.ImageGCReport, error) {
+	slog.SetDefault(logging.New(cfg))
+
+	db, err := repo.NewPostgres(cfg.DBDSN)
+	if err != nil {
+		return service.ImageGCReport{}, fmt.Errorf("db: %w", err)
+	}
+	defer db.Close()
+
+	images, uploads, _, err := newStorages(ctx, cfg)
+	if err != nil {
+		return service.ImageGCReport{}, fmt.Errorf("storage: %w", err)
+	}
+
+	adsSvc := service.NewAdsService(db, images, uploads, nil, cfg.MaxImageBytes, cfg.MaxImagesPerAd, cfg.AdsRetention, cfg.AdsDefaultTTL)
+	return adsSvc.CollectImageGarbage(ctx, grace, dryRun)
+}
+
+func logImageGC(log *slog.Logger, report service.ImageGCReport) {
+	if len(report.Orphans) > 0 {
+		log.Info("image_gc_orphans_removed",
+			slog.Int("count", len(report.Orphans)),
+			slog.Int64("freed_bytes", report.FreedBytes),
+		)
+	}
+	for _, ref := range report.Missing {
+		log.Warn("image_gc_missing_object",
+			slog.String("ad_id", ref.AdID),
+			slog.String("image_id", ref.ImageID),
+			slog.String("key", ref.Key),
+			slog.Bool("staged", ref.Staged),
+		)
+	}
+}
@@ -0,0 +1,17 @@
+package app
+
+import (
+	"log/slog"
+
+	"github.com/josinaldojr/imobifx-api/internal/integrations/notify"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+// newSavedSearchNotifier builds the notifier chosen in SAVED_SEARCH_NOTIFIER;
+// with none, matches are only recorded.
+func newSavedSearchNotifier(name string, log *slog.Logger) service.SavedSearchNotifier {
+	if name == "log" {
+		return notify.NewLog(log)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josinaldojr/imobifx-api/internal/config"
+	"github.com/josinaldojr/imobifx-api/internal/storage"
+)
+
+const localImagesURL = "/static/images"
+
+// newStorages builds the public images storage and the private uploads
+// storage for the configured backend. staticDir is set when the images live
+// on local disk and have to be served by the API itself.
+func newStorages(ctx context.Context, cfg config.Config) (images, uploads storage.Storage, staticDir string, err error) {
+	switch cfg.StorageBackend {
+	case "s3":
+		s3cfg := storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			UseSSL:    cfg.S3.UseSSL,
+			Bucket:    cfg.S3.Bucket,
+			PublicURL: cfg.S3.PublicURL,
+		}
+		if images, err = storage.NewS3(ctx, s3cfg); err != nil {
+			return nil, nil, "", err
+		}
+		s3cfg.Bucket, s3cfg.PublicURL = cfg.S3.UploadsBucket, ""
+		if uploads, err = storage.NewS3(ctx, s3cfg); err != nil {
+			return nil, nil, "", err
+		}
+		return images, uploads, "", nil
+
+	case "local":
+		local, err := storage.NewLocal(cfg.ImagesDir, localImagesURL)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if uploads, err = storage.NewLocal(cfg.UploadsDir, ""); err != nil {
+			return nil, nil, "", err
+		}
+		return local, uploads, local.Dir(), nil
+	}
+	return nil, nil, "", fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+}
+
+// newExportStorage builds the private storage for ad export files and the
+// rendered portal feeds. It is kept apart from the image storages so image GC
+// never sees them.
+func newExportStorage(ctx context.Context, cfg config.Config) (storage.Storage, error) {
+	switch cfg.StorageBackend {
+	case "s3":
+		return storage.NewS3(ctx, storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			UseSSL:    cfg.S3.UseSSL,
+			Bucket:    cfg.S3.ExportsBucket,
+		})
+	case "local":
+		return storage.NewLocal(cfg.ExportsDir, "")
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+}
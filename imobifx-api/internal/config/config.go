@@ -25,11 +25,82 @@ type Config struct {
 	DBDSN string
 	ViaCepBaseURL string
 	ViaCepTimeout time.Duration
+	Geocoder string
+	GeocoderBaseURL string
+	GeocoderUserAgent string
+	GeocoderTimeout time.Duration
+	// GeocoderInterval spaces the geocoder requests of this process.
+	GeocoderInterval time.Duration
 	ImagesDir string
-	BodyLimitBytes int
+	UploadsDir string
+	ExportsDir string
+	StorageBackend string
+	S3 S3Config
+	// BodyLimitBytes caps request bodies; UploadBodyLimitBytes replaces it
+	// on the routes taking image and import uploads.
+	BodyLimitBytes       int
+	UploadBodyLimitBytes int
 	MaxImageBytes  int64
+	MaxImagesPerAd int
 	LogLevel string
 	LogFormat string
+	AdsRetention time.Duration
+	PurgeInterval time.Duration
+	AdsDefaultTTL time.Duration
+	ScheduleInterval time.Duration
+	ImageProcessInterval time.Duration
+	ImageGCInterval time.Duration
+	ImageGCGrace time.Duration
+	ExportProcessInterval time.Duration
+	ExportRetention time.Duration
+	SavedSearchInterval time.Duration
+	SavedSearchNotifier string
+	Webhooks WebhooksConfig
+	Feeds FeedsConfig
+}
+
+// S3Config holds the STORAGE_BACKEND=s3 settings. Originals are staged in
+// UploadsBucket, which must not be publicly readable; the rendered variants go
+// to Bucket. Ad exports go to ExportsBucket, also private.
+type S3Config struct {
+	Endpoint      string
+	Region        string
+	AccessKey     string
+	SecretKey     string
+	UseSSL        bool
+	Bucket        string
+	UploadsBucket string
+	ExportsBucket string
+	PublicURL     string
+}
+
+// WebhooksConfig holds the outgoing webhook settings. A failed delivery is
+// retried after RetryBase, doubling up to RetryMax between attempts, until
+// MaxAttempts; outbox events are kept for Retention. AllowPrivate lets
+// endpoints on loopback and private networks be called, for local setups.
+type WebhooksConfig struct {
+	Interval     time.Duration
+	Timeout      time.Duration
+	MaxAttempts  int
+	RetryBase    time.Duration
+	RetryMax     time.Duration
+	Retention    time.Duration
+	AllowPrivate bool
+}
+
+// FeedsConfig holds the portal feed settings. Names lists the enabled feeds
+// (FEEDS, comma separated); none are rendered while it is empty.
+type FeedsConfig struct {
+	Names          []string
+	Interval       time.Duration
+	Provider       string
+	ContactName    string
+	ContactEmail   string
+	ContactPhone   string
+	PublicURL      string
+	ListingURL     string
+	VRSyncUsage    string
+	VRSyncProperty string
 }
 
 func Load() (Config, error) {
@@ -48,11 +119,46 @@ func Load() (Config, error) {
 		Port:           getenv("APP_PORT", "8080"),
 		DBDSN:          getenv("DB_DSN", ""),
 		ViaCepBaseURL:  getenv("VIA_CEP_BASE_URL", "https://viacep.com.br"),
+		Geocoder:       strings.ToLower(getenv("GEOCODER", "none")),
+		GeocoderBaseURL:   getenv("GEOCODER_BASE_URL", "https://nominatim.openstreetmap.org"),
+		GeocoderUserAgent: getenv("GEOCODER_USER_AGENT", "imobifx-api"),
 		ImagesDir:      getenv("IMAGES_DIR", "./data/images"),
+		UploadsDir:     getenv("UPLOADS_DIR", "./data/uploads"),
+		ExportsDir:     getenv("EXPORTS_DIR", "./data/exports"),
+		StorageBackend: strings.ToLower(getenv("STORAGE_BACKEND", "local")),
+		S3: S3Config{
+			Endpoint:      getenv("S3_ENDPOINT", ""),
+			Region:        getenv("S3_REGION", "us-east-1"),
+			AccessKey:     getenv("S3_ACCESS_KEY", ""),
+			SecretKey:     getenv("S3_SECRET_KEY", ""),
+			UseSSL:        getenv("S3_USE_SSL", "true") == "true",
+			Bucket:        getenv("S3_BUCKET", ""),
+			UploadsBucket: getenv("S3_UPLOADS_BUCKET", ""),
+			ExportsBucket: getenv("S3_EXPORTS_BUCKET", ""),
+			PublicURL:     getenv("S3_PUBLIC_URL", ""),
+		},
 		BodyLimitBytes: mustInt(getenv("BODY_LIMIT_BYTES", "10485760")),
+		UploadBodyLimitBytes: mustInt(getenv("UPLOAD_BODY_LIMIT_BYTES", "104857600")),
 		MaxImageBytes:  mustInt64(getenv("MAX_IMAGE_BYTES", "5242880")),
+		MaxImagesPerAd: mustInt(getenv("MAX_IMAGES_PER_AD", "30")),
 		LogLevel:       getenv("LOG_LEVEL", "debug"),
 		LogFormat:      getenv("LOG_FORMAT", "text"),
+		SavedSearchNotifier: strings.ToLower(getenv("SAVED_SEARCH_NOTIFIER", "log")),
+		Webhooks: WebhooksConfig{
+			MaxAttempts:  mustInt(getenv("WEBHOOK_MAX_ATTEMPTS", "10")),
+			AllowPrivate: getenv("WEBHOOK_ALLOW_PRIVATE", "false") == "true",
+		},
+		Feeds: FeedsConfig{
+			Names:          splitList(getenv("FEEDS", "")),
+			Provider:       getenv("FEED_PROVIDER", "ImobiFX"),
+			ContactName:    getenv("FEED_CONTACT_NAME", ""),
+			ContactEmail:   getenv("FEED_CONTACT_EMAIL", ""),
+			ContactPhone:   getenv("FEED_CONTACT_PHONE", ""),
+			PublicURL:      getenv("FEED_PUBLIC_URL", ""),
+			ListingURL:     getenv("FEED_LISTING_URL", ""),
+			VRSyncUsage:    getenv("FEED_VRSYNC_USAGE_TYPE", "Residential"),
+			VRSyncProperty: getenv("FEED_VRSYNC_PROPERTY_TYPE", "Residential / Apartment"),
+		},
 	}
 
 	timeoutStr := getenv("VIA_CEP_TIMEOUT", "2500ms")
@@ -62,6 +168,132 @@ func Load() (Config, error) {
 	}
 	cfg.ViaCepTimeout = tout
 
+	geoTimeoutStr := getenv("GEOCODER_TIMEOUT", "3s")
+	geoTimeout, err := time.ParseDuration(geoTimeoutStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid GEOCODER_TIMEOUT=%q: %w", geoTimeoutStr, err)
+	}
+	cfg.GeocoderTimeout = geoTimeout
+
+	geoIntervalStr := getenv("GEOCODER_INTERVAL", "1s")
+	geoInterval, err := time.ParseDuration(geoIntervalStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid GEOCODER_INTERVAL=%q: %w", geoIntervalStr, err)
+	}
+	cfg.GeocoderInterval = geoInterval
+
+	retentionStr := getenv("ADS_RETENTION", "720h")
+	retention, err := time.ParseDuration(retentionStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid ADS_RETENTION=%q: %w", retentionStr, err)
+	}
+	cfg.AdsRetention = retention
+
+	purgeStr := getenv("ADS_PURGE_INTERVAL", "1h")
+	purge, err := time.ParseDuration(purgeStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid ADS_PURGE_INTERVAL=%q: %w", purgeStr, err)
+	}
+	cfg.PurgeInterval = purge
+
+	ttlStr := getenv("ADS_DEFAULT_TTL", "2160h")
+	ttl, err := time.ParseDuration(ttlStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid ADS_DEFAULT_TTL=%q: %w", ttlStr, err)
+	}
+	cfg.AdsDefaultTTL = ttl
+
+	scheduleStr := getenv("ADS_SCHEDULE_INTERVAL", "1m")
+	schedule, err := time.ParseDuration(scheduleStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid ADS_SCHEDULE_INTERVAL=%q: %w", scheduleStr, err)
+	}
+	cfg.ScheduleInterval = schedule
+
+	imageStr := getenv("IMAGE_PROCESS_INTERVAL", "2s")
+	imageInterval, err := time.ParseDuration(imageStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid IMAGE_PROCESS_INTERVAL=%q: %w", imageStr, err)
+	}
+	cfg.ImageProcessInterval = imageInterval
+
+	gcStr := getenv("IMAGE_GC_INTERVAL", "24h")
+	gcInterval, err := time.ParseDuration(gcStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid IMAGE_GC_INTERVAL=%q: %w", gcStr, err)
+	}
+	cfg.ImageGCInterval = gcInterval
+
+	graceStr := getenv("IMAGE_GC_GRACE", "24h")
+	grace, err := time.ParseDuration(graceStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid IMAGE_GC_GRACE=%q: %w", graceStr, err)
+	}
+	cfg.ImageGCGrace = grace
+
+	exportStr := getenv("EXPORT_PROCESS_INTERVAL", "5s")
+	exportInterval, err := time.ParseDuration(exportStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid EXPORT_PROCESS_INTERVAL=%q: %w", exportStr, err)
+	}
+	cfg.ExportProcessInterval = exportInterval
+
+	exportRetentionStr := getenv("EXPORT_RETENTION", "24h")
+	exportRetention, err := time.ParseDuration(exportRetentionStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid EXPORT_RETENTION=%q: %w", exportRetentionStr, err)
+	}
+	cfg.ExportRetention = exportRetention
+
+	savedSearchStr := getenv("SAVED_SEARCH_INTERVAL", "1m")
+	savedSearchInterval, err := time.ParseDuration(savedSearchStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid SAVED_SEARCH_INTERVAL=%q: %w", savedSearchStr, err)
+	}
+	cfg.SavedSearchInterval = savedSearchInterval
+
+	webhookStr := getenv("WEBHOOK_INTERVAL", "2s")
+	webhookInterval, err := time.ParseDuration(webhookStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_INTERVAL=%q: %w", webhookStr, err)
+	}
+	cfg.Webhooks.Interval = webhookInterval
+
+	webhookTimeoutStr := getenv("WEBHOOK_TIMEOUT", "10s")
+	webhookTimeout, err := time.ParseDuration(webhookTimeoutStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_TIMEOUT=%q: %w", webhookTimeoutStr, err)
+	}
+	cfg.Webhooks.Timeout = webhookTimeout
+
+	retryBaseStr := getenv("WEBHOOK_RETRY_BASE", "30s")
+	retryBase, err := time.ParseDuration(retryBaseStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_RETRY_BASE=%q: %w", retryBaseStr, err)
+	}
+	cfg.Webhooks.RetryBase = retryBase
+
+	retryMaxStr := getenv("WEBHOOK_RETRY_MAX", "6h")
+	retryMax, err := time.ParseDuration(retryMaxStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_RETRY_MAX=%q: %w", retryMaxStr, err)
+	}
+	cfg.Webhooks.RetryMax = retryMax
+
+	outboxRetentionStr := getenv("OUTBOX_RETENTION", "720h")
+	outboxRetention, err := time.ParseDuration(outboxRetentionStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid OUTBOX_RETENTION=%q: %w", outboxRetentionStr, err)
+	}
+	cfg.Webhooks.Retention = outboxRetention
+
+	feedStr := getenv("FEED_INTERVAL", "1h")
+	feedInterval, err := time.ParseDuration(feedStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid FEED_INTERVAL=%q: %w", feedStr, err)
+	}
+	cfg.Feeds.Interval = feedInterval
+
 	if err := cfg.Validate(); err != nil {
 		return Config{}, err
 	}
@@ -86,15 +318,143 @@ func (c Config) Validate() error {
 		errs = append(errs, "IMAGES_DIR is required")
 	}
 
+	switch c.StorageBackend {
+	case "local":
+		if strings.TrimSpace(c.UploadsDir) == "" {
+			errs = append(errs, "UPLOADS_DIR is required")
+		}
+		if c.UploadsDir == c.ImagesDir {
+			errs = append(errs, "UPLOADS_DIR must differ from IMAGES_DIR (originals must not be served)")
+		}
+		if strings.TrimSpace(c.ExportsDir) == "" {
+			errs = append(errs, "EXPORTS_DIR is required")
+		}
+		if c.ExportsDir == c.ImagesDir || c.ExportsDir == c.UploadsDir {
+			errs = append(errs, "EXPORTS_DIR must differ from IMAGES_DIR and UPLOADS_DIR (exports must not be served or collected as orphan images)")
+		}
+	case "s3":
+		if strings.TrimSpace(c.S3.Endpoint) == "" {
+			errs = append(errs, "S3_ENDPOINT is required")
+		}
+		if c.S3.AccessKey == "" || c.S3.SecretKey == "" {
+			errs = append(errs, "S3_ACCESS_KEY and S3_SECRET_KEY are required")
+		}
+		if strings.TrimSpace(c.S3.Bucket) == "" || strings.TrimSpace(c.S3.UploadsBucket) == "" {
+			errs = append(errs, "S3_BUCKET and S3_UPLOADS_BUCKET are required")
+		}
+		if c.S3.Bucket == c.S3.UploadsBucket {
+			errs = append(errs, "S3_UPLOADS_BUCKET must differ from S3_BUCKET (originals must not be served)")
+		}
+		if strings.TrimSpace(c.S3.ExportsBucket) == "" {
+			errs = append(errs, "S3_EXPORTS_BUCKET is required")
+		}
+		if c.S3.ExportsBucket == c.S3.Bucket || c.S3.ExportsBucket == c.S3.UploadsBucket {
+			errs = append(errs, "S3_EXPORTS_BUCKET must differ from S3_BUCKET and S3_UPLOADS_BUCKET (exports must not be served or collected as orphan images)")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("invalid STORAGE_BACKEND: %q (use local|s3)", c.StorageBackend))
+	}
+
 	if c.BodyLimitBytes <= 0 {
 		errs = append(errs, "BODY_LIMIT_BYTES must be > 0")
 	}
+	if c.UploadBodyLimitBytes <= 0 {
+		errs = append(errs, "UPLOAD_BODY_LIMIT_BYTES must be > 0")
+	}
 	if c.MaxImageBytes <= 0 {
 		errs = append(errs, "MAX_IMAGE_BYTES must be > 0")
 	}
+	if c.MaxImagesPerAd <= 0 {
+		errs = append(errs, "MAX_IMAGES_PER_AD must be > 0")
+	}
 	if c.ViaCepTimeout <= 0 {
 		errs = append(errs, "VIA_CEP_TIMEOUT must be > 0")
 	}
+	switch c.Geocoder {
+	case "none":
+	case "nominatim":
+		if strings.TrimSpace(c.GeocoderBaseURL) == "" {
+			errs = append(errs, "GEOCODER_BASE_URL is required")
+		}
+		if strings.TrimSpace(c.GeocoderUserAgent) == "" {
+			errs = append(errs, "GEOCODER_USER_AGENT is required (Nominatim usage policy)")
+		}
+		if c.GeocoderTimeout <= 0 {
+			errs = append(errs, "GEOCODER_TIMEOUT must be > 0")
+		}
+		if c.GeocoderInterval < 0 {
+			errs = append(errs, "GEOCODER_INTERVAL must be >= 0")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("invalid GEOCODER: %q (use none|nominatim)", c.Geocoder))
+	}
+	if c.AdsRetention <= 0 {
+		errs = append(errs, "ADS_RETENTION must be > 0")
+	}
+	if c.PurgeInterval <= 0 {
+		errs = append(errs, "ADS_PURGE_INTERVAL must be > 0")
+	}
+	if c.AdsDefaultTTL < 0 {
+		errs = append(errs, "ADS_DEFAULT_TTL must be >= 0 (0 disables)")
+	}
+	if c.ScheduleInterval <= 0 {
+		errs = append(errs, "ADS_SCHEDULE_INTERVAL must be > 0")
+	}
+	if c.ImageProcessInterval <= 0 {
+		errs = append(errs, "IMAGE_PROCESS_INTERVAL must be > 0")
+	}
+	if c.ImageGCInterval <= 0 {
+		errs = append(errs, "IMAGE_GC_INTERVAL must be > 0")
+	}
+	if c.ImageGCGrace < time.Hour {
+		errs = append(errs, "IMAGE_GC_GRACE must be >= 1h (uploads in flight must not be collected)")
+	}
+	if c.ExportProcessInterval <= 0 {
+		errs = append(errs, "EXPORT_PROCESS_INTERVAL must be > 0")
+	}
+	if c.ExportRetention <= 0 {
+		errs = append(errs, "EXPORT_RETENTION must be > 0")
+	}
+	if c.SavedSearchInterval <= 0 {
+		errs = append(errs, "SAVED_SEARCH_INTERVAL must be > 0")
+	}
+	if c.SavedSearchNotifier != "none" && c.SavedSearchNotifier != "log" {
+		errs = append(errs, fmt.Sprintf("invalid SAVED_SEARCH_NOTIFIER: %q (use none|log)", c.SavedSearchNotifier))
+	}
+	if c.Webhooks.Interval <= 0 {
+		errs = append(errs, "WEBHOOK_INTERVAL must be > 0")
+	}
+	if c.Webhooks.Timeout <= 0 || c.Webhooks.Timeout > time.Minute {
+		errs = append(errs, "WEBHOOK_TIMEOUT must be > 0 and <= 1m")
+	}
+	if c.Webhooks.MaxAttempts <= 0 {
+		errs = append(errs, "WEBHOOK_MAX_ATTEMPTS must be > 0")
+	}
+	if c.Webhooks.RetryBase <= 0 || c.Webhooks.RetryMax < c.Webhooks.RetryBase {
+		errs = append(errs, "WEBHOOK_RETRY_BASE must be > 0 and WEBHOOK_RETRY_MAX >= WEBHOOK_RETRY_BASE")
+	}
+	if c.Webhooks.Retention <= 0 {
+		errs = append(errs, "OUTBOX_RETENTION must be > 0")
+	}
+	for _, name := range c.Feeds.Names {
+		if name != "vrsync" {
+			errs = append(errs, fmt.Sprintf("invalid FEEDS entry: %q (use vrsync)", name))
+		}
+	}
+	if len(c.Feeds.Names) > 0 {
+		if c.Feeds.Interval <= 0 {
+			errs = append(errs, "FEED_INTERVAL must be > 0")
+		}
+		if strings.TrimSpace(c.Feeds.Provider) == "" || strings.TrimSpace(c.Feeds.ContactEmail) == "" {
+			errs = append(errs, "FEED_PROVIDER and FEED_CONTACT_EMAIL are required when FEEDS is set")
+		}
+		if c.StorageBackend == "local" && !strings.HasPrefix(c.Feeds.PublicURL, "http") {
+			errs = append(errs, "FEED_PUBLIC_URL (absolute http(s) URL of the API) is required when FEEDS is set and images are stored locally")
+		}
+		if c.Feeds.ListingURL != "" && !strings.Contains(c.Feeds.ListingURL, "{id}") {
+			errs = append(errs, "FEED_LISTING_URL must contain {id}")
+		}
+	}
 
 	if len(errs) > 0 {
 		return errors.New("config error: " + strings.Join(errs, "; "))
@@ -109,6 +469,16 @@ func getenv(k, def string) string {
 	return def
 }
 
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func mustInt(s string) int {
 	v, err := strconv.Atoi(s)
 	if err != nil {
@@ -1,18 +1,71 @@
 package domain
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AdStatusDraft   = "DRAFT"
+	AdStatusActive  = "ACTIVE"
+	AdStatusPaused  = "PAUSED"
+	AdStatusSold    = "SOLD"
+	AdStatusRented  = "RENTED"
+	AdStatusExpired = "EXPIRED"
+)
+
+var AdStatuses = []string{AdStatusDraft, AdStatusActive, AdStatusPaused, AdStatusSold, AdStatusRented, AdStatusExpired}
 
 type Ad struct {
-	ID           string    `json:"id"`
-	Type         string    `json:"type"`
-	PriceBRL     float64   `json:"price_brl"`
-	ImagePath    *string   `json:"-"`
-	CEP          string    `json:"cep"`
-	Street       string    `json:"street"`
-	Number       *string   `json:"number,omitempty"`
-	Complement   *string   `json:"complement,omitempty"`
-	Neighborhood string    `json:"neighborhood"`
-	City         string    `json:"city"`
-	State        string    `json:"state"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID       string  `json:"id"`
+	Type     string  `json:"type"`
+	Status   string  `json:"status"`
+	PriceBRL float64 `json:"price_brl"`
+	// PreviousPriceBRL is the price before the latest change, made at
+	// PriceChangedAt; both are nil while the price never changed.
+	PreviousPriceBRL *float64   `json:"previous_price_brl,omitempty"`
+	PriceChangedAt   *time.Time `json:"price_changed_at,omitempty"`
+	Title            string     `json:"title"`
+	Description      string     `json:"description"`
+	ImagePath        *string    `json:"-"`
+	Images           []AdImage  `json:"-"`
+	CEP              string     `json:"cep"`
+	Street           string     `json:"street"`
+	Number           *string    `json:"number,omitempty"`
+	Complement       *string    `json:"complement,omitempty"`
+	Neighborhood     string     `json:"neighborhood"`
+	City             string     `json:"city"`
+	State            string     `json:"state"`
+	Location         *GeoPoint  `json:"location,omitempty"`
+	Bedrooms         *int       `json:"bedrooms,omitempty"`
+	Bathrooms        *int       `json:"bathrooms,omitempty"`
+	Parking          *int       `json:"parking_spaces,omitempty"`
+	AreaM2           *float64   `json:"area_m2,omitempty"`
+	CondoFeeBRL      *float64   `json:"condo_fee_brl,omitempty"`
+	IPTUBRL          *float64   `json:"iptu_brl,omitempty"`
+	PublishAt        *time.Time `json:"publish_at,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+}
+
+// AdETag derives the entity tag of an ad from its updated_at, which the
+// repository bumps on every write.
+func AdETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 10) + `"`
+}
+
+// ETagMatches reports whether an If-Match header value (possibly a list)
+// contains etag. If-Match uses the strong comparison (RFC 9110, 13.1.1), so
+// weak tags never match.
+func ETagMatches(ifMatch, etag string) bool {
+	for _, v := range strings.Split(ifMatch, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AdOpenStatuses are the statuses of ads still on, or about to go on, the
+// market; only those are compared when looking for duplicates.
+var AdOpenStatuses = []string{AdStatusDraft, AdStatusActive, AdStatusPaused}
+
+// DuplicatePriceTolerance is how far apart (a fraction of the higher price)
+// the prices of two ads at the same address may be for them to count as
+// duplicates: agents often repost a property with a small discount.
+const DuplicatePriceTolerance = 0.10
+
+// AddressKey is the normalized address that identifies a property: case,
+// accents, punctuation and the usual abbreviations ("Av.", "apto") do not
+// tell two addresses apart.
+type AddressKey struct {
+	CEP        string
+	Street     string
+	Number     string
+	Complement string
+}
+
+func (a Ad) AddressKey() AddressKey {
+	return AddressKey{
+		CEP:        strings.TrimSpace(a.CEP),
+		Street:     normalizeAddressPart(a.Street, streetAbbreviations),
+		Number:     normalizeNumber(a.Number),
+		Complement: normalizeAddressPart(deref(a.Complement), complementAbbreviations),
+	}
+}
+
+// SimilarPrice reports whether two prices differ by at most tolerance (a
+// fraction, 0.1 for 10%) of the higher one.
+func SimilarPrice(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance*math.Max(a, b)
+}
+
+// ProbableDuplicates reports whether two ads look like the same property
+// posted twice: same type and address, prices within tolerance.
+func ProbableDuplicates(a, b Ad, tolerance float64) bool {
+	return a.Type == b.Type && a.AddressKey() == b.AddressKey() && SimilarPrice(a.PriceBRL, b.PriceBRL, tolerance)
+}
+
+type DuplicateAd struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Title     string    `json:"title"`
+	PriceBRL  float64   `json:"price_brl"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DuplicateCluster is a set of ads that are probable duplicates of each
+// other, oldest first. The address is the one of the oldest ad.
+type DuplicateCluster struct {
+	Type       string        `json:"type"`
+	CEP        string        `json:"cep"`
+	Street     string        `json:"street"`
+	Number     *string       `json:"number,omitempty"`
+	Complement *string       `json:"complement,omitempty"`
+	Ads        []DuplicateAd `json:"ads"`
+}
+
+type DuplicateClustersResponse struct {
+	PriceTolerance float64            `json:"price_tolerance"`
+	Clusters       []DuplicateCluster `json:"clusters"`
+}
+
+func ToDuplicateAd(a Ad) DuplicateAd {
+	return DuplicateAd{ID: a.ID, Status: a.Status, Title: a.Title, PriceBRL: a.PriceBRL, CreatedAt: a.CreatedAt}
+}
+
+var streetAbbreviations = map[string]string{
+	"r": "rua", "av": "avenida", "avn": "avenida", "tv": "travessa", "trav": "travessa", "al": "alameda",
+	"pc": "praca", "pca": "praca", "est": "estrada", "rod": "rodovia", "lgo": "largo", "dr": "doutor",
+	"prof": "professor", "pres": "presidente", "gov": "governador", "sen": "senador", "dep": "deputado",
+	"cel": "coronel", "gal": "general", "gen": "general",
+}
+
+var complementAbbreviations = map[string]string{
+	"apartamento": "ap", "apto": "ap", "apt": "ap", "bloco": "bl", "blc": "bl", "casa": "cs", "sala": "sl",
+	"loja": "lj", "andar": "and", "torre": "t", "tr": "t", "lote": "lt", "quadra": "qd", "qdr": "qd",
+}
+
+// normalizeAddressPart folds case and accents, drops punctuation, splits
+// letters from digits ("apto101" is "apto 101") and replaces the words found
+// in words.
+func normalizeAddressPart(s string, words map[string]string) string {
+	var fields []string
+	var cur []rune
+	for _, r := range foldAccents(strings.ToLower(s)) {
+		if len(cur) > 0 && (!unicode.IsLetter(r) && !unicode.IsDigit(r) || unicode.IsDigit(r) != unicode.IsDigit(cur[0])) {
+			fields, cur = append(fields, string(cur)), cur[:0]
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			cur = append(cur, r)
+		}
+	}
+	if len(cur) > 0 {
+		fields = append(fields, string(cur))
+	}
+	for i, f := range fields {
+		if w, ok := words[f]; ok {
+			fields[i] = w
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// normalizeNumber treats "s/n", "sn" and "sem número" as no number.
+func normalizeNumber(n *string) string {
+	v := strings.ReplaceAll(normalizeAddressPart(deref(n), nil), " ", "")
+	switch v {
+	case "sn", "semnumero", "0":
+		return ""
+	}
+	return v
+}
+
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "º", "", "ª", "",
+)
+
+func foldAccents(s string) string {
+	return accentFolder.Replace(s)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
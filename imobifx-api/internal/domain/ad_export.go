@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Export job states. Jobs start PENDING; the export worker claims them
+// (RUNNING) and either stores the file (DONE) or gives up (FAILED).
+const (
+	AdExportPending = "PENDING"
+	AdExportRunning = "RUNNING"
+	AdExportDone    = "DONE"
+	AdExportFailed  = "FAILED"
+)
+
+// AdExport is a background export of the ads matching a listing filter.
+// Filters holds the ListAdsInput as JSON; Quote is the quote the USD prices
+// and USD filters were computed with.
+type AdExport struct {
+	ID         string
+	Format     string
+	Status     string
+	Filters    []byte
+	FileKey    string
+	Rows       *int
+	SizeBytes  *int64
+	Quote      *Quote
+	Error      *string
+	CreatedAt  time.Time
+	ClaimedAt  *time.Time
+	FinishedAt *time.Time
+	ExpiresAt  *time.Time
+}
+
+type AdExportResponse struct {
+	ID          string     `json:"id"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"`
+	Rows        *int       `json:"rows"`
+	SizeBytes   *int64     `json:"size_bytes"`
+	QuoteUsed   *QuoteUsed `json:"quote_used"`
+	Error       *string    `json:"error,omitempty"`
+	DownloadURL *string    `json:"download_url"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// ToAdExportResponse builds the job status; downloadURL is only reported
+// once the file is ready.
+func ToAdExportResponse(e AdExport, downloadURL string) AdExportResponse {
+	resp := AdExportResponse{
+		ID:         e.ID,
+		Format:     e.Format,
+		Status:     e.Status,
+		Rows:       e.Rows,
+		SizeBytes:  e.SizeBytes,
+		QuoteUsed:  ToQuoteUsed(e.Quote),
+		Error:      e.Error,
+		CreatedAt:  e.CreatedAt,
+		FinishedAt: e.FinishedAt,
+		ExpiresAt:  e.ExpiresAt,
+	}
+	if e.Status == AdExportDone {
+		resp.DownloadURL = &downloadURL
+	}
+	return resp
+}
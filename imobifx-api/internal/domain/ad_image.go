@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+// Image processing states. Uploads start PENDING; the image worker claims
+// them (PROCESSING) and renders the size variants (READY) or gives up on
+// files it cannot decode (FAILED).
+const (
+	AdImagePending    = "PENDING"
+	AdImageProcessing = "PROCESSING"
+	AdImageReady      = "READY"
+	AdImageFailed     = "FAILED"
+)
+
+// Size variants rendered for every image, smallest first.
+const (
+	ImageSizeThumb  = "thumb"
+	ImageSizeMedium = "medium"
+	ImageSizeLarge  = "large"
+)
+
+var ImageSizes = []string{ImageSizeThumb, ImageSizeMedium, ImageSizeLarge}
+
+type AdImage struct {
+	ID        string            `json:"id"`
+	AdID      string            `json:"-"`
+	Path      string            `json:"-"`
+	Status    string            `json:"status"`
+	Variants  map[string]string `json:"-"`
+	Position  int               `json:"position"`
+	IsCover   bool              `json:"is_cover"`
+	CreatedAt time.Time         `json:"created_at"`
+	// Legacy images were stored before the image pipeline: until they are
+	// processed, their original is in the images storage, not in uploads.
+	Legacy bool `json:"-"`
+}
+
+type AdImageItem struct {
+	ID       string            `json:"id"`
+	Status   string            `json:"status"`
+	URL      *string           `json:"url"`
+	URLs     map[string]string `json:"urls"`
+	Position int               `json:"position"`
+	IsCover  bool              `json:"is_cover"`
+}
+
+// URLBuilder turns a stored image key into the URL clients download it from.
+// It is implemented by the storage backends.
+type URLBuilder interface {
+	URL(key string) string
+}
+
+// URLs maps each size variant to its public URL. It is empty until the image
+// is READY. Images stored before variants existed only have Path, which is
+// served for every size.
+func (img AdImage) URLs(urls URLBuilder) map[string]string {
+	out := make(map[string]string, len(ImageSizes))
+	if img.Status != "" && img.Status != AdImageReady {
+		return out
+	}
+	for _, size := range ImageSizes {
+		if p, ok := img.Variants[size]; ok {
+			out[size] = urls.URL(p)
+		} else if img.Path != "" {
+			out[size] = urls.URL(img.Path)
+		}
+	}
+	return out
+}
+
+// ImageRef is a stored object referenced by the database. Staged refs point
+// at the uploads storage (images not processed yet), the others at the
+// images storage.
+type ImageRef struct {
+	AdID      string    `json:"ad_id"`
+	ImageID   string    `json:"image_id,omitempty"`
+	Key       string    `json:"key"`
+	Staged    bool      `json:"staged"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+// AdImportReport is the outcome of a bulk import. Committed is false when
+// nothing was written, either because no row was valid or because an
+// all-or-nothing import had failures.
+type AdImportReport struct {
+	Mode      string           `json:"mode"`
+	Total     int              `json:"total"`
+	Imported  int              `json:"imported"`
+	Failed    int              `json:"failed"`
+	Committed bool             `json:"committed"`
+	Created   []AdImportResult `json:"created"`
+	Errors    []AdImportError  `json:"errors"`
+}
+
+type AdImportResult struct {
+	Line int    `json:"line"`
+	ID   string `json:"id"`
+}
+
+// AdImportError reports why a row was rejected, with the same code and
+// details an API call with that row would have returned.
+type AdImportError struct {
+	Line    int    `json:"line"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+const (
+	AdImportModePartial      = "partial"
+	AdImportModeAllOrNothing = "all_or_nothing"
+)
@@ -1,17 +1,23 @@
 package domain
 
 import (
+	"math"
 	"strings"
 	"time"
 )
 
 type AdItem struct {
-	ID       string   `json:"id"`
-	Type     string   `json:"type"`
-	PriceBRL float64  `json:"price_brl"`
-	PriceUSD *float64 `json:"price_usd"`
-	ImageURL *string  `json:"image_url"`
-	Address  struct {
+	ID          string            `json:"id"`
+	Type        string            `json:"type"`
+	Status      string            `json:"status"`
+	PriceBRL    float64           `json:"price_brl"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	PriceUSD    *float64          `json:"price_usd"`
+	ImageURL    *string           `json:"image_url"`
+	ImageURLs   map[string]string `json:"image_urls"`
+	Images      []AdImageItem     `json:"images"`
+	Address     struct {
 		CEP          string  `json:"cep"`
 		Street       string  `json:"street"`
 		Number       *string `json:"number,omitempty"`
@@ -20,41 +26,57 @@ type AdItem struct {
 		City         string  `json:"city"`
 		State        string  `json:"state"`
 	} `json:"address"`
-	CreatedAt time.Time `json:"created_at"`
+	Location *GeoPoint `json:"location"`
+	// DistanceKM is set when the listing is searched around a point.
+	DistanceKM  *float64 `json:"distance_km,omitempty"`
+	Bedrooms    *int     `json:"bedrooms"`
+	Bathrooms   *int     `json:"bathrooms"`
+	Parking     *int     `json:"parking_spaces"`
+	AreaM2      *float64 `json:"area_m2"`
+	CondoFeeBRL *float64 `json:"condo_fee_brl"`
+	IPTUBRL     *float64 `json:"iptu_brl"`
+	PricePerM2  *float64 `json:"price_per_m2"`
+	// PriceDrop is set when the latest price change was a reduction;
+	// PriceDropPct is that reduction in percent of the previous price.
+	PriceDrop        bool       `json:"price_drop"`
+	PriceDropPct     *float64   `json:"price_drop_pct,omitempty"`
+	PreviousPriceBRL *float64   `json:"previous_price_brl,omitempty"`
+	PriceChangedAt   *time.Time `json:"price_changed_at,omitempty"`
+	PublishAt        *time.Time `json:"publish_at,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
 }
 
-func ToAdItem(a Ad) AdItem {
-	item := AdItem{
-		ID:        a.ID,
-		Type:      a.Type,
-		PriceBRL:  a.PriceBRL,
-		CreatedAt: a.CreatedAt,
-	}
-	item.Address.CEP = a.CEP
-	item.Address.Street = a.Street
-	item.Address.Number = a.Number
-	item.Address.Complement = a.Complement
-	item.Address.Neighborhood = a.Neighborhood
-	item.Address.City = a.City
-	item.Address.State = a.State
-
-	if a.ImagePath != nil && *a.ImagePath != "" {
-		u := "/static/images/" + *a.ImagePath
-		item.ImageURL = &u
-	}
-	return item
+// ToAdItem builds the API representation of an ad; image URLs come from urls.
+func ToAdItem(a Ad, urls URLBuilder) AdItem {
+	return ToAdItemWithQuote(a, nil, urls)
 }
 
 func round2(val float64) float64 {
-	return float64(int(val*100+0.5)) / 100
+	return math.Round(val*100) / 100
 }
 
-func ToAdItemWithQuote(a Ad, quote *Quote) AdItem {
+func ToAdItemWithQuote(a Ad, quote *Quote, urls URLBuilder) AdItem {
 	item := AdItem{
-		ID:        a.ID,
-		Type:      a.Type,
-		PriceBRL:  a.PriceBRL,
-		CreatedAt: a.CreatedAt,
+		ID:          a.ID,
+		Type:        a.Type,
+		Status:      a.Status,
+		PriceBRL:    a.PriceBRL,
+		Title:       a.Title,
+		Description: a.Description,
+		Bedrooms:    a.Bedrooms,
+		Bathrooms:   a.Bathrooms,
+		Parking:     a.Parking,
+		AreaM2:      a.AreaM2,
+		CondoFeeBRL: a.CondoFeeBRL,
+		IPTUBRL:     a.IPTUBRL,
+		PublishAt:   a.PublishAt,
+		ExpiresAt:   a.ExpiresAt,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+		DeletedAt:   a.DeletedAt,
 	}
 	item.Address.CEP = a.CEP
 	item.Address.Street = a.Street
@@ -63,14 +85,48 @@ func ToAdItemWithQuote(a Ad, quote *Quote) AdItem {
 	item.Address.Neighborhood = a.Neighborhood
 	item.Address.City = a.City
 	item.Address.State = a.State
+	item.Location = a.Location
 
 	if a.ImagePath != nil && strings.TrimSpace(*a.ImagePath) != "" {
-		u := "/static/images/" + *a.ImagePath
+		u := urls.URL(*a.ImagePath)
 		item.ImageURL = &u
 	}
 
+	item.ImageURLs = map[string]string{}
+	item.Images = make([]AdImageItem, 0, len(a.Images))
+	for _, img := range a.Images {
+		sizes := img.URLs(urls)
+		it := AdImageItem{
+			ID:       img.ID,
+			Status:   img.Status,
+			URLs:     sizes,
+			Position: img.Position,
+			IsCover:  img.IsCover,
+		}
+		if u, ok := sizes[ImageSizeLarge]; ok {
+			it.URL = &u
+		}
+		if img.IsCover {
+			item.ImageURLs = sizes
+		}
+		item.Images = append(item.Images, it)
+	}
+
+	if a.AreaM2 != nil && *a.AreaM2 > 0 {
+		v := round2(a.PriceBRL / *a.AreaM2)
+		item.PricePerM2 = &v
+	}
+
+	item.PreviousPriceBRL = a.PreviousPriceBRL
+	item.PriceChangedAt = a.PriceChangedAt
+	if p := a.PreviousPriceBRL; p != nil && *p > a.PriceBRL {
+		v := round2((*p - a.PriceBRL) / *p * 100)
+		item.PriceDrop = true
+		item.PriceDropPct = &v
+	}
+
 	if quote != nil {
-		v := round2(a.PriceBRL * quote.BrlToUsd)
+		v := quote.ToUSD(a.PriceBRL)
 		item.PriceUSD = &v
 	}
 	return item
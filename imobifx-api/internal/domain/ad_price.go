@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// AdPrice is a price an ad was set to, with the quote that was effective at
+// that moment (nil when no quote existed yet).
+type AdPrice struct {
+	PriceBRL  float64
+	ChangedAt time.Time
+	Quote     *Quote
+}
+
+// AdPriceChange is one entry of an ad's price history, converted to USD at
+// the quote that was effective when the price was set.
+type AdPriceChange struct {
+	PriceBRL  float64    `json:"price_brl"`
+	PriceUSD  *float64   `json:"price_usd"`
+	QuoteUsed *QuoteUsed `json:"quote_used"`
+	ChangedAt time.Time  `json:"changed_at"`
+}
+
+type AdPriceHistoryResponse struct {
+	AdID  string          `json:"ad_id"`
+	Items []AdPriceChange `json:"items"`
+}
+
+func ToAdPriceChange(p AdPrice) AdPriceChange {
+	c := AdPriceChange{
+		PriceBRL:  p.PriceBRL,
+		QuoteUsed: ToQuoteUsed(p.Quote),
+		ChangedAt: p.ChangedAt,
+	}
+	if p.Quote != nil {
+		v := p.Quote.ToUSD(p.PriceBRL)
+		c.PriceUSD = &v
+	}
+	return c
+}
@@ -0,0 +1,27 @@
+package domain
+
+// Orderings accepted by the ads listing. Relevance only applies to text
+// searches and is their default; other listings default to newest first.
+// Distance needs a reference point and puts ads without coordinates last.
+// A single quote converts the whole listing, so the USD orderings follow the
+// BRL price.
+const (
+	AdSortNewest         = "newest"
+	AdSortOldest         = "oldest"
+	AdSortPriceAsc       = "price_asc"
+	AdSortPriceDesc      = "price_desc"
+	AdSortPriceUSDAsc    = "price_usd_asc"
+	AdSortPriceUSDDesc   = "price_usd_desc"
+	AdSortPricePerM2Asc  = "price_per_m2_asc"
+	AdSortPricePerM2Desc = "price_per_m2_desc"
+	AdSortRelevance      = "relevance"
+	AdSortDistance       = "distance"
+)
+
+var AdSorts = []string{
+	AdSortNewest, AdSortOldest,
+	AdSortPriceAsc, AdSortPriceDesc,
+	AdSortPriceUSDAsc, AdSortPriceUSDDesc,
+	AdSortPricePerM2Asc, AdSortPricePerM2Desc,
+	AdSortRelevance, AdSortDistance,
+}
@@ -0,0 +1,95 @@
+package domain
+
+import "strconv"
+
+// Levels of the market statistics. Prices of sales and rentals are never
+// mixed, so every level is also split by type.
+const (
+	AdStatsByType         = "type"
+	AdStatsByState        = "state"
+	AdStatsByCity         = "city"
+	AdStatsByNeighborhood = "neighborhood"
+)
+
+// AdStatsPercentiles are the price percentiles reported besides the median.
+var AdStatsPercentiles = []int{10, 25, 75, 90}
+
+// AdPriceStats summarizes the BRL prices of a group of ads. The location
+// fields down to the group level are set; City and Neighborhood carry the
+// most common spelling among the ads of the group.
+type AdPriceStats struct {
+	Level        string
+	Type         string
+	State        string
+	City         string
+	Neighborhood string
+
+	Count  int
+	Avg    float64
+	Median float64
+	Min    float64
+	Max    float64
+	// Percentiles follow the order of AdStatsPercentiles.
+	Percentiles []float64
+}
+
+type PriceSummary struct {
+	Avg         float64            `json:"avg"`
+	Median      float64            `json:"median"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+type AdStatsGroup struct {
+	Type         string        `json:"type"`
+	State        string        `json:"state,omitempty"`
+	City         string        `json:"city,omitempty"`
+	Neighborhood string        `json:"neighborhood,omitempty"`
+	Count        int           `json:"count"`
+	PriceBRL     PriceSummary  `json:"price_brl"`
+	PriceUSD     *PriceSummary `json:"price_usd"`
+}
+
+type AdStatsResponse struct {
+	QuoteUsed *QuoteUsed `json:"quote_used"`
+
+	ByType         []AdStatsGroup `json:"by_type"`
+	ByState        []AdStatsGroup `json:"by_state"`
+	ByCity         []AdStatsGroup `json:"by_city"`
+	ByNeighborhood []AdStatsGroup `json:"by_neighborhood"`
+}
+
+// ToAdStatsGroup rounds the summary to cents and, with a quote, converts it
+// to USD the same way price_usd is.
+func ToAdStatsGroup(s AdPriceStats, q *Quote) AdStatsGroup {
+	g := AdStatsGroup{
+		Type:         s.Type,
+		State:        s.State,
+		City:         s.City,
+		Neighborhood: s.Neighborhood,
+		Count:        s.Count,
+		PriceBRL:     priceSummary(s, round2),
+	}
+	if q != nil {
+		usd := priceSummary(s, q.ToUSD)
+		g.PriceUSD = &usd
+	}
+	return g
+}
+
+func priceSummary(s AdPriceStats, conv func(float64) float64) PriceSummary {
+	p := PriceSummary{
+		Avg:         conv(s.Avg),
+		Median:      conv(s.Median),
+		Min:         conv(s.Min),
+		Max:         conv(s.Max),
+		Percentiles: make(map[string]float64, len(s.Percentiles)),
+	}
+	for i, v := range s.Percentiles {
+		if i < len(AdStatsPercentiles) {
+			p.Percentiles["p"+strconv.Itoa(AdStatsPercentiles[i])] = conv(v)
+		}
+	}
+	return p
+}
@@ -2,15 +2,33 @@ package domain
 
 import "time"
 
+type QuoteUsed struct {
+	BrlToUsd    float64   `json:"brl_to_usd"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+func ToQuoteUsed(q *Quote) *QuoteUsed {
+	if q == nil {
+		return nil
+	}
+	return &QuoteUsed{BrlToUsd: q.BrlToUsd, EffectiveAt: q.EffectiveAt}
+}
+
+// AdsListResponse is a page of ads. Offset pages carry page and total; cursor
+// pages carry next_cursor (absent on the last page) and total only on request.
 type AdsListResponse struct {
-	Page     int `json:"page"`
-	PageSize int `json:"page_size"`
-	Total    int `json:"total"`
+	Page       int     `json:"page,omitempty"`
+	PageSize   int     `json:"page_size"`
+	Total      *int    `json:"total,omitempty"`
+	NextCursor *string `json:"next_cursor,omitempty"`
 
-	QuoteUsed *struct {
-		BrlToUsd    float64   `json:"brl_to_usd"`
-		EffectiveAt time.Time `json:"effective_at"`
-	} `json:"quote_used"`
+	QuoteUsed *QuoteUsed `json:"quote_used"`
 
 	Items []AdItem `json:"items"`
 }
+
+type AdDetailResponse struct {
+	AdItem
+
+	QuoteUsed *QuoteUsed `json:"quote_used"`
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Event types written to the outbox. Ad events carry the ad as stored
+// (without images); quote events carry the quote.
+const (
+	EventAdCreated    = "ad.created"
+	EventAdUpdated    = "ad.updated"
+	EventAdDeleted    = "ad.deleted"
+	EventQuoteCreated = "quote.created"
+)
+
+var EventTypes = []string{EventAdCreated, EventAdUpdated, EventAdDeleted, EventQuoteCreated}
+
+// Event is a change recorded in the outbox, in the same transaction as the
+// change itself. IDs grow with each event but may commit out of order; Seq,
+// assigned once the event is committed, is its position in the event stream
+// and is 0 until then.
+type Event struct {
+	ID        int64           `json:"id"`
+	Seq       int64           `json:"-"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// EventFilter narrows a stream of events to the ads of a type and location.
+// City matches ignoring case and accents, like the listing. Quote events
+// concern every ad and always match.
+type EventFilter struct {
+	Type  *string
+	City  *string
+	State *string
+}
+
+func (f EventFilter) Matches(e Event) bool {
+	if !strings.HasPrefix(e.Type, "ad.") || (f.Type == nil && f.City == nil && f.State == nil) {
+		return true
+	}
+	var a struct {
+		Type  string `json:"type"`
+		City  string `json:"city"`
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal(e.Data, &a); err != nil {
+		return false
+	}
+	if f.Type != nil && !strings.EqualFold(a.Type, *f.Type) {
+		return false
+	}
+	if f.State != nil && !strings.EqualFold(a.State, *f.State) {
+		return false
+	}
+	if f.City != nil && foldAccents(strings.ToLower(a.City)) != foldAccents(strings.ToLower(strings.TrimSpace(*f.City))) {
+		return false
+	}
+	return true
+}
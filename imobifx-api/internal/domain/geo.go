@@ -0,0 +1,44 @@
+package domain
+
+import "math"
+
+// GeoPoint is a WGS84 coordinate in decimal degrees.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// GeoBox is a latitude/longitude rectangle, as sent by map views.
+type GeoBox struct {
+	MinLat, MinLng float64
+	MaxLat, MaxLng float64
+}
+
+// earthRadiusKM is the mean Earth radius; the haversine_km SQL function uses
+// the same value.
+const earthRadiusKM = 6371.0088
+
+// DistanceKM is the great-circle (haversine) distance between two points.
+func DistanceKM(a, b GeoPoint) float64 {
+	dLat := radians(b.Lat - a.Lat)
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(radians(a.Lat))*math.Cos(radians(b.Lat))*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * earthRadiusKM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox is the smallest GeoBox containing every point within radiusKM
+// of p, used to prefilter radius searches with an index.
+func BoundingBox(p GeoPoint, radiusKM float64) GeoBox {
+	dLat := radiusKM / earthRadiusKM * 180 / math.Pi
+	dLng := 180.0
+	if c := math.Cos(radians(p.Lat)); c > 1e-9 {
+		dLng = math.Min(180, dLat/c)
+	}
+	return GeoBox{
+		MinLat: math.Max(-90, p.Lat-dLat), MinLng: math.Max(-180, p.Lng-dLng),
+		MaxLat: math.Min(90, p.Lat+dLat), MaxLng: math.Min(180, p.Lng+dLng),
+	}
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 type Quote struct {
 	ID          string    `json:"id"`
@@ -8,3 +11,41 @@ type Quote struct {
 	EffectiveAt time.Time `json:"effective_at"`
 	CreatedAt   time.Time `json:"created_at"`
 }
+
+// ToUSD converts a BRL amount at this quote, rounded to cents as shown in
+// price_usd.
+func (q Quote) ToUSD(brl float64) float64 {
+	return round2(brl * q.BrlToUsd)
+}
+
+// MinBRL returns the lowest BRL price, in cents, whose ToUSD is at least usd.
+func (q Quote) MinBRL(usd float64) float64 {
+	c := q.searchCents(usd, func(c float64) bool { return q.ToUSD(c/100) >= usd })
+	return math.Max(c, 0) / 100
+}
+
+// MaxBRL returns the highest BRL price, in cents, whose ToUSD is at most usd.
+func (q Quote) MaxBRL(usd float64) float64 {
+	c := q.searchCents(usd, func(c float64) bool { return q.ToUSD(c/100) > usd })
+	return (c - 1) / 100
+}
+
+// searchCents returns the lowest whole number of BRL cents for which ok,
+// monotonic in the price, holds. ToUSD only rounds to cents, so the answer is
+// within one USD cent of the exact conversion of usd: bisecting that window
+// keeps the bound consistent with the rounding in a bounded number of steps,
+// whatever usd is.
+func (q Quote) searchCents(usd float64, ok func(c float64) bool) float64 {
+	est := usd / q.BrlToUsd * 100
+	w := math.Ceil(1/q.BrlToUsd) + 1
+	lo, hi := math.Floor(est-w)-1, math.Ceil(est+w)+1
+	for i := 0; i < 64 && hi-lo > 1; i++ {
+		mid := math.Floor(lo + (hi-lo)/2)
+		if ok(mid) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi
+}
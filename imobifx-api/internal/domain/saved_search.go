@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// SavedSearch is a listing filter kept to be alerted of new matching ads.
+// Filters holds the ListAdsInput as JSON; Query is the same filter as the
+// GET /api/ads query string, as the client sent it.
+type SavedSearch struct {
+	ID        string
+	Name      string
+	Email     *string
+	Filters   []byte
+	Query     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SavedSearchMatch is an ad found by a saved search when it went live.
+// NotifiedAt is set once the notifier delivered it.
+type SavedSearchMatch struct {
+	SearchID   string
+	Ad         Ad
+	MatchedAt  time.Time
+	NotifiedAt *time.Time
+}
+
+type SavedSearchResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     *string   `json:"email"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SavedSearchListResponse struct {
+	Items []SavedSearchResponse `json:"items"`
+}
+
+type SavedSearchMatchItem struct {
+	MatchedAt  time.Time  `json:"matched_at"`
+	NotifiedAt *time.Time `json:"notified_at"`
+	Ad         AdItem     `json:"ad"`
+}
+
+// SavedSearchMatchesResponse is a page of matches, newest first, priced at
+// the current quote.
+type SavedSearchMatchesResponse struct {
+	Page      int                    `json:"page"`
+	PageSize  int                    `json:"page_size"`
+	Total     int                    `json:"total"`
+	QuoteUsed *QuoteUsed             `json:"quote_used"`
+	Items     []SavedSearchMatchItem `json:"items"`
+}
+
+func ToSavedSearchResponse(s SavedSearch) SavedSearchResponse {
+	return SavedSearchResponse{
+		ID:        s.ID,
+		Name:      s.Name,
+		Email:     s.Email,
+		Query:     s.Query,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import "time"
+
+// Webhook delivery states. Deliveries start PENDING and are retried with
+// backoff until the endpoint accepts them (DELIVERED) or the attempts run out
+// (DEAD).
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryDead      = "DEAD"
+)
+
+var WebhookDeliveryStatuses = []string{WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryDead}
+
+// Webhook subscribes an endpoint to some event types. Secret signs every
+// delivery.
+type Webhook struct {
+	ID        string
+	URL       string
+	Secret    string
+	Events    []string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery is one event to be posted to one webhook. URL and Secret
+// are only filled in for claimed deliveries, which also carry the event data.
+type WebhookDelivery struct {
+	ID             string
+	WebhookID      string
+	URL            string
+	Secret         string
+	Event          Event
+	Status         string
+	Attempts       int
+	NextAttemptAt  *time.Time
+	LastAttemptAt  *time.Time
+	LastStatusCode *int
+	LastError      *string
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
+
+// WebhookResponse never carries the secret, except right after creation.
+type WebhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    *string   `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookListResponse struct {
+	Items []WebhookResponse `json:"items"`
+}
+
+type WebhookDeliveryItem struct {
+	ID             string     `json:"id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	LastStatusCode *int       `json:"last_status_code"`
+	LastError      *string    `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// WebhookDeliveriesResponse is a page of the delivery log, newest first.
+type WebhookDeliveriesResponse struct {
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+	Total    int                   `json:"total"`
+	Items    []WebhookDeliveryItem `json:"items"`
+}
+
+func ToWebhookResponse(w Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    w.Events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+func ToWebhookDeliveryItem(d WebhookDelivery) WebhookDeliveryItem {
+	return WebhookDeliveryItem{
+		ID:             d.ID,
+		EventID:        d.Event.ID,
+		EventType:      d.Event.Type,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastAttemptAt:  d.LastAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
@@ -0,0 +1,146 @@
+// Package export writes tabular reports as CSV, JSON Lines or XLSX, one row
+// at a time so large reports never have to fit in memory.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Supported formats.
+const (
+	CSV   = "csv"
+	JSONL = "jsonl"
+	XLSX  = "xlsx"
+)
+
+var Formats = []string{CSV, JSONL, XLSX}
+
+// Writer appends rows to a report. Values line up with the columns given to
+// New; supported values are nil, string, bool, int, int64, float64 and
+// time.Time.
+type Writer interface {
+	Write(values []any) error
+	// Close flushes the report. It does not close the underlying writer.
+	Close() error
+}
+
+// New starts a report in format on w. CSV starts with a header row, JSONL
+// uses the columns as keys and XLSX writes them as the first sheet row.
+func New(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSV(w, columns)
+	case JSONL:
+		return newJSONL(w, columns), nil
+	case XLSX:
+		return newXLSX(w, columns)
+	}
+	return nil, fmt.Errorf("export: unknown format %q", format)
+}
+
+// ContentType is the media type files in format are served with.
+func ContentType(format string) string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case JSONL:
+		return "application/x-ndjson"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSV(w io.Writer, columns []string) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	if err := cw.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (c *csvWriter) Write(values []any) error {
+	for i, v := range values {
+		c.record[i] = text(v)
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	w       *bufio.Writer
+	buf     bytes.Buffer
+	enc     *json.Encoder
+	columns []string
+}
+
+func newJSONL(w io.Writer, columns []string) *jsonlWriter {
+	j := &jsonlWriter{w: bufio.NewWriter(w), columns: columns}
+	j.enc = json.NewEncoder(&j.buf)
+	j.enc.SetEscapeHTML(false)
+	return j
+}
+
+// Write keeps the column order, which a map would not.
+func (j *jsonlWriter) Write(values []any) error {
+	j.buf.Reset()
+	j.buf.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			j.buf.WriteByte(',')
+		}
+		if err := j.enc.Encode(j.columns[i]); err != nil {
+			return err
+		}
+		j.buf.Truncate(j.buf.Len() - 1) // Encode ends every value with a newline
+		j.buf.WriteByte(':')
+		if err := j.enc.Encode(v); err != nil {
+			return err
+		}
+		j.buf.Truncate(j.buf.Len() - 1)
+	}
+	j.buf.WriteString("}\n")
+	_, err := j.w.Write(j.buf.Bytes())
+	return err
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.w.Flush()
+}
+
+// text formats a value for CSV and the XLSX string cells.
+func text(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
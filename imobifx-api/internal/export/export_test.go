@@ -0,0 +1,103 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/export"
+)
+
+var (
+	columns = []string{"id", "title", "price_brl", "bedrooms", "created_at"}
+	created = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows    = [][]any{
+		{"a1", `Casa "térrea", varanda`, 250000.5, 3, created},
+		{"a2", "Apto <1> & cia", 1800.0, nil, created},
+	}
+)
+
+func write(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := export.New(format, &buf, columns)
+	require.NoError(t, err)
+	for _, r := range rows {
+		require.NoError(t, w.Write(r))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	require.Equal(t, "id,title,price_brl,bedrooms,created_at\n"+
+		"a1,\"Casa \"\"térrea\"\", varanda\",250000.5,3,2026-05-01T12:00:00Z\n"+
+		"a2,Apto <1> & cia,1800,,2026-05-01T12:00:00Z\n", string(write(t, export.CSV)))
+}
+
+func TestJSONL_KeepsColumnOrder(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(string(write(t, export.JSONL)), "\n"), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, `{"id":"a1","title":"Casa \"térrea\", varanda","price_brl":250000.5,"bedrooms":3,"created_at":"2026-05-01T12:00:00Z"}`, lines[0])
+	require.Equal(t, `{"id":"a2","title":"Apto <1> & cia","price_brl":1800,"bedrooms":null,"created_at":"2026-05-01T12:00:00Z"}`, lines[1])
+}
+
+func TestXLSX_IsAReadableWorkbook(t *testing.T) {
+	out := write(t, export.XLSX)
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	require.NoError(t, err)
+
+	var names []string
+	var sheet []byte
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		rc, err := f.Open()
+		require.NoError(t, err)
+		body, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		require.NoError(t, xml.Unmarshal(body, new(struct{})), f.Name)
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			sheet = body
+		}
+	}
+	require.ElementsMatch(t, []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"}, names)
+
+	var ws struct {
+		Rows []struct {
+			R     string `xml:"r,attr"`
+			Cells []struct {
+				R      string `xml:"r,attr"`
+				T      string `xml:"t,attr"`
+				V      string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.Unmarshal(sheet, &ws))
+	require.Len(t, ws.Rows, 3)
+	require.Equal(t, "created_at", ws.Rows[0].Cells[4].Inline)
+	require.Equal(t, "E1", ws.Rows[0].Cells[4].R)
+
+	first := ws.Rows[1].Cells
+	require.Equal(t, `Casa "térrea", varanda`, first[1].Inline)
+	require.Equal(t, "", first[2].T)
+	require.Equal(t, "250000.5", first[2].V)
+	require.Equal(t, "3", first[3].V)
+
+	second := ws.Rows[2].Cells
+	require.Len(t, second, 4) // the nil bedrooms cell is left out
+	require.Equal(t, "Apto <1> & cia", second[1].Inline)
+	require.Equal(t, "E3", second[3].R)
+}
+
+func TestNew_UnknownFormat(t *testing.T) {
+	_, err := export.New("pdf", io.Discard, columns)
+	require.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// xlsxMaxRows and xlsxMaxCellChars are the limits of a sheet in Excel.
+	xlsxMaxRows      = 1048576
+	xlsxMaxCellChars = 32767
+
+	xlsxSheetName = "ads"
+)
+
+// ErrTooManyRows is returned when a report does not fit in one XLSX sheet.
+var ErrTooManyRows = errors.New("export: too many rows for an xlsx sheet")
+
+// The static parts of a single-sheet workbook. Cells use inline strings, so
+// no shared strings table is needed and rows can be streamed.
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + xlsxSheetName + `" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	cols  []string // column letters
+	row   int
+}
+
+func newXLSX(w io.Writer, columns []string) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, p := range xlsxParts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+
+	// The sheet must be the last entry: a zip entry stays open until the
+	// next one is created.
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxWriter{zw: zw, sheet: bufio.NewWriter(f), cols: make([]string, len(columns))}
+	for i := range columns {
+		x.cols[i] = columnName(i)
+	}
+	x.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]any, len(columns))
+	for i, c := range columns {
+		header[i] = c
+	}
+	if err := x.Write(header); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) Write(values []any) error {
+	if x.row == xlsxMaxRows {
+		return ErrTooManyRows
+	}
+	x.row++
+	n := strconv.Itoa(x.row)
+
+	x.sheet.WriteString(`<row r="` + n + `">`)
+	for i, v := range values {
+		ref := x.cols[i] + n
+		switch v := v.(type) {
+		case nil:
+		case int, int64, float64:
+			x.sheet.WriteString(`<c r="` + ref + `"><v>` + text(v) + `</v></c>`)
+		case bool:
+			b := "0"
+			if v {
+				b = "1"
+			}
+			x.sheet.WriteString(`<c r="` + ref + `" t="b"><v>` + b + `</v></c>`)
+		default:
+			s := text(v)
+			if _, ok := v.(time.Time); !ok && utf8.RuneCountInString(s) > xlsxMaxCellChars {
+				s = string([]rune(s)[:xlsxMaxCellChars])
+			}
+			x.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(x.sheet, []byte(s))
+			x.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	x.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// columnName is the spreadsheet name of the zero-based column i: A..Z, AA...
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
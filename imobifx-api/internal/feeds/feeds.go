@@ -0,0 +1,52 @@
+// Package feeds defines the syndication feeds of active ads crawled by the
+// real estate portals. Each portal format is a Generator; see the vrsync
+// package for the ZAP/VivaReal one.
+package feeds
+
+import (
+	"io"
+	"time"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// Header identifies the publisher of a feed.
+type Header struct {
+	Provider    string
+	Email       string
+	ContactName string
+	Telephone   string
+	PublishedAt time.Time
+}
+
+// Listing is one ad of a feed. ImageURLs are absolute, cover first; URL is
+// the public page of the ad and may be empty.
+type Listing struct {
+	Ad        domain.Ad
+	ImageURLs []string
+	URL       string
+}
+
+// Listings walks the listings of a feed, calling fn for each in turn and
+// stopping at the first error.
+type Listings func(fn func(Listing) error) error
+
+// Result summarizes a rendered feed. Skipped maps the ID of each ad the
+// format cannot represent to the reason; those ads are left out rather than
+// failing the whole feed.
+type Result struct {
+	Listings int
+	Skipped  map[string]string
+}
+
+// Generator renders feeds in one portal format.
+type Generator interface {
+	// Name identifies the feed; it is served at /feeds/<name>.xml.
+	Name() string
+	ContentType() string
+	// Write renders the feed to w, a listing at a time.
+	Write(w io.Writer, h Header, listings Listings) (Result, error)
+	// Validate checks a rendered feed against the schema the generator is
+	// written to, before it is published.
+	Validate(r io.Reader) error
+}
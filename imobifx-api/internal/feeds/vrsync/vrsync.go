@@ -0,0 +1,316 @@
+// Package vrsync renders the VRSync XML listing feed crawled by the ZAP and
+// VivaReal portals.
+package vrsync
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josinaldojr/imobifx-api/internal/feeds"
+	"github.com/josinaldojr/imobifx-api/internal/feeds/xsd"
+)
+
+const Namespace = "http://www.vivareal.com/schemas/1.0/VRSync"
+
+//go:embed vrsync.xsd
+var schemaXSD []byte
+
+var schema = sync.OnceValues(func() (*xsd.Schema, error) {
+	return xsd.Parse(bytes.NewReader(schemaXSD))
+})
+
+// UsageTypes and PropertyTypes are the values of the schema the ads can be
+// published as. Ads carry no property type, so the whole feed uses the one
+// given in Options.
+var (
+	UsageTypes    = []string{"Residential", "Commercial"}
+	PropertyTypes = []string{
+		"Residential / Apartment", "Residential / Home", "Residential / Condo", "Residential / Flat",
+		"Residential / Kitnet", "Residential / Penthouse", "Residential / Sobrado", "Residential / Village House",
+		"Residential / Land Lot", "Residential / Farm Ranch",
+		"Commercial / Building", "Commercial / Business", "Commercial / Office", "Commercial / Land Lot",
+		"Commercial / Industrial",
+	}
+)
+
+var states = map[string]string{
+	"AC": "Acre", "AL": "Alagoas", "AP": "Amapá", "AM": "Amazonas", "BA": "Bahia", "CE": "Ceará",
+	"DF": "Distrito Federal", "ES": "Espírito Santo", "GO": "Goiás", "MA": "Maranhão", "MT": "Mato Grosso",
+	"MS": "Mato Grosso do Sul", "MG": "Minas Gerais", "PA": "Pará", "PB": "Paraíba", "PR": "Paraná",
+	"PE": "Pernambuco", "PI": "Piauí", "RJ": "Rio de Janeiro", "RN": "Rio Grande do Norte",
+	"RS": "Rio Grande do Sul", "RO": "Rondônia", "RR": "Roraima", "SC": "Santa Catarina", "SP": "São Paulo",
+	"SE": "Sergipe", "TO": "Tocantins",
+}
+
+type Options struct {
+	UsageType    string
+	PropertyType string
+}
+
+type Generator struct {
+	opts Options
+}
+
+func New(opts Options) (*Generator, error) {
+	if !slices.Contains(UsageTypes, opts.UsageType) {
+		return nil, fmt.Errorf("vrsync: invalid usage type %q (use %s)", opts.UsageType, strings.Join(UsageTypes, ", "))
+	}
+	if !slices.Contains(PropertyTypes, opts.PropertyType) {
+		return nil, fmt.Errorf("vrsync: invalid property type %q", opts.PropertyType)
+	}
+	return &Generator{opts: opts}, nil
+}
+
+func (g *Generator) Name() string { return "vrsync" }
+
+func (g *Generator) ContentType() string { return "application/xml; charset=utf-8" }
+
+// Validate checks the feed against vrsync.xsd, our own subset of the format:
+// it catches generator regressions and bad data, not portal-side rules.
+func (g *Generator) Validate(r io.Reader) error {
+	s, err := schema()
+	if err != nil {
+		return err
+	}
+	return s.Validate(r)
+}
+
+func (g *Generator) Write(w io.Writer, h feeds.Header, listings feeds.Listings) (feeds.Result, error) {
+	res := feeds.Result{Skipped: map[string]string{}}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return res, err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	root := xml.StartElement{Name: xml.Name{Space: Namespace, Local: "ListingDataFeed"}}
+	list := xml.StartElement{Name: xml.Name{Local: "Listings"}}
+	if err := enc.EncodeToken(root); err != nil {
+		return res, err
+	}
+	hdr := header{Provider: h.Provider, Email: h.Email, ContactName: h.ContactName, Telephone: h.Telephone}
+	if !h.PublishedAt.IsZero() {
+		hdr.PublishDate = h.PublishedAt.UTC().Format(time.RFC3339)
+	}
+	if err := enc.Encode(hdr); err != nil {
+		return res, err
+	}
+	if err := enc.EncodeToken(list); err != nil {
+		return res, err
+	}
+
+	err := listings(func(l feeds.Listing) error {
+		item, reason := g.listing(l, h)
+		if reason != "" {
+			res.Skipped[l.Ad.ID] = reason
+			return nil
+		}
+		res.Listings++
+		return enc.Encode(item)
+	})
+	if err != nil {
+		return res, err
+	}
+
+	if err := enc.EncodeToken(list.End()); err != nil {
+		return res, err
+	}
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return res, err
+	}
+	return res, enc.Close()
+}
+
+type header struct {
+	XMLName     xml.Name `xml:"Header"`
+	Provider    string   `xml:"Provider"`
+	Email       string   `xml:"Email"`
+	ContactName string   `xml:"ContactName,omitempty"`
+	PublishDate string   `xml:"PublishDate,omitempty"`
+	Telephone   string   `xml:"Telephone,omitempty"`
+}
+
+type listing struct {
+	XMLName         xml.Name `xml:"Listing"`
+	ListingID       string   `xml:"ListingID"`
+	Title           string   `xml:"Title"`
+	TransactionType string   `xml:"TransactionType"`
+	PublicationType string   `xml:"PublicationType"`
+	DetailViewURL   string   `xml:"DetailViewUrl,omitempty"`
+	Media           *media   `xml:"Media"`
+	Details         details  `xml:"Details"`
+	Location        location `xml:"Location"`
+	ContactInfo     *contact `xml:"ContactInfo"`
+}
+
+type media struct {
+	Items []mediaItem `xml:"Item"`
+}
+
+type mediaItem struct {
+	Medium  string `xml:"medium,attr"`
+	Primary bool   `xml:"primary,attr,omitempty"`
+	URL     string `xml:",chardata"`
+}
+
+type details struct {
+	UsageType                 string  `xml:"UsageType"`
+	PropertyType              string  `xml:"PropertyType"`
+	Description               string  `xml:"Description"`
+	ListPrice                 *amount `xml:"ListPrice"`
+	RentalPrice               *amount `xml:"RentalPrice"`
+	PropertyAdministrationFee *amount `xml:"PropertyAdministrationFee"`
+	YearlyTax                 *amount `xml:"YearlyTax"`
+	LivingArea                *area   `xml:"LivingArea"`
+	Bedrooms                  *int    `xml:"Bedrooms"`
+	Bathrooms                 *int    `xml:"Bathrooms"`
+	Garage                    *garage `xml:"Garage"`
+}
+
+type amount struct {
+	Currency string `xml:"currency,attr"`
+	Period   string `xml:"period,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+type area struct {
+	Unit  string `xml:"unit,attr"`
+	Value string `xml:",chardata"`
+}
+
+type garage struct {
+	Type  string `xml:"type,attr"`
+	Value int    `xml:",chardata"`
+}
+
+type location struct {
+	DisplayAddress string `xml:"displayAddress,attr"`
+	Country        coded  `xml:"Country"`
+	State          coded  `xml:"State"`
+	City           string `xml:"City"`
+	Neighborhood   string `xml:"Neighborhood,omitempty"`
+	Address        string `xml:"Address,omitempty"`
+	StreetNumber   string `xml:"StreetNumber,omitempty"`
+	Complement     string `xml:"Complement,omitempty"`
+	PostalCode     string `xml:"PostalCode,omitempty"`
+	Latitude       string `xml:"Latitude,omitempty"`
+	Longitude      string `xml:"Longitude,omitempty"`
+}
+
+type coded struct {
+	Abbreviation string `xml:"abbreviation,attr"`
+	Name         string `xml:",chardata"`
+}
+
+type contact struct {
+	Name      string `xml:"Name,omitempty"`
+	Email     string `xml:"Email"`
+	Telephone string `xml:"Telephone,omitempty"`
+}
+
+// listing maps an ad to its feed entry, or returns why it cannot be
+// published.
+func (g *Generator) listing(l feeds.Listing, h feeds.Header) (listing, string) {
+	a := l.Ad
+	uf := strings.ToUpper(strings.TrimSpace(a.State))
+	state, ok := states[uf]
+	if !ok {
+		return listing{}, fmt.Sprintf("unknown state %q", a.State)
+	}
+	city := strings.TrimSpace(a.City)
+	if city == "" {
+		return listing{}, "missing city"
+	}
+
+	out := listing{
+		ListingID:       a.ID,
+		Title:           strings.TrimSpace(a.Title),
+		PublicationType: "STANDARD",
+		DetailViewURL:   l.URL,
+		Details: details{
+			UsageType:    g.opts.UsageType,
+			PropertyType: g.opts.PropertyType,
+			Description:  strings.TrimSpace(a.Description),
+			Bedrooms:     a.Bedrooms,
+			Bathrooms:    a.Bathrooms,
+		},
+		Location: location{
+			DisplayAddress: "All",
+			Country:        coded{Abbreviation: "BR", Name: "Brasil"},
+			State:          coded{Abbreviation: uf, Name: state},
+			City:           city,
+			Neighborhood:   strings.TrimSpace(a.Neighborhood),
+			Address:        strings.TrimSpace(a.Street),
+			StreetNumber:   trimmed(a.Number),
+			Complement:     trimmed(a.Complement),
+			PostalCode:     a.CEP,
+		},
+		ContactInfo: &contact{Name: h.ContactName, Email: h.Email, Telephone: h.Telephone},
+	}
+
+	price := brl(&a.PriceBRL)
+	if a.Type == "RENT" {
+		out.TransactionType = "For Rent"
+		price.Period = "Monthly"
+		out.Details.RentalPrice = price
+	} else {
+		out.TransactionType = "For Sale"
+		out.Details.ListPrice = price
+	}
+	if out.Title == "" {
+		if a.Type == "RENT" {
+			out.Title = "Imóvel para alugar em " + city
+		} else {
+			out.Title = "Imóvel à venda em " + city
+		}
+	}
+	if out.Details.Description == "" {
+		out.Details.Description = out.Title
+	}
+	out.Details.PropertyAdministrationFee = brl(a.CondoFeeBRL)
+	out.Details.YearlyTax = brl(a.IPTUBRL)
+	if a.AreaM2 != nil {
+		out.Details.LivingArea = &area{Unit: "square metres", Value: decimal(*a.AreaM2)}
+	}
+	if a.Parking != nil {
+		out.Details.Garage = &garage{Type: "Parking Space", Value: *a.Parking}
+	}
+	if a.Location != nil {
+		out.Location.Latitude = decimal(a.Location.Lat)
+		out.Location.Longitude = decimal(a.Location.Lng)
+	}
+
+	if len(l.ImageURLs) > 0 {
+		out.Media = &media{}
+		for i, u := range l.ImageURLs {
+			out.Media.Items = append(out.Media.Items, mediaItem{Medium: "image", Primary: i == 0, URL: u})
+		}
+	}
+	return out, ""
+}
+
+func brl(v *float64) *amount {
+	if v == nil {
+		return nil
+	}
+	return &amount{Currency: "BRL", Value: decimal(*v)}
+}
+
+func decimal(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func trimmed(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimSpace(*s)
+}
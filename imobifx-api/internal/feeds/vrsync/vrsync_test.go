@@ -0,0 +1,242 @@
+package vrsync_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/feeds"
+	"github.com/josinaldojr/imobifx-api/internal/feeds/vrsync"
+	"github.com/josinaldojr/imobifx-api/internal/feeds/xsd"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+var header = feeds.Header{
+	Provider:    "ImobiFX",
+	Email:       "anuncios@example.com",
+	ContactName: "Equipe ImobiFX",
+	PublishedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func generator(t *testing.T) *vrsync.Generator {
+	t.Helper()
+	g, err := vrsync.New(vrsync.Options{UsageType: "Residential", PropertyType: "Residential / Apartment"})
+	require.NoError(t, err)
+	return g
+}
+
+func render(t *testing.T, g *vrsync.Generator, listings ...feeds.Listing) ([]byte, feeds.Result) {
+	t.Helper()
+	var buf bytes.Buffer
+	res, err := g.Write(&buf, header, func(fn func(feeds.Listing) error) error {
+		for _, l := range listings {
+			if err := fn(l); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return buf.Bytes(), res
+}
+
+// parsed is the part of a listing the tests look at.
+type parsed struct {
+	Listings []struct {
+		ID          string `xml:"ListingID"`
+		Title       string `xml:"Title"`
+		Transaction string `xml:"TransactionType"`
+		URL         string `xml:"DetailViewUrl"`
+		Media       []struct {
+			Primary bool   `xml:"primary,attr"`
+			URL     string `xml:",chardata"`
+		} `xml:"Media>Item"`
+		Description string `xml:"Details>Description"`
+		ListPrice   string `xml:"Details>ListPrice"`
+		RentalPrice struct {
+			Period string `xml:"period,attr"`
+			Value  string `xml:",chardata"`
+		} `xml:"Details>RentalPrice"`
+		Condo  string `xml:"Details>PropertyAdministrationFee"`
+		IPTU   string `xml:"Details>YearlyTax"`
+		Area   string `xml:"Details>LivingArea"`
+		Garage string `xml:"Details>Garage"`
+		State  struct {
+			UF   string `xml:"abbreviation,attr"`
+			Name string `xml:",chardata"`
+		} `xml:"Location>State"`
+		City       string `xml:"Location>City"`
+		Number     string `xml:"Location>StreetNumber"`
+		PostalCode string `xml:"Location>PostalCode"`
+		Latitude   string `xml:"Location>Latitude"`
+	} `xml:"Listings>Listing"`
+}
+
+func TestWrite_MapsAdsAndValidates(t *testing.T) {
+	g := generator(t)
+	sale := feeds.Listing{
+		Ad: domain.Ad{
+			ID: "5b0c4c1e-4d7a-4f55-9d1f-8f0a2b6f9e01", Type: "SALE", PriceBRL: 450000,
+			Title: "Apartamento <3 quartos> & varanda", Description: "Vista para o mar",
+			CEP: "58038-000", Street: "Av. Cabo Branco", Number: ptr("1200"), Neighborhood: "Cabo Branco",
+			City: "João Pessoa", State: "pb", Location: &domain.GeoPoint{Lat: -7.1366, Lng: -34.8228},
+			Bedrooms: ptr(3), Bathrooms: ptr(2), Parking: ptr(2), AreaM2: ptr(98.5),
+			CondoFeeBRL: ptr(650.0), IPTUBRL: ptr(1200.0),
+		},
+		ImageURLs: []string{"https://cdn.example.com/cover.jpg", "https://cdn.example.com/2.jpg"},
+		URL:       "https://imobifx.example.com/anuncios/5b0c4c1e-4d7a-4f55-9d1f-8f0a2b6f9e01",
+	}
+	rent := feeds.Listing{Ad: domain.Ad{ID: "r1", Type: "RENT", PriceBRL: 2500.5, City: "Recife", State: "PE"}}
+
+	out, res := render(t, g, sale, rent)
+	require.Equal(t, 2, res.Listings)
+	require.Empty(t, res.Skipped)
+	require.NoError(t, g.Validate(bytes.NewReader(out)))
+
+	var doc parsed
+	require.NoError(t, xml.Unmarshal(out, &doc))
+	require.Len(t, doc.Listings, 2)
+
+	s := doc.Listings[0]
+	require.Equal(t, sale.Ad.ID, s.ID)
+	require.Equal(t, "Apartamento <3 quartos> & varanda", s.Title)
+	require.Equal(t, "For Sale", s.Transaction)
+	require.Equal(t, sale.URL, s.URL)
+	require.Len(t, s.Media, 2)
+	require.True(t, s.Media[0].Primary)
+	require.Equal(t, "https://cdn.example.com/cover.jpg", s.Media[0].URL)
+	require.False(t, s.Media[1].Primary)
+	require.Equal(t, "450000", s.ListPrice)
+	require.Equal(t, "650", s.Condo)
+	require.Equal(t, "1200", s.IPTU)
+	require.Equal(t, "98.5", s.Area)
+	require.Equal(t, "2", s.Garage)
+	require.Equal(t, "PB", s.State.UF)
+	require.Equal(t, "Paraíba", s.State.Name)
+	require.Equal(t, "1200", s.Number)
+	require.Equal(t, "58038-000", s.PostalCode)
+	require.Equal(t, "-7.1366", s.Latitude)
+
+	r := doc.Listings[1]
+	require.Equal(t, "For Rent", r.Transaction)
+	require.Equal(t, "Monthly", r.RentalPrice.Period)
+	require.Equal(t, "2500.5", r.RentalPrice.Value)
+	require.Empty(t, r.ListPrice)
+	require.Equal(t, "Imóvel para alugar em Recife", r.Title)
+	require.Equal(t, r.Title, r.Description)
+	require.Empty(t, r.Media)
+}
+
+func TestWrite_SkipsAdsWithoutAValidState(t *testing.T) {
+	g := generator(t)
+	out, res := render(t, g,
+		feeds.Listing{Ad: domain.Ad{ID: "a1", Type: "SALE", City: "Recife", State: "XX"}},
+		feeds.Listing{Ad: domain.Ad{ID: "a2", Type: "SALE", City: "Natal", State: "RN"}},
+	)
+	require.Equal(t, 1, res.Listings)
+	require.Equal(t, map[string]string{"a1": `unknown state "XX"`}, res.Skipped)
+	require.NoError(t, g.Validate(bytes.NewReader(out)))
+}
+
+func TestWrite_EmptyFeedIsValid(t *testing.T) {
+	g := generator(t)
+	out, res := render(t, g)
+	require.Zero(t, res.Listings)
+	require.NoError(t, g.Validate(bytes.NewReader(out)))
+}
+
+func TestValidate_RejectsNonConformingFeed(t *testing.T) {
+	g := generator(t)
+	out, _ := render(t, g, feeds.Listing{
+		Ad:        domain.Ad{ID: "a1", Type: "SALE", City: "Natal", State: "RN"},
+		ImageURLs: []string{"/static/images/a1.jpg"},
+	})
+
+	err := g.Validate(bytes.NewReader(out))
+	var verr *xsd.Error
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "/ListingDataFeed/Listings/Listing[1]/Media/Item[1]", verr.Path)
+}
+
+func TestNew_AcceptsOnlySchemaValues(t *testing.T) {
+	_, err := vrsync.New(vrsync.Options{UsageType: "Residential", PropertyType: "Apartment"})
+	require.Error(t, err)
+	_, err = vrsync.New(vrsync.Options{UsageType: "Rural", PropertyType: "Residential / Home"})
+	require.Error(t, err)
+
+	// Every property type New accepts must also pass the schema.
+	for _, pt := range vrsync.PropertyTypes {
+		g, err := vrsync.New(vrsync.Options{UsageType: strings.SplitN(pt, " / ", 2)[0], PropertyType: pt})
+		require.NoError(t, err)
+		out, _ := render(t, g, feeds.Listing{Ad: domain.Ad{ID: "a1", Type: "SALE", City: "Natal", State: "RN"}})
+		require.NoError(t, g.Validate(bytes.NewReader(out)), pt)
+	}
+}
+
+// TestValidate_AgreesWithLibxml2 checks the schema and the validator against
+// an independent XSD implementation: xmllint must compile vrsync.xsd and
+// reach the same verdict on valid and broken feeds. It is skipped where
+// xmllint is not installed.
+func TestValidate_AgreesWithLibxml2(t *testing.T) {
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint not installed")
+	}
+
+	g := generator(t)
+	out, _ := render(t, g,
+		feeds.Listing{
+			Ad: domain.Ad{
+				ID: "a1", Type: "SALE", PriceBRL: 450000, Title: "Apartamento", Description: "Vista para o mar",
+				CEP: "58038-000", Street: "Av. Cabo Branco", Number: ptr("1200"), Neighborhood: "Cabo Branco",
+				City: "João Pessoa", State: "PB", Location: &domain.GeoPoint{Lat: -7.1366, Lng: -34.8228},
+				Bedrooms: ptr(3), Bathrooms: ptr(2), Parking: ptr(2), AreaM2: ptr(98.5),
+				CondoFeeBRL: ptr(650.0), IPTUBRL: ptr(1200.0),
+			},
+			ImageURLs: []string{"https://cdn.example.com/cover.jpg"},
+			URL:       "https://imobifx.example.com/anuncios/a1",
+		},
+		feeds.Listing{Ad: domain.Ad{ID: "r1", Type: "RENT", PriceBRL: 2500.5, City: "Recife", State: "PE"}},
+	)
+	feed := string(out)
+
+	for name, doc := range map[string]string{
+		"valid":              feed,
+		"relative media url": strings.Replace(feed, "https://cdn.example.com/cover.jpg", "/static/cover.jpg", 1),
+		"missing element":    strings.Replace(feed, "<TransactionType>For Sale</TransactionType>", "", 1),
+		"unknown value":      strings.Replace(feed, "For Sale", "For Lease", 1),
+		"bad postal code":    strings.Replace(feed, "58038-000", "58038", 1),
+		"text in listing":    strings.Replace(feed, "<Listing>", "<Listing>oops", 1),
+		"unexpected element": strings.Replace(feed, "</Header>", "<Extra>x</Extra></Header>", 1),
+		"elements reordered": strings.Replace(strings.Replace(feed, "<Email>anuncios@example.com</Email>", "", 1), "</Header>", "<Email>anuncios@example.com</Email></Header>", 1),
+	} {
+		if name != "valid" {
+			require.NotEqual(t, feed, doc, name)
+		}
+		path := filepath.Join(t.TempDir(), "feed.xml")
+		require.NoError(t, os.WriteFile(path, []byte(doc), 0o600))
+
+		cmd := exec.Command(xmllint, "--noout", "--schema", "vrsync.xsd", path)
+		lintOut, err := cmd.CombinedOutput()
+		var exit *exec.ExitError
+		// 3 is a document that does not validate; anything else means the
+		// schema itself was rejected.
+		if errors.As(err, &exit) && exit.ExitCode() != 3 {
+			t.Fatalf("%s: xmllint: %s", name, lintOut)
+		}
+		libxml2Valid := err == nil
+
+		require.Equal(t, libxml2Valid, g.Validate(strings.NewReader(doc)) == nil, "%s: %s", name, lintOut)
+		require.Equal(t, name == "valid", libxml2Valid, name)
+	}
+}
@@ -0,0 +1,723 @@
+// Package xsd validates XML documents against an XML Schema. It supports the
+// subset the portal feed schemas use: global and local elements with
+// minOccurs/maxOccurs, complex types with sequence or all content, simple
+// content extensions with attributes, and simple types restricted by
+// enumeration, length, range and pattern facets over the common built-in
+// types. Schemas using anything else are rejected by Parse.
+package xsd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	schemaNS = "http://www.w3.org/2001/XMLSchema"
+	xsiNS    = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// Error is a validation failure at Path, an XPath-like location such as
+// /Feed/Listings/Listing[3]/Title.
+type Error struct {
+	Path string
+	Msg  string
+}
+
+func (e *Error) Error() string { return e.Path + ": " + e.Msg }
+
+type Schema struct {
+	ns       string
+	elements map[string]*element
+	complex  map[string]*complexType
+	simple   map[string]*simpleType
+}
+
+type element struct {
+	name     string
+	min, max int // max < 0 is unbounded
+	complex  *complexType
+	simple   *simpleType
+	typeRef  string
+}
+
+type complexType struct {
+	all      bool
+	children []*element
+	attrs    []*attribute
+	// content types elements with simple content (text plus attributes).
+	content *simpleType
+}
+
+type attribute struct {
+	name     string
+	required bool
+	typ      *simpleType
+}
+
+type simpleType struct {
+	builtin string
+	base    *simpleType
+	baseRef string
+
+	enum           []string
+	minLen, maxLen int // -1 when unset
+	minIncl        *float64
+	maxIncl        *float64
+	pattern        *regexp.Regexp
+}
+
+// Parse reads a schema document.
+func Parse(r io.Reader) (*Schema, error) {
+	root, err := parseTree(r)
+	if err != nil {
+		return nil, err
+	}
+	if root.name.Space != schemaNS || root.name.Local != "schema" {
+		return nil, fmt.Errorf("xsd: root is not xs:schema")
+	}
+
+	p := &parser{
+		prefixes: map[string]string{},
+		s: &Schema{
+			ns:       root.attr("targetNamespace"),
+			elements: map[string]*element{},
+			complex:  map[string]*complexType{},
+			simple:   map[string]*simpleType{},
+		},
+	}
+	for _, a := range root.attrs {
+		if a.Name.Space == "xmlns" {
+			p.prefixes[a.Name.Local] = a.Value
+		} else if a.Name.Space == "" && a.Name.Local == "xmlns" {
+			p.prefixes[""] = a.Value
+		}
+	}
+
+	for _, n := range root.children {
+		switch n.name.Local {
+		case "element":
+			e, err := p.element(n, true)
+			if err != nil {
+				return nil, err
+			}
+			p.s.elements[e.name] = e
+		case "complexType":
+			ct, err := p.complexType(n)
+			if err != nil {
+				return nil, err
+			}
+			p.s.complex[n.attr("name")] = ct
+		case "simpleType":
+			st, err := p.simpleType(n)
+			if err != nil {
+				return nil, err
+			}
+			p.s.simple[n.attr("name")] = st
+		case "annotation":
+		default:
+			return nil, fmt.Errorf("xsd: unsupported top-level <%s>", n.name.Local)
+		}
+	}
+
+	if err := p.resolve(); err != nil {
+		return nil, err
+	}
+	return p.s, nil
+}
+
+type parser struct {
+	s        *Schema
+	prefixes map[string]string
+	elems    []*element
+	attrs    []*attribute
+	simples  []*simpleType
+}
+
+func (p *parser) element(n *node, global bool) (*element, error) {
+	e := &element{name: n.attr("name"), min: 1, max: 1}
+	if e.name == "" {
+		return nil, fmt.Errorf("xsd: element without name (ref is not supported)")
+	}
+	if !global {
+		var err error
+		if e.min, err = occurs(n.attr("minOccurs"), 1); err != nil {
+			return nil, err
+		}
+		if e.max, err = occurs(n.attr("maxOccurs"), 1); err != nil {
+			return nil, err
+		}
+	}
+	e.typeRef = n.attr("type")
+
+	for _, c := range n.children {
+		var err error
+		switch c.name.Local {
+		case "complexType":
+			e.complex, err = p.complexType(c)
+		case "simpleType":
+			e.simple, err = p.simpleType(c)
+		case "annotation":
+		default:
+			err = fmt.Errorf("xsd: unsupported <%s> in element %q", c.name.Local, e.name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if e.typeRef == "" && e.complex == nil && e.simple == nil {
+		e.simple = &simpleType{builtin: "string", minLen: -1, maxLen: -1}
+	}
+	p.elems = append(p.elems, e)
+	return e, nil
+}
+
+func occurs(v string, def int) (int, error) {
+	switch v {
+	case "":
+		return def, nil
+	case "unbounded":
+		return -1, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("xsd: invalid occurrence %q", v)
+	}
+	return n, nil
+}
+
+func (p *parser) complexType(n *node) (*complexType, error) {
+	ct := &complexType{}
+	for _, c := range n.children {
+		switch c.name.Local {
+		case "sequence", "all":
+			ct.all = c.name.Local == "all"
+			for _, ec := range c.children {
+				if ec.name.Local == "annotation" {
+					continue
+				}
+				if ec.name.Local != "element" {
+					return nil, fmt.Errorf("xsd: unsupported <%s> in <%s>", ec.name.Local, c.name.Local)
+				}
+				e, err := p.element(ec, false)
+				if err != nil {
+					return nil, err
+				}
+				ct.children = append(ct.children, e)
+			}
+		case "attribute":
+			a, err := p.attribute(c)
+			if err != nil {
+				return nil, err
+			}
+			ct.attrs = append(ct.attrs, a)
+		case "simpleContent":
+			ext := c.child("extension")
+			if ext == nil {
+				return nil, fmt.Errorf("xsd: simpleContent without extension")
+			}
+			ct.content = &simpleType{baseRef: ext.attr("base"), minLen: -1, maxLen: -1}
+			p.simples = append(p.simples, ct.content)
+			for _, ac := range ext.children {
+				if ac.name.Local != "attribute" {
+					return nil, fmt.Errorf("xsd: unsupported <%s> in extension", ac.name.Local)
+				}
+				a, err := p.attribute(ac)
+				if err != nil {
+					return nil, err
+				}
+				ct.attrs = append(ct.attrs, a)
+			}
+		case "annotation":
+		default:
+			return nil, fmt.Errorf("xsd: unsupported <%s> in complexType", c.name.Local)
+		}
+	}
+	return ct, nil
+}
+
+func (p *parser) attribute(n *node) (*attribute, error) {
+	a := &attribute{name: n.attr("name"), required: n.attr("use") == "required"}
+	if st := n.child("simpleType"); st != nil {
+		var err error
+		if a.typ, err = p.simpleType(st); err != nil {
+			return nil, err
+		}
+	} else {
+		ref := n.attr("type")
+		if ref == "" {
+			ref = "xs:string"
+		}
+		a.typ = &simpleType{baseRef: ref, minLen: -1, maxLen: -1}
+		p.simples = append(p.simples, a.typ)
+	}
+	p.attrs = append(p.attrs, a)
+	return a, nil
+}
+
+func (p *parser) simpleType(n *node) (*simpleType, error) {
+	r := n.child("restriction")
+	if r == nil {
+		return nil, fmt.Errorf("xsd: simpleType %q is not a restriction", n.attr("name"))
+	}
+	st := &simpleType{baseRef: r.attr("base"), minLen: -1, maxLen: -1}
+	for _, f := range r.children {
+		v := f.attr("value")
+		var err error
+		switch f.name.Local {
+		case "enumeration":
+			st.enum = append(st.enum, v)
+		case "minLength":
+			st.minLen, err = strconv.Atoi(v)
+		case "maxLength":
+			st.maxLen, err = strconv.Atoi(v)
+		case "minInclusive":
+			var x float64
+			x, err = strconv.ParseFloat(v, 64)
+			st.minIncl = &x
+		case "maxInclusive":
+			var x float64
+			x, err = strconv.ParseFloat(v, 64)
+			st.maxIncl = &x
+		case "pattern":
+			st.pattern, err = regexp.Compile(`^(?:` + v + `)$`)
+		case "annotation":
+		default:
+			err = fmt.Errorf("xsd: unsupported facet <%s>", f.name.Local)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.simples = append(p.simples, st)
+	return st, nil
+}
+
+var builtins = map[string]bool{
+	"string": true, "token": true, "normalizedString": true, "decimal": true, "double": true, "float": true,
+	"integer": true, "int": true, "long": true, "nonNegativeInteger": true, "positiveInteger": true,
+	"boolean": true, "dateTime": true, "date": true, "anyURI": true,
+}
+
+// resolve links the type references once every named type is known.
+func (p *parser) resolve() error {
+	for _, st := range p.simples {
+		if st.baseRef == "" {
+			continue
+		}
+		ns, local := p.qname(st.baseRef)
+		if ns == schemaNS {
+			if !builtins[local] {
+				return fmt.Errorf("xsd: unsupported built-in type %q", st.baseRef)
+			}
+			st.builtin = local
+			continue
+		}
+		base, ok := p.s.simple[local]
+		if !ok {
+			return fmt.Errorf("xsd: unknown simple type %q", st.baseRef)
+		}
+		st.base = base
+	}
+	for _, e := range p.elems {
+		if e.typeRef == "" {
+			continue
+		}
+		ns, local := p.qname(e.typeRef)
+		switch {
+		case ns == schemaNS:
+			if !builtins[local] {
+				return fmt.Errorf("xsd: unsupported built-in type %q", e.typeRef)
+			}
+			e.simple = &simpleType{builtin: local, minLen: -1, maxLen: -1}
+		case p.s.complex[local] != nil:
+			e.complex = p.s.complex[local]
+		case p.s.simple[local] != nil:
+			e.simple = p.s.simple[local]
+		default:
+			return fmt.Errorf("xsd: unknown type %q of element %q", e.typeRef, e.name)
+		}
+	}
+	return nil
+}
+
+func (p *parser) qname(ref string) (ns, local string) {
+	prefix, local, ok := strings.Cut(ref, ":")
+	if !ok {
+		return p.prefixes[""], ref
+	}
+	return p.prefixes[prefix], local
+}
+
+// Validate checks the document in r. It returns an *Error for documents that
+// do not conform and other errors for malformed XML. The document is read as
+// a stream: only the open elements are kept, so feeds of any size validate in
+// constant memory.
+func (s *Schema) Validate(r io.Reader) error {
+	dec := xml.NewDecoder(r)
+	var stack []*frame
+	seenRoot := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var f *frame
+			if len(stack) == 0 {
+				f, err = s.root(t, seenRoot)
+				seenRoot = true
+			} else {
+				f, err = s.child(stack[len(stack)-1], t)
+			}
+			if err != nil {
+				return err
+			}
+			if err := checkAttrs(t.Attr, f.attrs(), f.path); err != nil {
+				return err
+			}
+			stack = append(stack, f)
+		case xml.EndElement:
+			if err := s.end(stack[len(stack)-1]); err != nil {
+				return err
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) == 0 {
+				continue
+			}
+			f := stack[len(stack)-1]
+			if f.content() != nil {
+				f.text.Write(t)
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return &Error{Path: f.path, Msg: "text is not allowed here"}
+			}
+		}
+	}
+	if !seenRoot {
+		return fmt.Errorf("xsd: empty document")
+	}
+	return nil
+}
+
+// frame is an open element being validated: its declaration, the text read
+// so far for simple content, and where its children are in the content model.
+type frame struct {
+	decl *element
+	path string
+	text bytes.Buffer
+
+	// pos is the particle of a sequence the last child matched, count how
+	// many times in a row; an all group counts each particle instead.
+	pos    int
+	count  int
+	counts map[string]int
+}
+
+func (f *frame) attrs() []*attribute {
+	if f.decl.complex == nil {
+		return nil
+	}
+	return f.decl.complex.attrs
+}
+
+// content is the type of the text of the element, nil when it holds
+// elements.
+func (f *frame) content() *simpleType {
+	if f.decl.simple != nil {
+		return f.decl.simple
+	}
+	return f.decl.complex.content
+}
+
+func (s *Schema) root(t xml.StartElement, seen bool) (*frame, error) {
+	path := "/" + t.Name.Local
+	e, ok := s.elements[t.Name.Local]
+	if seen || !ok || t.Name.Space != s.ns {
+		return nil, &Error{Path: path, Msg: "unexpected root element"}
+	}
+	return &frame{decl: e, path: path}, nil
+}
+
+// child matches t against the content model of its parent p.
+func (s *Schema) child(p *frame, t xml.StartElement) (*frame, error) {
+	if p.content() != nil {
+		return nil, &Error{Path: p.path, Msg: "element must not have children"}
+	}
+	ct := p.decl.complex
+	unexpected := &Error{Path: p.path, Msg: fmt.Sprintf("unexpected element <%s>", t.Name.Local)}
+
+	if ct.all {
+		var decl *element
+		for _, e := range ct.children {
+			if e.name == t.Name.Local && t.Name.Space == s.ns {
+				decl = e
+			}
+		}
+		if decl == nil {
+			return nil, unexpected
+		}
+		if p.counts == nil {
+			p.counts = map[string]int{}
+		}
+		p.counts[decl.name]++
+		return &frame{decl: decl, path: childPath(p.path, decl.name, p.counts[decl.name], decl.max)}, nil
+	}
+
+	for p.pos < len(ct.children) && (ct.children[p.pos].name != t.Name.Local || t.Name.Space != s.ns) {
+		if err := checkCount(ct.children[p.pos], p.count, p.path); err != nil {
+			return nil, err
+		}
+		p.pos++
+		p.count = 0
+	}
+	if p.pos == len(ct.children) {
+		return nil, unexpected
+	}
+	e := ct.children[p.pos]
+	p.count++
+	if e.max >= 0 && p.count > e.max {
+		return nil, checkCount(e, p.count, p.path)
+	}
+	return &frame{decl: e, path: childPath(p.path, e.name, p.count, e.max)}, nil
+}
+
+// end checks what can only be checked once the element is closed: its text
+// and the particles it is missing.
+func (s *Schema) end(f *frame) error {
+	if st := f.content(); st != nil {
+		return checkValue(st, f.text.String(), f.path)
+	}
+	ct := f.decl.complex
+	if ct.all {
+		for _, e := range ct.children {
+			if err := checkCount(e, f.counts[e.name], f.path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i := f.pos; i < len(ct.children); i++ {
+		count := 0
+		if i == f.pos {
+			count = f.count
+		}
+		if err := checkCount(ct.children[i], count, f.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func childPath(path, name string, n, max int) string {
+	if max == 1 {
+		return path + "/" + name
+	}
+	return fmt.Sprintf("%s/%s[%d]", path, name, n)
+}
+
+func checkCount(e *element, count int, path string) error {
+	if count < e.min {
+		return &Error{Path: path, Msg: fmt.Sprintf("missing element <%s>", e.name)}
+	}
+	if e.max >= 0 && count > e.max {
+		return &Error{Path: path, Msg: fmt.Sprintf("too many <%s> elements (max %d)", e.name, e.max)}
+	}
+	return nil
+}
+
+func checkAttrs(attrs []xml.Attr, decls []*attribute, path string) error {
+	seen := map[string]bool{}
+	for _, a := range attrs {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") || a.Name.Space == xsiNS {
+			continue
+		}
+		var decl *attribute
+		for _, d := range decls {
+			if d.name == a.Name.Local && a.Name.Space == "" {
+				decl = d
+			}
+		}
+		if decl == nil {
+			return &Error{Path: path, Msg: fmt.Sprintf("unexpected attribute %q", a.Name.Local)}
+		}
+		seen[decl.name] = true
+		if err := checkValue(decl.typ, a.Value, path+"/@"+decl.name); err != nil {
+			return err
+		}
+	}
+	for _, d := range decls {
+		if d.required && !seen[d.name] {
+			return &Error{Path: path, Msg: fmt.Sprintf("missing attribute %q", d.name)}
+		}
+	}
+	return nil
+}
+
+func checkValue(st *simpleType, v string, path string) error {
+	if st.builtin != "string" && st.builtin != "normalizedString" {
+		v = strings.TrimSpace(v)
+	}
+	if st.base != nil {
+		if err := checkValue(st.base, v, path); err != nil {
+			return err
+		}
+	}
+	if st.builtin != "" {
+		if err := checkBuiltin(st.builtin, v); err != nil {
+			return &Error{Path: path, Msg: err.Error()}
+		}
+	}
+
+	if len(st.enum) > 0 {
+		found := false
+		for _, e := range st.enum {
+			found = found || e == v
+		}
+		if !found {
+			return &Error{Path: path, Msg: fmt.Sprintf("%q is not one of %s", v, strings.Join(st.enum, ", "))}
+		}
+	}
+	n := len([]rune(v))
+	if st.minLen >= 0 && n < st.minLen {
+		return &Error{Path: path, Msg: fmt.Sprintf("shorter than %d characters", st.minLen)}
+	}
+	if st.maxLen >= 0 && n > st.maxLen {
+		return &Error{Path: path, Msg: fmt.Sprintf("longer than %d characters", st.maxLen)}
+	}
+	if st.minIncl != nil || st.maxIncl != nil {
+		x, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return &Error{Path: path, Msg: fmt.Sprintf("%q is not a number", v)}
+		}
+		if st.minIncl != nil && x < *st.minIncl {
+			return &Error{Path: path, Msg: fmt.Sprintf("%s is below %v", v, *st.minIncl)}
+		}
+		if st.maxIncl != nil && x > *st.maxIncl {
+			return &Error{Path: path, Msg: fmt.Sprintf("%s is above %v", v, *st.maxIncl)}
+		}
+	}
+	if st.pattern != nil && !st.pattern.MatchString(v) {
+		return &Error{Path: path, Msg: fmt.Sprintf("%q does not match %s", v, st.pattern)}
+	}
+	return nil
+}
+
+var (
+	decimalRe = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	integerRe = regexp.MustCompile(`^[+-]?\d+$`)
+)
+
+func checkBuiltin(typ, v string) error {
+	switch typ {
+	case "decimal":
+		if !decimalRe.MatchString(v) {
+			return fmt.Errorf("%q is not a decimal", v)
+		}
+	case "double", "float":
+		if x, err := strconv.ParseFloat(v, 64); err != nil || math.IsNaN(x) {
+			return fmt.Errorf("%q is not a number", v)
+		}
+	case "integer", "int", "long", "nonNegativeInteger", "positiveInteger":
+		if !integerRe.MatchString(v) {
+			return fmt.Errorf("%q is not an integer", v)
+		}
+		x, _ := strconv.ParseInt(v, 10, 64)
+		if typ == "nonNegativeInteger" && x < 0 || typ == "positiveInteger" && x < 1 {
+			return fmt.Errorf("%q is out of range for %s", v, typ)
+		}
+	case "boolean":
+		if v != "true" && v != "false" && v != "1" && v != "0" {
+			return fmt.Errorf("%q is not a boolean", v)
+		}
+	case "dateTime":
+		if _, err := time.Parse("2006-01-02T15:04:05Z07:00", v); err != nil {
+			if _, err := time.Parse("2006-01-02T15:04:05", v); err != nil {
+				return fmt.Errorf("%q is not a dateTime", v)
+			}
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return fmt.Errorf("%q is not a date", v)
+		}
+	case "anyURI":
+		if _, err := url.Parse(v); err != nil {
+			return fmt.Errorf("%q is not a URI", v)
+		}
+	}
+	return nil
+}
+
+// node is a parsed schema element. Text is the concatenated character data
+// directly inside it.
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	text     string
+}
+
+func (n *node) attr(name string) string {
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *node) child(local string) *node {
+	for _, c := range n.children {
+		if c.name.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+func parseTree(r io.Reader) (*node, error) {
+	dec := xml.NewDecoder(r)
+	var stack []*node
+	var root *node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name, attrs: t.Copy().Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("xsd: empty document")
+	}
+	return root, nil
+}
@@ -0,0 +1,126 @@
+package xsd_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/feeds/xsd"
+)
+
+const testSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns="urn:test" targetNamespace="urn:test" elementFormDefault="qualified">
+  <xs:element name="Feed">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Name" type="Code"/>
+        <xs:element name="Item" type="Item" minOccurs="0" maxOccurs="2"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+  <xs:complexType name="Item">
+    <xs:all>
+      <xs:element name="Price" type="Price"/>
+      <xs:element name="Rooms" type="xs:nonNegativeInteger" minOccurs="0"/>
+    </xs:all>
+    <xs:attribute name="kind" use="required">
+      <xs:simpleType>
+        <xs:restriction base="xs:string">
+          <xs:enumeration value="sale"/>
+          <xs:enumeration value="rent"/>
+        </xs:restriction>
+      </xs:simpleType>
+    </xs:attribute>
+  </xs:complexType>
+  <xs:complexType name="Price">
+    <xs:simpleContent>
+      <xs:extension base="Amount">
+        <xs:attribute name="currency" type="xs:string" use="required"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal">
+      <xs:minInclusive value="0"/>
+      <xs:maxInclusive value="1000"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]+"/>
+      <xs:maxLength value="4"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+
+func TestValidate(t *testing.T) {
+	s, err := xsd.Parse(strings.NewReader(testSchema))
+	require.NoError(t, err)
+
+	item := func(attrs, body string) string {
+		return `<Item ` + attrs + `>` + body + `</Item>`
+	}
+	doc := func(body string) string {
+		return `<Feed xmlns="urn:test"><Name>ABC</Name>` + body + `</Feed>`
+	}
+
+	cases := []struct {
+		name, doc, path, msg string
+	}{
+		{"valid", doc(item(`kind="sale"`, `<Rooms>2</Rooms><Price currency="BRL">10.5</Price>`)), "", ""},
+		{"empty optional list", doc(""), "", ""},
+		{"wrong namespace", `<Feed><Name>ABC</Name></Feed>`, "/Feed", "unexpected root element"},
+		{"pattern", `<Feed xmlns="urn:test"><Name>abc</Name></Feed>`, "/Feed/Name", "does not match"},
+		{"max length", `<Feed xmlns="urn:test"><Name>ABCDE</Name></Feed>`, "/Feed/Name", "longer than 4"},
+		{"missing element", `<Feed xmlns="urn:test"></Feed>`, "/Feed", "missing element <Name>"},
+		{"out of order", `<Feed xmlns="urn:test">` + item(`kind="sale"`, `<Price currency="BRL">1</Price>`) + `<Name>ABC</Name></Feed>`, "/Feed", "missing element <Name>"},
+		{"too many", doc(strings.Repeat(item(`kind="sale"`, `<Price currency="BRL">1</Price>`), 3)), "/Feed", "too many <Item> elements (max 2)"},
+		{"missing in all", doc(item(`kind="rent"`, `<Rooms>1</Rooms>`)), "/Feed/Item[1]", "missing element <Price>"},
+		{"repeated in all", doc(item(`kind="rent"`, `<Price currency="BRL">1</Price><Price currency="BRL">2</Price>`)), "/Feed/Item[1]", "too many <Price>"},
+		{"enumeration", doc(item(`kind="swap"`, `<Price currency="BRL">1</Price>`)), "/Feed/Item[1]/@kind", `"swap" is not one of sale, rent`},
+		{"required attribute", doc(item(`kind="sale"`, `<Price>1</Price>`)), "/Feed/Item[1]/Price", `missing attribute "currency"`},
+		{"unknown attribute", doc(item(`kind="sale" extra="1"`, `<Price currency="BRL">1</Price>`)), "/Feed/Item[1]", `unexpected attribute "extra"`},
+		{"decimal", doc(item(`kind="sale"`, `<Price currency="BRL">1,5</Price>`)), "/Feed/Item[1]/Price", "not a decimal"},
+		{"range", doc(item(`kind="sale"`, `<Price currency="BRL">1000.01</Price>`)), "/Feed/Item[1]/Price", "above 1000"},
+		{"non-negative", doc(item(`kind="sale"`, `<Price currency="BRL">1</Price><Rooms>-1</Rooms>`)), "/Feed/Item[1]/Rooms", "out of range"},
+		{"second item", doc(item(`kind="sale"`, `<Price currency="BRL">1</Price>`) + item(`kind="sale"`, `<Price currency="BRL">-1</Price>`)), "/Feed/Item[2]/Price", "below 0"},
+		{"text in element content", doc(item(`kind="sale"`, `oops<Price currency="BRL">1</Price>`)), "/Feed/Item[1]", "text is not allowed"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := s.Validate(strings.NewReader(tc.doc))
+			if tc.path == "" {
+				require.NoError(t, err)
+				return
+			}
+			var verr *xsd.Error
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, tc.path, verr.Path)
+			require.Contains(t, verr.Msg, tc.msg)
+		})
+	}
+}
+
+func TestValidate_MalformedXML(t *testing.T) {
+	s, err := xsd.Parse(strings.NewReader(testSchema))
+	require.NoError(t, err)
+
+	err = s.Validate(strings.NewReader(`<Feed xmlns="urn:test"><Name>ABC</Feed>`))
+	require.Error(t, err)
+	var verr *xsd.Error
+	require.False(t, errors.As(err, &verr))
+}
+
+func TestParse_RejectsUnsupportedConstructs(t *testing.T) {
+	_, err := xsd.Parse(strings.NewReader(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="T"><xs:choice><xs:element name="A"/></xs:choice></xs:complexType>
+</xs:schema>`))
+	require.ErrorContains(t, err, "unsupported <choice>")
+
+	_, err = xsd.Parse(strings.NewReader(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="A" type="Missing"/>
+</xs:schema>`))
+	require.ErrorContains(t, err, `unknown type "Missing"`)
+}
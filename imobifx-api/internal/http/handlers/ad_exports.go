@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/josinaldojr/imobifx-api/internal/export"
+	"github.com/josinaldojr/imobifx-api/internal/http/requests"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+func CreateAdExport(exports *service.ExportService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		format, in, err := requests.BindCreateAdExport(c)
+		if err != nil {
+			return err
+		}
+
+		job, err := exports.Create(c.UserContext(), format, in)
+		if err != nil {
+			return err
+		}
+
+		c.Location("/api/ads/exports/" + job.ID)
+		return c.Status(http.StatusAccepted).JSON(job)
+	}
+}
+
+func GetAdExport(exports *service.ExportService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		job, err := exports.Get(c.UserContext(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(job)
+	}
+}
+
+func DownloadAdExport(exports *service.ExportService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		f, job, err := exports.Open(c.UserContext(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+
+		c.Attachment("ads-" + job.ID + "." + job.Format)
+		c.Set(fiber.HeaderContentType, export.ContentType(job.Format))
+		size := -1
+		if job.SizeBytes != nil {
+			size = int(*job.SizeBytes)
+		}
+		// fasthttp closes f once the body has been sent.
+		return c.SendStream(f, size)
+	}
+}
@@ -22,11 +22,34 @@ func CreateAd(cfg config.Config, ads *service.AdsService) fiber.Handler {
 			return err
 		}
 
-		item := domain.ToAdItem(created)
+		item := ads.Item(created)
+		c.Set(fiber.HeaderETag, domain.AdETag(created.UpdatedAt))
 		return c.Status(http.StatusCreated).JSON(item)
 	}
 }
 
+// ImportAds answers 200 when the import was committed and 422 when nothing
+// was stored; the body is the per-row report either way.
+func ImportAds(imports *service.ImportService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		rows, opts, err := requests.BindImportAds(c)
+		if err != nil {
+			return err
+		}
+
+		report, err := imports.Import(c.UserContext(), rows, opts)
+		if err != nil {
+			return err
+		}
+
+		status := http.StatusOK
+		if !report.Committed {
+			status = http.StatusUnprocessableEntity
+		}
+		return c.Status(status).JSON(report)
+	}
+}
+
 func ListAds(ads *service.AdsService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		in, err := requests.BindListAds(c)
@@ -40,3 +63,196 @@ func ListAds(ads *service.AdsService) fiber.Handler {
 		return c.JSON(resp)
 	}
 }
+
+// AdStats summarizes the prices of the ads matching the listing filters.
+func AdStats(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindListAds(c)
+		if err != nil {
+			return err
+		}
+		resp, err := ads.Stats(c.UserContext(), in)
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+func GetAd(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		resp, err := ads.Get(c.UserContext(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderETag, domain.AdETag(resp.UpdatedAt))
+		return c.JSON(resp)
+	}
+}
+
+func GetAdPriceHistory(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		resp, err := ads.PriceHistory(c.UserContext(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+func ReplaceAd(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindReplaceAd(c)
+		if err != nil {
+			return err
+		}
+
+		updated, err := ads.Replace(c.UserContext(), c.Params("id"), in, c.Get(fiber.HeaderIfMatch))
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderETag, domain.AdETag(updated.UpdatedAt))
+		return c.JSON(ads.Item(updated))
+	}
+}
+
+func PatchAd(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindPatchAd(c)
+		if err != nil {
+			return err
+		}
+
+		updated, err := ads.Patch(c.UserContext(), c.Params("id"), in, c.Get(fiber.HeaderIfMatch))
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderETag, domain.AdETag(updated.UpdatedAt))
+		return c.JSON(ads.Item(updated))
+	}
+}
+
+func DeleteAd(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := ads.Delete(c.UserContext(), c.Params("id")); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusNoContent)
+	}
+}
+
+func RestoreAd(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		restored, err := ads.Restore(c.UserContext(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderETag, domain.AdETag(restored.UpdatedAt))
+		return c.JSON(ads.Item(restored))
+	}
+}
+
+func TransitionAd(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindTransitionAd(c)
+		if err != nil {
+			return err
+		}
+
+		updated, err := ads.Transition(c.UserContext(), c.Params("id"), in.Status)
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderETag, domain.AdETag(updated.UpdatedAt))
+		return c.JSON(ads.Item(updated))
+	}
+}
+
+func RenewAd(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		expiresAt, err := requests.BindRenewAd(c)
+		if err != nil {
+			return err
+		}
+
+		renewed, err := ads.Renew(c.UserContext(), c.Params("id"), expiresAt)
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderETag, domain.AdETag(renewed.UpdatedAt))
+		return c.JSON(ads.Item(renewed))
+	}
+}
+
+func AddAdImages(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		updated, err := ads.AddImages(c.UserContext(), c.Params("id"), requests.BindAddAdImages(c))
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderETag, domain.AdETag(updated.UpdatedAt))
+		return c.Status(http.StatusCreated).JSON(ads.Item(updated))
+	}
+}
+
+func DeleteAdImage(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		updated, err := ads.RemoveImage(c.UserContext(), c.Params("id"), c.Params("imageId"))
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderETag, domain.AdETag(updated.UpdatedAt))
+		return c.JSON(ads.Item(updated))
+	}
+}
+
+func ReorderAdImages(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindReorderAdImages(c)
+		if err != nil {
+			return err
+		}
+
+		updated, err := ads.ReorderImages(c.UserContext(), c.Params("id"), in.ImageIDs)
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderETag, domain.AdETag(updated.UpdatedAt))
+		return c.JSON(ads.Item(updated))
+	}
+}
+
+func SetAdCover(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		updated, err := ads.SetCover(c.UserContext(), c.Params("id"), c.Params("imageId"))
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderETag, domain.AdETag(updated.UpdatedAt))
+		return c.JSON(ads.Item(updated))
+	}
+}
+
+// ListDuplicateAds reports the clusters of probable duplicate ads.
+func ListDuplicateAds(ads *service.AdsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tolerance, err := requests.BindDuplicateClusters(c)
+		if err != nil {
+			return err
+		}
+		resp, err := ads.DuplicateClusters(c.UserContext(), tolerance)
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
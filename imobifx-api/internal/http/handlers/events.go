@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/http/requests"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+const (
+	// eventsHeartbeat keeps idle streams open through proxies and notices
+	// clients that went away.
+	eventsHeartbeat = 15 * time.Second
+
+	// eventsRetry is how long browsers wait before reconnecting, in ms.
+	eventsRetry = 3000
+)
+
+// StreamEvents serves the changes to ads and quotes as Server-Sent Events,
+// resuming after Last-Event-ID when the client reconnects.
+func StreamEvents(hub *service.EventHub) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindEvents(c)
+		if err != nil {
+			return err
+		}
+		sub, err := hub.Subscribe(in)
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Set("X-Accel-Buffering", "no")
+
+		// The writer runs once the handler has returned, so it cannot use the
+		// request context; a gone client shows up as a write error.
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer sub.Close()
+			if err := streamEvents(w, sub); err != nil {
+				slog.Debug("events_stream_closed", slog.String("error", err.Error()))
+			}
+		})
+		return nil
+	}
+}
+
+func streamEvents(w *bufio.Writer, sub *service.EventSubscription) error {
+	fmt.Fprintf(w, "retry: %d\n\n", eventsRetry)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	err := sub.Replay(context.Background(), func(e domain.Event) error {
+		return writeEvent(w, e)
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if sub.Replayed(e) {
+				continue
+			}
+			if err := writeEvent(w, e); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if _, err := w.WriteString(": ping\n\n"); err != nil {
+				return err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func writeEvent(w *bufio.Writer, e domain.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data)
+	return err
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+// GetFeed serves a portal feed, e.g. /api/feeds/vrsync.xml.
+func GetFeed(feeds *service.FeedService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		name, ok := strings.CutSuffix(c.Params("feed"), ".xml")
+		if !ok {
+			return errors.New(http.StatusNotFound, "FEED_NOT_FOUND", "Feed não encontrado.", fiber.Map{"feed": c.Params("feed")})
+		}
+
+		f, contentType, err := feeds.Open(c.UserContext(), name)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, contentType)
+		// fasthttp closes f once the body has been sent.
+		return c.SendStream(f)
+	}
+}
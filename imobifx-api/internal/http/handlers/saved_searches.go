@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/josinaldojr/imobifx-api/internal/http/requests"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+func CreateSavedSearch(searches *service.SavedSearchService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindSavedSearch(c)
+		if err != nil {
+			return err
+		}
+		created, err := searches.Create(c.UserContext(), in)
+		if err != nil {
+			return err
+		}
+		c.Location("/api/saved-searches/" + created.ID)
+		return c.Status(http.StatusCreated).JSON(created)
+	}
+}
+
+func ListSavedSearches(searches *service.SavedSearchService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		resp, err := searches.List(c.UserContext())
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+func GetSavedSearch(searches *service.SavedSearchService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		resp, err := searches.Get(c.UserContext(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+func ReplaceSavedSearch(searches *service.SavedSearchService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindSavedSearch(c)
+		if err != nil {
+			return err
+		}
+		updated, err := searches.Replace(c.UserContext(), c.Params("id"), in)
+		if err != nil {
+			return err
+		}
+		return c.JSON(updated)
+	}
+}
+
+func DeleteSavedSearch(searches *service.SavedSearchService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := searches.Delete(c.UserContext(), c.Params("id")); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusNoContent)
+	}
+}
+
+func ListSavedSearchMatches(searches *service.SavedSearchService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page, pageSize := requests.BindSavedSearchMatches(c)
+		resp, err := searches.Matches(c.UserContext(), c.Params("id"), page, pageSize)
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/josinaldojr/imobifx-api/internal/http/requests"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+func CreateWebhook(hooks *service.WebhookService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindWebhook(c)
+		if err != nil {
+			return err
+		}
+		created, err := hooks.Create(c.UserContext(), in)
+		if err != nil {
+			return err
+		}
+		c.Location("/api/webhooks/" + created.ID)
+		return c.Status(http.StatusCreated).JSON(created)
+	}
+}
+
+func ListWebhooks(hooks *service.WebhookService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		resp, err := hooks.List(c.UserContext())
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+func GetWebhook(hooks *service.WebhookService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		resp, err := hooks.Get(c.UserContext(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+func ReplaceWebhook(hooks *service.WebhookService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		in, err := requests.BindWebhook(c)
+		if err != nil {
+			return err
+		}
+		updated, err := hooks.Replace(c.UserContext(), c.Params("id"), in)
+		if err != nil {
+			return err
+		}
+		return c.JSON(updated)
+	}
+}
+
+func DeleteWebhook(hooks *service.WebhookService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := hooks.Delete(c.UserContext(), c.Params("id")); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusNoContent)
+	}
+}
+
+func ListWebhookDeliveries(hooks *service.WebhookService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		status, page, pageSize := requests.BindWebhookDeliveries(c)
+		resp, err := hooks.Deliveries(c.UserContext(), c.Params("id"), status, page, pageSize)
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+func RedeliverWebhookDelivery(hooks *service.WebhookService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		resp, err := hooks.Redeliver(c.UserContext(), c.Params("id"), c.Params("deliveryId"))
+		if err != nil {
+			return err
+		}
+		return c.Status(http.StatusAccepted).JSON(resp)
+	}
+}
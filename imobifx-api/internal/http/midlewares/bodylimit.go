@@ -0,0 +1,17 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit answers 413 to requests with a body over limit bytes. The server
+// accepts the largest body any route does; each route is held to its own
+// limit with this.
+func BodyLimit(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Request().Header.ContentLength() > limit || len(c.Body()) > limit {
+			return fiber.ErrRequestEntityTooLarge
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package requests
+
+import (
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+func BindAddAdImages(c *fiber.Ctx) []*multipart.FileHeader {
+	return formFiles(c, "images", "image")
+}
+
+func BindReorderAdImages(c *fiber.Ctx) (usecase.ReorderAdImagesInput, error) {
+	var in usecase.ReorderAdImagesInput
+	if err := c.BodyParser(&in); err != nil {
+		return usecase.ReorderAdImagesInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", nil)
+	}
+	return in, nil
+}
@@ -5,41 +5,111 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/josinaldojr/imobifx-api/internal/errors"
 	"github.com/josinaldojr/imobifx-api/internal/usecase"
 )
 
-func BindCreateAd(c *fiber.Ctx) (usecase.CreateAdInput, *multipart.FileHeader, error) {
-	typ := strings.ToUpper(strings.TrimSpace(c.FormValue("type")))
-	priceStr := strings.TrimSpace(c.FormValue("price_brl"))
+// BindCreateAd reads the ad fields and the uploaded images. Files may be sent
+// as repeated "images" parts; the legacy single "image" part still works and
+// goes first.
+func BindCreateAd(c *fiber.Ctx) (usecase.CreateAdInput, []*multipart.FileHeader, error) {
+	in, err := bindAdForm(c)
+	if err != nil {
+		return usecase.CreateAdInput{}, nil, err
+	}
+
+	if in.PublishAt, err = optTime(c.FormValue("publish_at"), "publish_at"); err != nil {
+		return usecase.CreateAdInput{}, nil, err
+	}
+	if in.ExpiresAt, err = optTime(c.FormValue("expires_at"), "expires_at"); err != nil {
+		return usecase.CreateAdInput{}, nil, err
+	}
+	// force may come as a form field or in the query string.
+	force := strings.TrimSpace(c.FormValue("force"))
+	if force == "" {
+		force = strings.TrimSpace(c.Query("force"))
+	}
+	if force != "" {
+		if in.Force, err = strconv.ParseBool(force); err != nil {
+			return usecase.CreateAdInput{}, nil, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"force": "must be a boolean"})
+		}
+	}
+
+	return in, formFiles(c, "image", "images"), nil
+}
+
+func formFiles(c *fiber.Ctx, fields ...string) []*multipart.FileHeader {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil
+	}
+	var files []*multipart.FileHeader
+	for _, f := range fields {
+		files = append(files, form.File[f]...)
+	}
+	return files
+}
+
+func bindAdForm(c *fiber.Ctx) (usecase.CreateAdInput, error) {
+	return adInput(func(key string) string { return c.FormValue(key) })
+}
+
+// adInput reads the ad fields through get, which returns "" for absent
+// fields. Forms and import rows share it, so both accept the same names.
+func adInput(get func(string) string) (usecase.CreateAdInput, error) {
+	typ := strings.ToUpper(strings.TrimSpace(get("type")))
+	priceStr := strings.TrimSpace(get("price_brl"))
 
 	price, err := strconv.ParseFloat(priceStr, 64)
 	if err != nil {
-		return usecase.CreateAdInput{}, nil, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"price_brl": "must be a number"})
+		return usecase.CreateAdInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"price_brl": "must be a number"})
 	}
 
-	cepRaw := strings.TrimSpace(c.FormValue("cep"))
+	cepRaw := strings.TrimSpace(get("cep"))
 
 	in := usecase.CreateAdInput{
 		Type:         typ,
+		Status:       strings.ToUpper(strings.TrimSpace(get("status"))),
 		PriceBRL:     price,
+		Title:        strings.TrimSpace(get("title")),
+		Description:  strings.TrimSpace(get("description")),
 		CEP:          cepRaw,
-		Street:       strings.TrimSpace(c.FormValue("street")),
-		Neighborhood: strings.TrimSpace(c.FormValue("neighborhood")),
-		City:         strings.TrimSpace(c.FormValue("city")),
-		State:        strings.ToUpper(strings.TrimSpace(c.FormValue("state"))),
-		Number:       optStr(c.FormValue("number")),
-		Complement:   optStr(c.FormValue("complement")),
+		Street:       strings.TrimSpace(get("street")),
+		Neighborhood: strings.TrimSpace(get("neighborhood")),
+		City:         strings.TrimSpace(get("city")),
+		State:        strings.ToUpper(strings.TrimSpace(get("state"))),
+		Number:       optStr(get("number")),
+		Complement:   optStr(get("complement")),
 	}
 
-	file, ferr := c.FormFile("image")
-	if ferr != nil {
-		file = nil
+	if in.Bedrooms, err = optInt(get("bedrooms"), "bedrooms"); err != nil {
+		return usecase.CreateAdInput{}, err
 	}
-
-	return in, file, nil
+	if in.Bathrooms, err = optInt(get("bathrooms"), "bathrooms"); err != nil {
+		return usecase.CreateAdInput{}, err
+	}
+	if in.Parking, err = optInt(get("parking_spaces"), "parking_spaces"); err != nil {
+		return usecase.CreateAdInput{}, err
+	}
+	if in.AreaM2, err = optFloat(get("area_m2"), "area_m2"); err != nil {
+		return usecase.CreateAdInput{}, err
+	}
+	if in.CondoFeeBRL, err = optFloat(get("condo_fee_brl"), "condo_fee_brl"); err != nil {
+		return usecase.CreateAdInput{}, err
+	}
+	if in.IPTUBRL, err = optFloat(get("iptu_brl"), "iptu_brl"); err != nil {
+		return usecase.CreateAdInput{}, err
+	}
+	if in.Latitude, err = optFloat(get("latitude"), "latitude"); err != nil {
+		return usecase.CreateAdInput{}, err
+	}
+	if in.Longitude, err = optFloat(get("longitude"), "longitude"); err != nil {
+		return usecase.CreateAdInput{}, err
+	}
+	return in, nil
 }
 
 func optStr(v string) *string {
@@ -49,3 +119,40 @@ func optStr(v string) *string {
 	}
 	return &v
 }
+
+func optInt(v, field string) (*int, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{field: "must be an integer"})
+	}
+	return &n, nil
+}
+
+func optFloat(v, field string) (*float64, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{field: "must be a number"})
+	}
+	return &f, nil
+}
+
+func optTime(v, field string) (*time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{field: "must be RFC3339 (e.g. 2026-02-16T10:00:00Z)"})
+	}
+	t = t.UTC()
+	return &t, nil
+}
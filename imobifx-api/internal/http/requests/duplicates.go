@@ -0,0 +1,25 @@
+package requests
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+)
+
+// BindDuplicateClusters reads the price tolerance of the duplicate report, a
+// fraction in [0, 1) that defaults to the one Create uses.
+func BindDuplicateClusters(c *fiber.Ctx) (float64, error) {
+	v := strings.TrimSpace(c.Query("price_tolerance"))
+	if v == "" {
+		return domain.DuplicatePriceTolerance, nil
+	}
+	tolerance, err := strconv.ParseFloat(v, 64)
+	if err != nil || tolerance < 0 || tolerance >= 1 {
+		return 0, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"price_tolerance": "must be a number >= 0 and < 1"})
+	}
+	return tolerance, nil
+}
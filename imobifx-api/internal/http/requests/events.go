@@ -0,0 +1,43 @@
+package requests
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// BindEvents reads the stream filters and where to resume from: the
+// Last-Event-ID header browsers send when reconnecting, or the last_event_id
+// query parameter for clients that cannot set headers.
+func BindEvents(c *fiber.Ctx) (usecase.EventsInput, error) {
+	var in usecase.EventsInput
+	if v := strings.TrimSpace(c.Query("type")); v != "" {
+		vv := strings.ToUpper(v)
+		in.Type = &vv
+	}
+	if v := strings.TrimSpace(c.Query("city")); v != "" {
+		in.City = &v
+	}
+	if v := strings.TrimSpace(c.Query("state")); v != "" {
+		vv := strings.ToUpper(v)
+		in.State = &vv
+	}
+
+	last := strings.TrimSpace(c.Get("Last-Event-ID"))
+	if last == "" {
+		last = strings.TrimSpace(c.Query("last_event_id"))
+	}
+	if last != "" {
+		id, err := strconv.ParseInt(last, 10, 64)
+		if err != nil {
+			return usecase.EventsInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"last_event_id": "must be an integer"})
+		}
+		in.LastEventID = &id
+	}
+	return in, nil
+}
@@ -0,0 +1,17 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/export"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// BindCreateAdExport reads the export format (csv by default) and the same
+// filters and sort as GET /api/ads, from the query string.
+func BindCreateAdExport(c *fiber.Ctx) (string, usecase.ListAdsInput, error) {
+	format := strings.ToLower(strings.TrimSpace(c.Query("format", export.CSV)))
+	in, err := BindListAds(c)
+	return format, in, err
+}
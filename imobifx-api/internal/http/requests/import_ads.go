@@ -0,0 +1,280 @@
+package requests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+// Import file formats.
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+// importFields are the columns (CSV) or keys (JSONL) an import row may have:
+// the fields of the create form.
+var importFields = []string{
+	"type", "status", "price_brl", "title", "description",
+	"cep", "street", "number", "complement", "neighborhood", "city", "state",
+	"latitude", "longitude",
+	"bedrooms", "bathrooms", "parking_spaces", "area_m2", "condo_fee_brl", "iptu_brl",
+	"publish_at", "expires_at",
+}
+
+// decimalFields accept a decimal comma ("1234,56"), as spreadsheets in
+// pt-BR export them.
+var decimalFields = []string{"price_brl", "latitude", "longitude", "area_m2", "condo_fee_brl", "iptu_brl"}
+
+// BindImportAds reads an import file, sent either as the multipart "file"
+// part or as the raw body, and the import options.
+func BindImportAds(c *fiber.Ctx) ([]usecase.ImportAdRow, usecase.ImportAdsOptions, error) {
+	var opts usecase.ImportAdsOptions
+	switch strings.ToLower(strings.TrimSpace(c.Query("mode", domain.AdImportModePartial))) {
+	case domain.AdImportModePartial:
+	case domain.AdImportModeAllOrNothing:
+		opts.AllOrNothing = true
+	default:
+		return nil, opts, importInvalid("mode", "must be partial or all_or_nothing")
+	}
+	for _, b := range []struct {
+		name string
+		dst  *bool
+	}{
+		{"enrich_cep", &opts.EnrichCEP},
+		{"geocode", &opts.Geocode},
+		{"force", &opts.Force},
+	} {
+		if v := strings.TrimSpace(c.Query(b.name)); v != "" {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, opts, importInvalid(b.name, "must be a boolean")
+			}
+			*b.dst = parsed
+		}
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format")))
+	var body io.Reader
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return nil, opts, importInvalid("file", "could not be read")
+		}
+		defer f.Close()
+		body = f
+		if format == "" {
+			format = ImportFormatFromName(fh.Filename)
+		}
+	} else {
+		body = bytes.NewReader(c.Body())
+		if format == "" {
+			format = importFormatFromContentType(c.Get(fiber.HeaderContentType))
+		}
+	}
+
+	rows, err := ParseAdsImport(body, format)
+	return rows, opts, err
+}
+
+// ImportFormatFromName guesses the format from a file extension; "" when
+// unknown.
+func ImportFormatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return ImportFormatCSV
+	case ".jsonl", ".ndjson":
+		return ImportFormatJSONL
+	}
+	return ""
+}
+
+func importFormatFromContentType(ct string) string {
+	switch strings.ToLower(strings.TrimSpace(strings.Split(ct, ";")[0])) {
+	case "text/csv":
+		return ImportFormatCSV
+	case "application/jsonl", "application/x-ndjson", "application/x-jsonlines":
+		return ImportFormatJSONL
+	}
+	return ""
+}
+
+// ParseAdsImport splits an import file into rows. Problems with a single row
+// are kept in that row; problems with the file as a whole (unknown format or
+// columns, too many rows) are returned as VALIDATION_ERROR.
+func ParseAdsImport(r io.Reader, format string) ([]usecase.ImportAdRow, error) {
+	var rows []usecase.ImportAdRow
+	var err error
+	switch format {
+	case ImportFormatCSV:
+		rows, err = parseImportCSV(r)
+	case ImportFormatJSONL:
+		rows, err = parseImportJSONL(r)
+	default:
+		return nil, importInvalid("format", "must be csv or jsonl")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, importInvalid("file", "has no rows")
+	}
+	return rows, nil
+}
+
+func parseImportCSV(r io.Reader) ([]usecase.ImportAdRow, error) {
+	br := bufio.NewReader(r)
+	first, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, importInvalid("file", "could not be read")
+	}
+	first = strings.TrimPrefix(first, "\uFEFF")
+
+	cr := csv.NewReader(io.MultiReader(strings.NewReader(first), br))
+	if strings.Count(first, ";") > strings.Count(first, ",") {
+		cr.Comma = ';'
+	}
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, importInvalid("file", "has no rows")
+	}
+	if err != nil {
+		return nil, importInvalid("file", err.Error())
+	}
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(importFields, header[i]) {
+			return nil, importInvalid("file", "unknown column "+strconv.Quote(name))
+		}
+		if slices.Contains(header[:i], header[i]) {
+			return nil, importInvalid("file", "duplicate column "+strconv.Quote(name))
+		}
+	}
+
+	var rows []usecase.ImportAdRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !stderrors.Is(err, csv.ErrFieldCount) {
+			// Malformed quoting: the reader cannot find where the row ends, so
+			// the rest of the file cannot be trusted either.
+			return nil, importInvalid("file", err.Error())
+		}
+		if len(rows) == validation.MaxImportRows {
+			return nil, importInvalid("file", "must have at most "+strconv.Itoa(validation.MaxImportRows)+" rows")
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			stderrors.As(err, &perr)
+			rows = append(rows, usecase.ImportAdRow{Line: perr.Line, Err: importInvalid("row", "must have "+strconv.Itoa(len(header))+" columns")})
+			continue
+		}
+		// Only valid once a record was read without error.
+		line, _ := cr.FieldPos(0)
+
+		values := make(map[string]string, len(header))
+		for i, name := range header {
+			values[name] = record[i]
+		}
+		rows = append(rows, importRow(line, values))
+	}
+	return rows, nil
+}
+
+func parseImportJSONL(r io.Reader) ([]usecase.ImportAdRow, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []usecase.ImportAdRow
+	for line := 1; sc.Scan(); line++ {
+		raw := bytes.TrimSpace(sc.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if len(rows) == validation.MaxImportRows {
+			return nil, importInvalid("file", "must have at most "+strconv.Itoa(validation.MaxImportRows)+" rows")
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			rows = append(rows, usecase.ImportAdRow{Line: line, Err: importInvalid("row", "must be a JSON object")})
+			continue
+		}
+
+		values := make(map[string]string, len(obj))
+		details := fiber.Map{}
+		for k, v := range obj {
+			if !slices.Contains(importFields, k) {
+				details[k] = "unknown field"
+				continue
+			}
+			switch v := v.(type) {
+			case nil:
+			case string:
+				values[k] = v
+			case json.Number:
+				values[k] = v.String()
+			case bool:
+				values[k] = strconv.FormatBool(v)
+			default:
+				details[k] = "must be a string or a number"
+			}
+		}
+		if len(details) > 0 {
+			rows = append(rows, usecase.ImportAdRow{Line: line, Err: errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", details)})
+			continue
+		}
+		rows = append(rows, importRow(line, values))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, importInvalid("file", err.Error())
+	}
+	return rows, nil
+}
+
+// importRow binds one row with the same rules as the create form.
+func importRow(line int, values map[string]string) usecase.ImportAdRow {
+	get := func(key string) string {
+		v := strings.TrimSpace(values[key])
+		if slices.Contains(decimalFields, key) && !strings.Contains(v, ".") {
+			v = strings.Replace(v, ",", ".", 1)
+		}
+		return v
+	}
+
+	in, err := adInput(get)
+	if err == nil {
+		in.PublishAt, err = optTime(get("publish_at"), "publish_at")
+	}
+	if err == nil {
+		in.ExpiresAt, err = optTime(get("expires_at"), "expires_at")
+	}
+	if err != nil {
+		return usecase.ImportAdRow{Line: line, Err: err}
+	}
+	return usecase.ImportAdRow{Line: line, Input: in}
+}
+
+func importInvalid(field, msg string) error {
+	return errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{field: msg})
+}
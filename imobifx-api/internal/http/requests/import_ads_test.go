@@ -0,0 +1,40 @@
+package requests_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/http/requests"
+)
+
+func TestParseAdsImport_CSVRowLines(t *testing.T) {
+	rows, err := requests.ParseAdsImport(strings.NewReader("type,price_brl,city\nRENT,1500,Recife\nSALE,300000\n\"RENT\",\"2000\",\"João\nPessoa\"\n"), requests.ImportFormatCSV)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	require.Equal(t, 2, rows[0].Line)
+	require.NoError(t, rows[0].Err)
+	require.Equal(t, 3, rows[1].Line)
+	require.Error(t, rows[1].Err, "missing column")
+	require.Equal(t, 4, rows[2].Line)
+	require.NoError(t, rows[2].Err)
+}
+
+func TestParseAdsImport_CSVMalformedQuoting(t *testing.T) {
+	for _, body := range []string{
+		"type,price_brl\nx\"y,1\n",
+		"type,price_brl\nRENT,1500\n\"RENT,2000\n",
+	} {
+		rows, err := requests.ParseAdsImport(strings.NewReader(body), requests.ImportFormatCSV)
+		require.Nil(t, rows)
+
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr, body)
+		require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+		require.Contains(t, appErr.Details.(fiber.Map)["file"], "line", body)
+	}
+}
@@ -16,36 +16,131 @@ func BindListAds(c *fiber.Ctx) (usecase.ListAdsInput, error) {
 		PageSize: parseInt(c.Query("page_size"), 10),
 	}
 
+	if c.Context().QueryArgs().Has("cursor") {
+		v := strings.TrimSpace(c.Query("cursor"))
+		in.Cursor = &v
+	}
+	if v := strings.TrimSpace(c.Query("with_total")); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return usecase.ListAdsInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"with_total": "must be a boolean"})
+		}
+		in.WithTotal = b
+	}
+	if v := strings.TrimSpace(c.Query("q")); v != "" {
+		in.Q = &v
+	}
+	in.Sort = strings.ToLower(strings.TrimSpace(c.Query("sort")))
 	if v := strings.TrimSpace(c.Query("type")); v != "" {
 		vv := strings.ToUpper(v)
 		in.Type = &vv
 	}
+	if v := strings.TrimSpace(c.Query("status")); v != "" {
+		vv := strings.ToUpper(v)
+		in.Status = &vv
+	}
 	if v := strings.TrimSpace(c.Query("city")); v != "" {
 		in.City = &v
 	}
+	if v := strings.TrimSpace(c.Query("neighborhood")); v != "" {
+		in.Neighborhood = &v
+	}
+	if v := strings.TrimSpace(c.Query("cep_prefix")); v != "" {
+		in.CEPPrefix = &v
+	}
 	if v := strings.TrimSpace(c.Query("state")); v != "" {
 		vv := strings.ToUpper(v)
 		in.State = &vv
 	}
 
-	if v := strings.TrimSpace(c.Query("min_price")); v != "" {
-		f, err := strconv.ParseFloat(v, 64)
+	if v := strings.TrimSpace(c.Query("archived")); v != "" {
+		b, err := strconv.ParseBool(v)
 		if err != nil {
-			return usecase.ListAdsInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"min_price": "must be a number"})
+			return usecase.ListAdsInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"archived": "must be a boolean"})
 		}
-		in.MinPrice = &f
+		in.Archived = b
 	}
-	if v := strings.TrimSpace(c.Query("max_price")); v != "" {
-		f, err := strconv.ParseFloat(v, 64)
-		if err != nil {
-			return usecase.ListAdsInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"max_price": "must be a number"})
+
+	var err error
+	for _, r := range []struct {
+		name     string
+		min, max **float64
+	}{
+		{"price", &in.MinPrice, &in.MaxPrice},
+		{"price_usd", &in.MinPriceUSD, &in.MaxPriceUSD},
+		{"area", &in.MinArea, &in.MaxArea},
+		{"condo_fee", &in.MinCondoFee, &in.MaxCondoFee},
+		{"iptu", &in.MinIPTU, &in.MaxIPTU},
+	} {
+		if *r.min, err = optFloat(c.Query("min_"+r.name), "min_"+r.name); err != nil {
+			return usecase.ListAdsInput{}, err
+		}
+		if *r.max, err = optFloat(c.Query("max_"+r.name), "max_"+r.name); err != nil {
+			return usecase.ListAdsInput{}, err
+		}
+	}
+	for _, r := range []struct {
+		name     string
+		min, max **int
+	}{
+		{"bedrooms", &in.MinBedrooms, &in.MaxBedrooms},
+		{"bathrooms", &in.MinBathrooms, &in.MaxBathrooms},
+		{"parking", &in.MinParking, &in.MaxParking},
+	} {
+		if *r.min, err = optInt(c.Query("min_"+r.name), "min_"+r.name); err != nil {
+			return usecase.ListAdsInput{}, err
+		}
+		if *r.max, err = optInt(c.Query("max_"+r.name), "max_"+r.name); err != nil {
+			return usecase.ListAdsInput{}, err
+		}
+	}
+
+	if in.PriceReducedSince, err = optTime(c.Query("price_reduced_since"), "price_reduced_since"); err != nil {
+		return usecase.ListAdsInput{}, err
+	}
+	if in.Lat, err = optFloat(c.Query("lat"), "lat"); err != nil {
+		return usecase.ListAdsInput{}, err
+	}
+	if in.Lng, err = optFloat(c.Query("lng"), "lng"); err != nil {
+		return usecase.ListAdsInput{}, err
+	}
+	if in.RadiusKM, err = optFloat(c.Query("radius_km"), "radius_km"); err != nil {
+		return usecase.ListAdsInput{}, err
+	}
+	if v := strings.TrimSpace(c.Query("bbox")); v != "" {
+		nums, ok := parseFloats(v, ",")
+		if !ok || len(nums) != 4 {
+			return usecase.ListAdsInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"bbox": "must be min_lng,min_lat,max_lng,max_lat"})
+		}
+		in.BBox = &[4]float64{nums[0], nums[1], nums[2], nums[3]}
+	}
+	if v := strings.TrimSpace(c.Query("polygon")); v != "" {
+		for _, vertex := range strings.Split(v, ";") {
+			nums, ok := parseFloats(vertex, ",")
+			if !ok || len(nums) != 2 {
+				return usecase.ListAdsInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"polygon": "must be lng,lat pairs separated by ';'"})
+			}
+			in.Polygon = append(in.Polygon, [2]float64{nums[0], nums[1]})
 		}
-		in.MaxPrice = &f
 	}
 
 	return in, nil
 }
 
+// parseFloats splits v by sep and parses every part as a float.
+func parseFloats(v, sep string) ([]float64, bool) {
+	parts := strings.Split(v, sep)
+	out := make([]float64, 0, len(parts))
+	for _, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, false
+		}
+		out = append(out, f)
+	}
+	return out, true
+}
+
 func parseInt(v string, def int) int {
 	if strings.TrimSpace(v) == "" {
 		return def
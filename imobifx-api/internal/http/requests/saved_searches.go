@@ -0,0 +1,43 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// listOnlyParams are the GET /api/ads parameters that do not filter, left out
+// of the query kept with a saved search.
+var listOnlyParams = []string{"page", "page_size", "cursor", "with_total", "sort"}
+
+// BindSavedSearch reads the name and e-mail from the JSON body and the filters
+// from the query string, the same as GET /api/ads.
+func BindSavedSearch(c *fiber.Ctx) (usecase.SavedSearchInput, error) {
+	var in usecase.SavedSearchInput
+	if err := c.BodyParser(&in); err != nil {
+		return usecase.SavedSearchInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "JSON inválido.", nil)
+	}
+
+	filters, err := BindListAds(c)
+	if err != nil {
+		return usecase.SavedSearchInput{}, err
+	}
+	in.Filters = filters
+
+	args := fiber.AcquireArgs()
+	defer fiber.ReleaseArgs(args)
+	c.Context().QueryArgs().CopyTo(args)
+	for _, k := range listOnlyParams {
+		args.Del(k)
+	}
+	in.Query = args.String()
+	return in, nil
+}
+
+// BindSavedSearchMatches reads the page of matches to list.
+func BindSavedSearchMatches(c *fiber.Ctx) (page, pageSize int) {
+	return parseInt(c.Query("page"), 1), parseInt(c.Query("page_size"), 10)
+}
@@ -0,0 +1,66 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+func BindReplaceAd(c *fiber.Ctx) (usecase.CreateAdInput, error) {
+	return bindAdForm(c)
+}
+
+func BindPatchAd(c *fiber.Ctx) (usecase.PatchAdInput, error) {
+	var in usecase.PatchAdInput
+	if err := c.BodyParser(&in); err != nil {
+		return usecase.PatchAdInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", nil)
+	}
+
+	trimPtr(in.Title)
+	trimPtr(in.Description)
+	trimPtr(in.CEP)
+	trimPtr(in.Street)
+	trimPtr(in.Number)
+	trimPtr(in.Complement)
+	trimPtr(in.Neighborhood)
+	trimPtr(in.City)
+	upperPtr(in.Type)
+	upperPtr(in.State)
+
+	return in, nil
+}
+
+func trimPtr(v *string) {
+	if v != nil {
+		*v = strings.TrimSpace(*v)
+	}
+}
+
+func upperPtr(v *string) {
+	if v != nil {
+		*v = strings.ToUpper(strings.TrimSpace(*v))
+	}
+}
+
+func BindTransitionAd(c *fiber.Ctx) (usecase.TransitionAdInput, error) {
+	var in usecase.TransitionAdInput
+	if err := c.BodyParser(&in); err != nil {
+		return usecase.TransitionAdInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", nil)
+	}
+	in.Status = strings.ToUpper(strings.TrimSpace(in.Status))
+	return in, nil
+}
+
+func BindRenewAd(c *fiber.Ctx) (*time.Time, error) {
+	var in usecase.RenewAdInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&in); err != nil {
+			return nil, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", nil)
+		}
+	}
+	return optTime(in.ExpiresAt, "expires_at")
+}
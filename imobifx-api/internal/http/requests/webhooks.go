@@ -0,0 +1,25 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+func BindWebhook(c *fiber.Ctx) (usecase.WebhookInput, error) {
+	var in usecase.WebhookInput
+	if err := c.BodyParser(&in); err != nil {
+		return usecase.WebhookInput{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "JSON inválido.", nil)
+	}
+	return in, nil
+}
+
+// BindWebhookDeliveries reads the status filter and the page of the delivery
+// log to list.
+func BindWebhookDeliveries(c *fiber.Ctx) (status string, page, pageSize int) {
+	return strings.ToUpper(strings.TrimSpace(c.Query("status"))), parseInt(c.Query("page"), 1), parseInt(c.Query("page_size"), 10)
+}
@@ -4,16 +4,23 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/josinaldojr/imobifx-api/internal/config"
 	"github.com/josinaldojr/imobifx-api/internal/http/handlers"
+	middlewares "github.com/josinaldojr/imobifx-api/internal/http/midlewares"
 	"github.com/josinaldojr/imobifx-api/internal/repo"
 	"github.com/josinaldojr/imobifx-api/internal/service"
 )
 
 type Deps struct {
-	Config  config.Config
-	Ads     *service.AdsService
-	Quotes  *service.QuotesService
-	DB      *repo.DB
-	Address *service.AddressService
+	Config   config.Config
+	Ads      *service.AdsService
+	Quotes   *service.QuotesService
+	DB       *repo.DB
+	Address  *service.AddressService
+	Import   *service.ImportService
+	Export   *service.ExportService
+	Feeds    *service.FeedService
+	Searches *service.SavedSearchService
+	Webhooks *service.WebhookService
+	Events   *service.EventHub
 }
 
 func RegisterRoutes(app *fiber.App, d Deps) {
@@ -24,10 +31,51 @@ func RegisterRoutes(app *fiber.App, d Deps) {
 
 	api := app.Group("/api")
 
+	// Uploads are registered first, with their own limit: they answer
+	// without reaching the general limit below, which every later route
+	// goes through.
+	upload := middlewares.BodyLimit(d.Config.UploadBodyLimitBytes)
+	api.Post("/ads", upload, handlers.CreateAd(d.Config, d.Ads))
+	api.Post("/ads/import", upload, handlers.ImportAds(d.Import))
+	api.Post("/ads/:id/images", upload, handlers.AddAdImages(d.Ads))
+	app.Use(middlewares.BodyLimit(d.Config.BodyLimitBytes))
+
 	api.Get("/addresses/:cep", handlers.Address(d.Address))
 	api.Post("/quotes", handlers.CreateQuote(d.Quotes))
 	api.Get("/quotes/current", handlers.CurrentQuote(d.Quotes))
+	api.Get("/events", handlers.StreamEvents(d.Events))
 
-	api.Post("/ads", handlers.CreateAd(d.Config, d.Ads))
+	api.Post("/ads/exports", handlers.CreateAdExport(d.Export))
+	api.Get("/ads/exports/:id", handlers.GetAdExport(d.Export))
+	api.Get("/ads/exports/:id/download", handlers.DownloadAdExport(d.Export))
+	api.Get("/feeds/:feed", handlers.GetFeed(d.Feeds))
+	api.Get("/admin/ads/duplicates", handlers.ListDuplicateAds(d.Ads))
+	api.Get("/stats/ads", handlers.AdStats(d.Ads))
 	api.Get("/ads", handlers.ListAds(d.Ads))
+	api.Get("/ads/:id", handlers.GetAd(d.Ads))
+	api.Get("/ads/:id/price-history", handlers.GetAdPriceHistory(d.Ads))
+	api.Put("/ads/:id", handlers.ReplaceAd(d.Ads))
+	api.Patch("/ads/:id", handlers.PatchAd(d.Ads))
+	api.Delete("/ads/:id", handlers.DeleteAd(d.Ads))
+	api.Post("/ads/:id/restore", handlers.RestoreAd(d.Ads))
+	api.Post("/ads/:id/status", handlers.TransitionAd(d.Ads))
+	api.Post("/ads/:id/renew", handlers.RenewAd(d.Ads))
+	api.Put("/ads/:id/images/order", handlers.ReorderAdImages(d.Ads))
+	api.Put("/ads/:id/images/:imageId/cover", handlers.SetAdCover(d.Ads))
+	api.Delete("/ads/:id/images/:imageId", handlers.DeleteAdImage(d.Ads))
+
+	api.Post("/saved-searches", handlers.CreateSavedSearch(d.Searches))
+	api.Get("/saved-searches", handlers.ListSavedSearches(d.Searches))
+	api.Get("/saved-searches/:id", handlers.GetSavedSearch(d.Searches))
+	api.Put("/saved-searches/:id", handlers.ReplaceSavedSearch(d.Searches))
+	api.Delete("/saved-searches/:id", handlers.DeleteSavedSearch(d.Searches))
+	api.Get("/saved-searches/:id/matches", handlers.ListSavedSearchMatches(d.Searches))
+
+	api.Post("/webhooks", handlers.CreateWebhook(d.Webhooks))
+	api.Get("/webhooks", handlers.ListWebhooks(d.Webhooks))
+	api.Get("/webhooks/:id", handlers.GetWebhook(d.Webhooks))
+	api.Put("/webhooks/:id", handlers.ReplaceWebhook(d.Webhooks))
+	api.Delete("/webhooks/:id", handlers.DeleteWebhook(d.Webhooks))
+	api.Get("/webhooks/:id/deliveries", handlers.ListWebhookDeliveries(d.Webhooks))
+	api.Post("/webhooks/:id/deliveries/:deliveryId/redeliver", handlers.RedeliverWebhookDelivery(d.Webhooks))
 }
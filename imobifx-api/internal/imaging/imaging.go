@@ -0,0 +1,149 @@
+// Package imaging decodes uploaded photos and re-encodes them into the
+// standard sizes served by the API. Re-encoding drops every metadata block
+// (EXIF, GPS, XMP); the EXIF orientation is applied to the pixels first so
+// phone photos keep the right side up.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// Size is a named variant whose longest side is at most MaxSide pixels.
+type Size struct {
+	Name    string
+	MaxSide int
+}
+
+// Sizes lists the variants produced for every upload, smallest first.
+var Sizes = []Size{
+	{Name: domain.ImageSizeThumb, MaxSide: 320},
+	{Name: domain.ImageSizeMedium, MaxSide: 1024},
+	{Name: domain.ImageSizeLarge, MaxSide: 1920},
+}
+
+// MaxPixels bounds width*height so a tiny file cannot decode into a huge
+// bitmap.
+const MaxPixels = 50_000_000
+
+const jpegQuality = 82
+
+var (
+	ErrUnsupported = errors.New("imaging: unsupported or corrupt image")
+	ErrTooLarge    = errors.New("imaging: image dimensions too large")
+)
+
+// Check reads only the image header and reports whether Render would accept
+// it.
+func Check(r io.Reader) error {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return ErrUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return ErrUnsupported
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// Render decodes r and returns one JPEG per entry of Sizes, keyed by size
+// name. Images are never upscaled. Sizes must be ordered smallest first.
+func Render(r io.Reader) (map[string][]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := Check(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	orientation := jpegOrientation(data)
+
+	// Each size is scaled from the next larger one, which is much cheaper than
+	// scaling the full-resolution source every time.
+	out := make(map[string][]byte, len(Sizes))
+	for i := len(Sizes) - 1; i >= 0; i-- {
+		size := Sizes[i]
+		scaled := fit(src, size.MaxSide)
+		src = scaled
+		img := orient(scaled, orientation)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		out[size.Name] = buf.Bytes()
+	}
+	return out, nil
+}
+
+// fit scales src down so its longest side is at most maxSide, flattening any
+// transparency onto white since the output is JPEG.
+func fit(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if longest := max(w, h); longest > maxSide {
+		w = max(1, w*maxSide/longest)
+		h = max(1, h*maxSide/longest)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+	return dst
+}
+
+// orient applies an EXIF orientation (1..8) to img.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,113 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/imaging"
+)
+
+func TestRender_ProducesEverySizeWithinBounds(t *testing.T) {
+	out, err := imaging.Render(bytes.NewReader(encodePNG(t, 2400, 1800)))
+	require.NoError(t, err)
+	require.Len(t, out, len(imaging.Sizes))
+
+	for _, size := range imaging.Sizes {
+		cfg := decodeConfig(t, out[size.Name])
+		require.Equal(t, size.MaxSide, cfg.Width, size.Name)
+		require.Equal(t, size.MaxSide*3/4, cfg.Height, size.Name)
+	}
+}
+
+func TestRender_DoesNotUpscale(t *testing.T) {
+	out, err := imaging.Render(bytes.NewReader(encodePNG(t, 200, 100)))
+	require.NoError(t, err)
+
+	cfg := decodeConfig(t, out[domain.ImageSizeLarge])
+	require.Equal(t, 200, cfg.Width)
+	require.Equal(t, 100, cfg.Height)
+}
+
+func TestRender_AppliesOrientationAndStripsExif(t *testing.T) {
+	src := withExifOrientation(t, encodeJPEG(t, 400, 200), 6)
+
+	out, err := imaging.Render(bytes.NewReader(src))
+	require.NoError(t, err)
+
+	large := out[domain.ImageSizeLarge]
+	cfg := decodeConfig(t, large)
+	require.Equal(t, 200, cfg.Width)
+	require.Equal(t, 400, cfg.Height)
+	require.False(t, bytes.Contains(large, []byte("Exif\x00\x00")))
+}
+
+func TestCheck(t *testing.T) {
+	require.NoError(t, imaging.Check(bytes.NewReader(encodePNG(t, 10, 10))))
+	require.ErrorIs(t, imaging.Check(bytes.NewReader([]byte("not an image"))), imaging.ErrUnsupported)
+
+	_, err := imaging.Render(bytes.NewReader([]byte("not an image")))
+	require.ErrorIs(t, err, imaging.ErrUnsupported)
+}
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, solid(w, h)))
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, solid(w, h), nil))
+	return buf.Bytes()
+}
+
+func solid(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	return img
+}
+
+// withExifOrientation inserts a minimal APP1 EXIF segment holding only the
+// orientation tag right after the JPEG SOI marker.
+func withExifOrientation(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(seg)+2))
+	app1 = append(app1, seg...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, app1...)
+	return append(out, jpg[2:]...)
+}
+
+func decodeConfig(t *testing.T, data []byte) image.Config {
+	t.Helper()
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, "jpeg", format)
+	return cfg
+}
@@ -0,0 +1,11 @@
+// Package geocode turns ad addresses into coordinates. Nominatim queries an
+// OpenStreetMap Nominatim server; Local answers from a fixed table and is
+// meant for tests and offline development.
+package geocode
+
+import "errors"
+
+var (
+	ErrNotFound    = errors.New("geocode: address not found")
+	ErrUnavailable = errors.New("geocode: unavailable")
+)
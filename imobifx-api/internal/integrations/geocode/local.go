@@ -0,0 +1,30 @@
+package geocode
+
+import (
+	"context"
+	"strings"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// Local resolves addresses from a fixed table keyed by CEP digits. A key may
+// also be a CEP prefix ("58038"), which matches every CEP starting with it;
+// the longest matching key wins. It never calls the network, so results are
+// deterministic.
+type Local struct {
+	points map[string]domain.GeoPoint
+}
+
+func NewLocal(points map[string]domain.GeoPoint) *Local {
+	return &Local{points: points}
+}
+
+func (l *Local) Geocode(ctx context.Context, addr domain.Address) (domain.GeoPoint, error) {
+	cep := strings.NewReplacer("-", "", ".", "", " ", "").Replace(addr.CEP)
+	for n := len(cep); n > 0; n-- {
+		if p, ok := l.points[cep[:n]]; ok {
+			return p, nil
+		}
+	}
+	return domain.GeoPoint{}, ErrNotFound
+}
@@ -0,0 +1,191 @@
+package geocode
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+const (
+	// nominatimCacheSize and nominatimCacheTTL bound the answers kept, found
+	// or not: addresses repeat a lot, in imports especially.
+	nominatimCacheSize = 10000
+	nominatimCacheTTL  = 24 * time.Hour
+)
+
+// Nominatim geocodes with the structured search of a Nominatim server. The
+// public server requires an identifying User-Agent and at most one request
+// per second: requests are spaced by interval across every caller, and
+// answers are cached so repeated addresses do not count against it.
+type Nominatim struct {
+	baseURL   string
+	userAgent string
+	http      *http.Client
+	limiter   *rate.Limiter
+	cache     *searchCache
+}
+
+func NewNominatim(baseURL, userAgent string, timeout, interval time.Duration) *Nominatim {
+	return &Nominatim{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		userAgent: userAgent,
+		http: &http.Client{
+			Timeout: timeout,
+		},
+		limiter: rate.NewLimiter(rate.Every(interval), 1),
+		cache:   newSearchCache(nominatimCacheSize, nominatimCacheTTL),
+	}
+}
+
+type nominatimResult struct {
+	Lat string `json:"lat"`
+	Lon string `json:"lon"`
+}
+
+// Geocode looks the street up within its city; the CEP narrows the search
+// when Nominatim knows it, but is dropped on a miss because OpenStreetMap
+// coverage of Brazilian postcodes is sparse.
+func (n *Nominatim) Geocode(ctx context.Context, addr domain.Address) (domain.GeoPoint, error) {
+	start := time.Now()
+	defer func() {
+		slog.Debug("geocode_call",
+			slog.String("cep", addr.CEP),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+		)
+	}()
+
+	q := url.Values{
+		"format":       {"jsonv2"},
+		"limit":        {"1"},
+		"countrycodes": {"br"},
+		"street":       {addr.Street},
+		"city":         {addr.City},
+		"state":        {addr.State},
+	}
+	if addr.CEP != "" {
+		withCEP := url.Values{"postalcode": {addr.CEP}}
+		for k, v := range q {
+			withCEP[k] = v
+		}
+		p, err := n.search(ctx, withCEP)
+		if !errors.Is(err, ErrNotFound) {
+			return p, err
+		}
+	}
+	return n.search(ctx, q)
+}
+
+// search answers from the cache, or queries the server once the rate limit
+// allows. Only definite answers are cached; an unavailable server is asked
+// again next time.
+func (n *Nominatim) search(ctx context.Context, q url.Values) (domain.GeoPoint, error) {
+	key := q.Encode()
+	if p, err, ok := n.cache.get(key); ok {
+		return p, err
+	}
+	p, err := n.query(ctx, key)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		n.cache.put(key, p, err)
+	}
+	return p, err
+}
+
+func (n *Nominatim) query(ctx context.Context, query string) (domain.GeoPoint, error) {
+	if err := n.limiter.Wait(ctx); err != nil {
+		return domain.GeoPoint{}, ErrUnavailable
+	}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, n.baseURL+"/search?"+query, nil)
+	req.Header.Set("User-Agent", n.userAgent)
+	req.Header.Set("Accept-Language", "pt-BR")
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return domain.GeoPoint{}, ErrUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return domain.GeoPoint{}, ErrUnavailable
+	}
+
+	var results []nominatimResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return domain.GeoPoint{}, ErrUnavailable
+	}
+	if len(results) == 0 {
+		return domain.GeoPoint{}, ErrNotFound
+	}
+
+	lat, err1 := strconv.ParseFloat(results[0].Lat, 64)
+	lng, err2 := strconv.ParseFloat(results[0].Lon, 64)
+	if err1 != nil || err2 != nil {
+		return domain.GeoPoint{}, ErrUnavailable
+	}
+	return domain.GeoPoint{Lat: lat, Lng: lng}, nil
+}
+
+// searchCache is a least recently used cache of search answers.
+type searchCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type searchEntry struct {
+	key     string
+	point   domain.GeoPoint
+	err     error
+	expires time.Time
+}
+
+func newSearchCache(size int, ttl time.Duration) *searchCache {
+	return &searchCache{size: size, ttl: ttl, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *searchCache) get(key string) (domain.GeoPoint, error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return domain.GeoPoint{}, nil, false
+	}
+	e := el.Value.(*searchEntry)
+	if time.Now().After(e.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return domain.GeoPoint{}, nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.point, e.err, true
+}
+
+func (c *searchCache) put(key string, p domain.GeoPoint, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &searchEntry{key: key, point: p, err: err, expires: time.Now().Add(c.ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(e)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*searchEntry).key)
+	}
+}
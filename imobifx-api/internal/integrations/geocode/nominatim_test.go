@@ -0,0 +1,179 @@
+package geocode_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/integrations/geocode"
+)
+
+var addr = domain.Address{CEP: "58038-000", Street: "Avenida Cabo Branco", City: "João Pessoa", State: "PB"}
+
+func TestNominatim_OK(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "imobifx-test" {
+			t.Errorf("unexpected user agent %q", r.Header.Get("User-Agent"))
+		}
+		if r.URL.Query().Get("street") != "Avenida Cabo Branco" || r.URL.Query().Get("postalcode") != "58038-000" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"lat":"-7.1195","lon":"-34.8229"}]`))
+	}))
+	defer srv.Close()
+
+	g := geocode.NewNominatim(srv.URL, "imobifx-test", 500*time.Millisecond, 0)
+
+	p, err := g.Geocode(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if p.Lat != -7.1195 || p.Lng != -34.8229 {
+		t.Fatalf("unexpected point: %+v", p)
+	}
+}
+
+func TestNominatim_RetriesWithoutCEP(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Has("postalcode") {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"lat":"-7.1","lon":"-34.8"}]`))
+	}))
+	defer srv.Close()
+
+	g := geocode.NewNominatim(srv.URL, "imobifx-test", 500*time.Millisecond, 0)
+
+	p, err := g.Geocode(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if calls != 2 || p.Lat != -7.1 {
+		t.Fatalf("expected fallback search, got %d calls and %+v", calls, p)
+	}
+}
+
+func TestNominatim_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	g := geocode.NewNominatim(srv.URL, "imobifx-test", 500*time.Millisecond, 0)
+
+	_, err := g.Geocode(context.Background(), addr)
+	if err != geocode.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestNominatim_Unavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	g := geocode.NewNominatim(srv.URL, "imobifx-test", 500*time.Millisecond, 0)
+
+	_, err := g.Geocode(context.Background(), addr)
+	if err != geocode.ErrUnavailable {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestNominatim_CachesAnswers(t *testing.T) {
+	calls := 0
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+		if r.URL.Query().Get("city") == "Natal" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"lat":"-7.1","lon":"-34.8"}]`))
+	}))
+	defer srv.Close()
+
+	g := geocode.NewNominatim(srv.URL, "imobifx-test", 500*time.Millisecond, 0)
+	for i := 0; i < 3; i++ {
+		if p, err := g.Geocode(context.Background(), addr); err != nil || p.Lat != -7.1 {
+			t.Fatalf("expected cached point, got %+v, %v", p, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+
+	// Misses are cached too, fallback search included.
+	natal := domain.Address{Street: "Rua A", City: "Natal", State: "RN"}
+	for i := 0; i < 2; i++ {
+		if _, err := g.Geocode(context.Background(), natal); err != geocode.ErrNotFound {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+
+	// An unavailable server is asked again.
+	status = http.StatusServiceUnavailable
+	other := domain.Address{Street: "Rua B", City: "Recife", State: "PE"}
+	g.Geocode(context.Background(), other)
+	g.Geocode(context.Background(), other)
+	if calls != 4 {
+		t.Fatalf("expected 4 calls, got %d", calls)
+	}
+}
+
+func TestNominatim_SpacesRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"lat":"-7.1","lon":"-34.8"}]`))
+	}))
+	defer srv.Close()
+
+	g := geocode.NewNominatim(srv.URL, "imobifx-test", 500*time.Millisecond, 50*time.Millisecond)
+	start := time.Now()
+	for _, street := range []string{"Rua A", "Rua B", "Rua C"} {
+		if _, err := g.Geocode(context.Background(), domain.Address{Street: street, City: "Recife", State: "PE"}); err != nil {
+			t.Fatalf("expected nil err, got %v", err)
+		}
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Fatalf("3 requests took %v, expected them spaced by 50ms", d)
+	}
+
+	// A caller that cannot wait for its turn gives up.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.Geocode(ctx, domain.Address{Street: "Rua D", City: "Recife", State: "PE"}); err != geocode.ErrUnavailable {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestLocal_LongestPrefix(t *testing.T) {
+	g := geocode.NewLocal(map[string]domain.GeoPoint{
+		"58":       {Lat: -7, Lng: -36},
+		"58038":    {Lat: -7.12, Lng: -34.83},
+		"58038000": {Lat: -7.1195, Lng: -34.8229},
+	})
+
+	p, err := g.Geocode(context.Background(), domain.Address{CEP: "58038-000"})
+	if err != nil || p.Lat != -7.1195 {
+		t.Fatalf("expected exact match, got %+v, %v", p, err)
+	}
+	p, err = g.Geocode(context.Background(), domain.Address{CEP: "58038-110"})
+	if err != nil || p.Lat != -7.12 {
+		t.Fatalf("expected prefix match, got %+v, %v", p, err)
+	}
+	if _, err := g.Geocode(context.Background(), domain.Address{CEP: "01310-100"}); err != geocode.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package notify delivers saved search alerts. Log writes them to the
+// application log, which is enough to wire an external mailer that tails it
+// and for development.
+package notify
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// Log records one entry per saved search with the IDs of its new ads.
+type Log struct {
+	log *slog.Logger
+}
+
+func NewLog(log *slog.Logger) *Log {
+	return &Log{log: log}
+}
+
+func (l *Log) Notify(ctx context.Context, search domain.SavedSearch, ads []domain.Ad) error {
+	ids := make([]string, len(ads))
+	for i, a := range ads {
+		ids[i] = a.ID
+	}
+	attrs := []any{
+		slog.String("saved_search_id", search.ID),
+		slog.String("name", search.Name),
+		slog.String("query", search.Query),
+		slog.Any("ad_ids", ids),
+	}
+	if search.Email != nil {
+		attrs = append(attrs, slog.String("email", *search.Email))
+	}
+	l.log.InfoContext(ctx, "saved_search_matched", attrs...)
+	return nil
+}
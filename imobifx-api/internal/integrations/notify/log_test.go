@@ -0,0 +1,30 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/integrations/notify"
+)
+
+func TestLog_Notify(t *testing.T) {
+	var buf bytes.Buffer
+	n := notify.NewLog(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	email := "ana@example.com"
+	search := domain.SavedSearch{ID: "s1", Name: "Aluguel em JP", Email: &email, Query: "type=RENT&max_price=3000"}
+	require.NoError(t, n.Notify(context.Background(), search, []domain.Ad{{ID: "a1"}, {ID: "a2"}}))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "saved_search_matched", entry["msg"])
+	require.Equal(t, "s1", entry["saved_search_id"])
+	require.Equal(t, "ana@example.com", entry["email"])
+	require.Equal(t, []any{"a1", "a2"}, entry["ad_ids"])
+}
@@ -0,0 +1,148 @@
+// Package webhook posts outbox events to the subscribed endpoints. Every
+// request is signed with the webhook secret so receivers can check it came
+// from us and was not replayed later.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+const (
+	// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>", the
+	// HMAC being of "<t>.<body>" keyed with the webhook secret.
+	SignatureHeader = "X-Imobifx-Signature"
+	EventHeader     = "X-Imobifx-Event"
+	DeliveryHeader  = "X-Imobifx-Delivery"
+
+	userAgent = "imobifx-webhooks/1"
+)
+
+// Client posts deliveries. Redirects are not followed: an endpoint that moved
+// must be updated in its webhook.
+//
+// Unless allowPrivate is set, connections to loopback, private, link-local
+// and other non-public addresses are refused. The check runs on the address
+// actually dialed, so a public name resolving to an internal address is
+// caught too; proxies are not used for the same reason.
+type Client struct {
+	http *http.Client
+}
+
+func NewClient(timeout time.Duration, allowPrivate bool) *Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = publicOnly
+	}
+	return &Client{
+		http: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:           dialer.DialContext,
+				ForceAttemptHTTP2:     true,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// ErrForbiddenAddress is returned when an endpoint resolves to an address
+// webhooks may not reach.
+var ErrForbiddenAddress = errors.New("webhook: destination address not allowed")
+
+// reserved are non-public ranges netip has no predicate for: "this network"
+// and the carrier-grade NAT space of RFC 6598.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// publicOnly is a net.Dialer Control refusing non-public destinations.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	ip := ap.Addr().Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	for _, p := range reserved {
+		if p.Contains(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+		}
+	}
+	return nil
+}
+
+// Sign computes the SignatureHeader value of body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts the event of d as JSON. Any 2xx answer accepts it; the status
+// is returned whenever a response was received, even with an error.
+func (c *Client) Send(ctx context.Context, d domain.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	defer func() {
+		slog.Debug("webhook_call",
+			slog.String("webhook_id", d.WebhookID),
+			slog.String("delivery_id", d.ID),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+		)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, d.Event.Type)
+	req.Header.Set(DeliveryHeader, d.ID)
+	req.Header.Set(SignatureHeader, Sign(d.Secret, start, body))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,105 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/integrations/webhook"
+)
+
+func delivery(url string) domain.WebhookDelivery {
+	return domain.WebhookDelivery{
+		ID:        "d1",
+		WebhookID: "w1",
+		URL:       url,
+		Secret:    "s3cr3t-s3cr3t-s3cr3t",
+		Event: domain.Event{
+			ID:        42,
+			Type:      domain.EventQuoteCreated,
+			CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Data:      json.RawMessage(`{"brl_to_usd":0.2}`),
+		},
+	}
+}
+
+func TestSend_SignsTheBody(t *testing.T) {
+	var got http.Header
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	status, err := webhook.NewClient(time.Second, true).Send(context.Background(), delivery(srv.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, status)
+
+	require.Equal(t, domain.EventQuoteCreated, got.Get(webhook.EventHeader))
+	require.Equal(t, "d1", got.Get(webhook.DeliveryHeader))
+	require.JSONEq(t, `{"id":42,"type":"quote.created","created_at":"2026-01-02T03:04:05Z","data":{"brl_to_usd":0.2}}`, string(body))
+
+	// The receiver recomputes the signature from the timestamp it was given.
+	var ts int64
+	_, err = fmt.Sscanf(got.Get(webhook.SignatureHeader), "t=%d,", &ts)
+	require.NoError(t, err)
+	require.Equal(t, webhook.Sign("s3cr3t-s3cr3t-s3cr3t", time.Unix(ts, 0), body), got.Get(webhook.SignatureHeader))
+	require.NotEqual(t, webhook.Sign("other", time.Unix(ts, 0), body), got.Get(webhook.SignatureHeader))
+}
+
+func TestSend_RejectsNon2xxAndRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/", http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := webhook.NewClient(time.Second, true)
+	status, err := c.Send(context.Background(), delivery(srv.URL))
+	require.Error(t, err)
+	require.Equal(t, http.StatusInternalServerError, status)
+
+	status, err = c.Send(context.Background(), delivery(srv.URL+"/moved"))
+	require.Error(t, err)
+	require.Equal(t, http.StatusMovedPermanently, status)
+}
+
+func TestSend_RefusesNonPublicAddresses(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := webhook.NewClient(time.Second, false)
+	for _, url := range []string{
+		srv.URL,
+		"http://localhost:1/",
+		"http://10.0.0.1/",
+		"http://192.168.1.10/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]:1/",
+		"http://[fd00::1]/",
+		"http://0.0.0.0:1/",
+		"http://100.64.0.1/",
+	} {
+		status, err := c.Send(context.Background(), delivery(url))
+		require.ErrorIs(t, err, webhook.ErrForbiddenAddress, url)
+		require.Zero(t, status, url)
+	}
+	require.Zero(t, hits)
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Runner owns a set of background jobs and stops them together.
+type Runner struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRunner() *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{ctx: ctx, cancel: cancel}
+}
+
+// Every starts a goroutine that calls fn right away and then once per
+// interval until the runner is stopped.
+func (r *Runner) Every(name string, interval time.Duration, fn func(context.Context) error) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		Every(r.ctx, name, interval, fn)
+	}()
+}
+
+// Stop cancels every job and waits for the running iterations to return, or
+// for ctx to be done.
+func (r *Runner) Stop(ctx context.Context) error {
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Every runs fn right away and then once per interval until ctx is done.
+// Errors are logged and do not stop the loop.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		start := time.Now()
+		if err := fn(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("job_failed",
+				slog.String("job", name),
+				slog.String("error", err.Error()),
+			)
+		} else {
+			slog.Debug("job_run",
+				slog.String("job", name),
+				slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package jobs_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/jobs"
+)
+
+func TestRunner_RunsImmediatelyAndStops(t *testing.T) {
+	r := jobs.NewRunner()
+
+	var calls atomic.Int32
+	started := make(chan struct{}, 1)
+	r.Every("test", time.Hour, func(ctx context.Context) error {
+		calls.Add(1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("job did not run right away")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, r.Stop(ctx))
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestRunner_Stop_WaitsForRunningIteration(t *testing.T) {
+	r := jobs.NewRunner()
+
+	var finished atomic.Bool
+	started := make(chan struct{})
+	r.Every("slow", time.Hour, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(true)
+		return ctx.Err()
+	})
+
+	<-started
+	require.NoError(t, r.Stop(context.Background()))
+	require.True(t, finished.Load())
+}
@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// lockAdAddresses takes a transaction-level advisory lock on the CEP and
+// type of each ad. Duplicate checks take it before reading the candidates, so
+// two concurrent creates of the same property cannot both pass; it is freed
+// when the transaction ends. The key is coarser than the normalized address
+// duplicates are judged on, but every probable duplicate shares it.
+func lockAdAddresses(ctx context.Context, tx pgx.Tx, ads []domain.Ad) error {
+	keys := make([]string, 0, len(ads))
+	for _, a := range ads {
+		keys = append(keys, a.CEP+"|"+a.Type)
+	}
+	// Taking the locks in order keeps concurrent imports from deadlocking.
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	for _, k := range keys {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listOpenAdsAt returns the open ads (see domain.AdOpenStatuses) sharing the
+// CEP and type of any of ads, oldest first. Duplicate checks narrow
+// candidates with it and compare the rest of the address in Go.
+func listOpenAdsAt(ctx context.Context, q querier, ads []domain.Ad) ([]domain.Ad, error) {
+	ceps := make([]string, 0, len(ads))
+	types := make([]string, 0, len(ads))
+	for _, a := range ads {
+		ceps = append(ceps, a.CEP)
+		types = append(types, a.Type)
+	}
+	rows, err := q.Query(ctx, `
+		SELECT `+adColumns+`
+		FROM ads
+		WHERE (cep, type) IN (SELECT * FROM unnest($1::text[], $2::text[]))
+		  AND status = ANY($3) AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`, ceps, types, domain.AdOpenStatuses)
+	if err != nil {
+		return nil, err
+	}
+	return collectAds(rows)
+}
+
+// ListOpenAdsSharingCEP returns the open ads whose CEP and type are shared by
+// at least one other open ad, ordered by CEP, type and age: the candidates
+// for duplicate clusters, grouped.
+func (d *DB) ListOpenAdsSharingCEP(ctx context.Context) ([]domain.Ad, error) {
+	rows, err := d.Pool.Query(ctx, `
+		WITH open_ads AS (
+			SELECT * FROM ads WHERE status = ANY($1) AND deleted_at IS NULL
+		)
+		SELECT `+adColumns+`
+		FROM open_ads
+		WHERE (cep, type) IN (SELECT cep, type FROM open_ads GROUP BY cep, type HAVING count(*) > 1)
+		ORDER BY cep, type, created_at, id
+	`, domain.AdOpenStatuses)
+	if err != nil {
+		return nil, err
+	}
+	return collectAds(rows)
+}
@@ -0,0 +1,73 @@
+//go:build integration
+
+package repo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+)
+
+func TestAdExports_Lifecycle(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ad_exports")
+
+	created, err := db.CreateAdExport(ctx, "csv", []byte(`{"City":"Joao Pessoa"}`))
+	require.NoError(t, err)
+	require.Equal(t, domain.AdExportPending, created.Status)
+	require.JSONEq(t, `{"City":"Joao Pessoa"}`, string(created.Filters))
+
+	claimed, err := db.ClaimAdExport(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, created.ID, claimed.ID)
+	require.Equal(t, domain.AdExportRunning, claimed.Status)
+
+	none, err := db.ClaimAdExport(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Nil(t, none, "a fresh RUNNING job is not claimed twice")
+
+	// A stale claim is taken over, and the first worker can no longer finish.
+	reclaimed, err := db.ClaimAdExport(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, reclaimed)
+	ok, err := db.FailAdExport(ctx, claimed.ID, *claimed.ClaimedAt, "boom", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	quote := &domain.Quote{BrlToUsd: 0.2, EffectiveAt: time.Now().UTC().Truncate(time.Second)}
+	ok, err = db.CompleteAdExport(ctx, reclaimed.ID, *reclaimed.ClaimedAt, reclaimed.ID+".csv", 12, 3456, quote, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	done, err := db.GetAdExport(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.AdExportDone, done.Status)
+	require.Equal(t, 12, *done.Rows)
+	require.Equal(t, int64(3456), *done.SizeBytes)
+	require.Equal(t, 0.2, done.Quote.BrlToUsd)
+	require.NotNil(t, done.FinishedAt)
+
+	expired, err := db.DeleteExpiredAdExports(ctx, time.Now())
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	require.Equal(t, created.ID+".csv", expired[0].FileKey)
+
+	gone, err := db.GetAdExport(ctx, created.ID)
+	require.NoError(t, err)
+	require.Nil(t, gone)
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+const adExportColumns = `id, format, status, filters, COALESCE(file_key, ''), row_count, size_bytes,
+	brl_to_usd, quote_effective_at, error, created_at, claimed_at, finished_at, expires_at`
+
+func scanAdExport(row rowScanner) (domain.AdExport, error) {
+	var e domain.AdExport
+	var rate *float64
+	var effectiveAt *time.Time
+	err := row.Scan(&e.ID, &e.Format, &e.Status, &e.Filters, &e.FileKey, &e.Rows, &e.SizeBytes,
+		&rate, &effectiveAt, &e.Error, &e.CreatedAt, &e.ClaimedAt, &e.FinishedAt, &e.ExpiresAt)
+	if rate != nil && effectiveAt != nil {
+		e.Quote = &domain.Quote{BrlToUsd: *rate, EffectiveAt: *effectiveAt}
+	}
+	return e, err
+}
+
+// CreateAdExport queues an export job; filters is the listing input as JSON.
+func (d *DB) CreateAdExport(ctx context.Context, format string, filters []byte) (domain.AdExport, error) {
+	return scanAdExport(d.Pool.QueryRow(ctx, `
+		INSERT INTO ad_exports (format, filters) VALUES ($1, $2)
+		RETURNING `+adExportColumns, format, filters))
+}
+
+func (d *DB) GetAdExport(ctx context.Context, id string) (*domain.AdExport, error) {
+	e, err := scanAdExport(d.Pool.QueryRow(ctx, `SELECT `+adExportColumns+` FROM ad_exports WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// ClaimAdExport marks the oldest PENDING job as RUNNING and returns it, or
+// nil when the queue is empty. Jobs left RUNNING since before staleBefore are
+// claimed again, like images in ClaimPendingImages.
+func (d *DB) ClaimAdExport(ctx context.Context, staleBefore time.Time) (*domain.AdExport, error) {
+	e, err := scanAdExport(d.Pool.QueryRow(ctx, `
+		UPDATE ad_exports SET status = 'RUNNING', claimed_at = now()
+		WHERE id = (
+			SELECT id FROM ad_exports
+			WHERE status = 'PENDING' OR (status = 'RUNNING' AND claimed_at < $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+adExportColumns, staleBefore))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CompleteAdExport records the stored file of a claimed job. It returns
+// false when the job was reclaimed in the meantime, in which case the caller
+// owns the file.
+func (d *DB) CompleteAdExport(ctx context.Context, id string, claimedAt time.Time, fileKey string, rows int, size int64, quote *domain.Quote, expiresAt time.Time) (bool, error) {
+	var rate *float64
+	var effectiveAt *time.Time
+	if quote != nil {
+		rate, effectiveAt = &quote.BrlToUsd, &quote.EffectiveAt
+	}
+	tag, err := d.Pool.Exec(ctx, `
+		UPDATE ad_exports
+		SET status = 'DONE', file_key = $3, row_count = $4, size_bytes = $5,
+		    brl_to_usd = $6, quote_effective_at = $7, error = NULL,
+		    finished_at = now(), expires_at = $8
+		WHERE id = $1 AND status = 'RUNNING' AND claimed_at = $2
+	`, id, claimedAt, fileKey, rows, size, rate, effectiveAt, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// FailAdExport marks a claimed job as FAILED with the reason.
+func (d *DB) FailAdExport(ctx context.Context, id string, claimedAt time.Time, reason string, expiresAt time.Time) (bool, error) {
+	tag, err := d.Pool.Exec(ctx, `
+		UPDATE ad_exports
+		SET status = 'FAILED', error = $3, finished_at = now(), expires_at = $4
+		WHERE id = $1 AND status = 'RUNNING' AND claimed_at = $2
+	`, id, claimedAt, reason, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// DeleteExpiredAdExports removes finished jobs whose expires_at has passed
+// and returns them so their files can be removed.
+func (d *DB) DeleteExpiredAdExports(ctx context.Context, now time.Time) ([]domain.AdExport, error) {
+	rows, err := d.Pool.Query(ctx, `
+		DELETE FROM ad_exports WHERE expires_at < $1
+		RETURNING `+adExportColumns, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.AdExport
+	for rows.Next() {
+		e, err := scanAdExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,360 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// querier is implemented by both *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func loadAdImages(ctx context.Context, q querier, ads []*domain.Ad) error {
+	if len(ads) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(ads))
+	byID := make(map[string]*domain.Ad, len(ads))
+	for i, a := range ads {
+		ids[i] = a.ID
+		byID[a.ID] = a
+		a.Images = []domain.AdImage{}
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT `+adImageColumns+`
+		FROM ad_images
+		WHERE ad_id = ANY($1)
+		ORDER BY ad_id, position
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		img, err := scanAdImage(rows)
+		if err != nil {
+			return err
+		}
+		if a, ok := byID[img.AdID]; ok {
+			a.Images = append(a.Images, img)
+		}
+	}
+	return rows.Err()
+}
+
+const adImageColumns = `id, ad_id, path, status, variants, position, is_cover, created_at, legacy`
+
+func scanAdImage(row rowScanner) (domain.AdImage, error) {
+	var img domain.AdImage
+	err := row.Scan(&img.ID, &img.AdID, &img.Path, &img.Status, &img.Variants, &img.Position, &img.IsCover, &img.CreatedAt, &img.Legacy)
+	return img, err
+}
+
+// insertAdImages stores freshly uploaded images as PENDING; the image worker
+// renders their variants later.
+func insertAdImages(ctx context.Context, q querier, adID string, firstPosition int, coverFirst bool, paths []string) ([]domain.AdImage, error) {
+	out := make([]domain.AdImage, 0, len(paths))
+	for i, p := range paths {
+		img, err := scanAdImage(q.QueryRow(ctx, `
+			INSERT INTO ad_images (ad_id, path, status, position, is_cover)
+			VALUES ($1, $2, 'PENDING', $3, $4)
+			RETURNING `+adImageColumns,
+			adID, p, firstPosition+i, coverFirst && i == 0))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, img)
+	}
+	return out, nil
+}
+
+// lockLiveAd takes a row lock on a live ad so image changes are serialized.
+func lockLiveAd(ctx context.Context, tx pgx.Tx, adID string) (bool, error) {
+	var one int
+	err := tx.QueryRow(ctx, `
+		SELECT 1 FROM ads WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, adID).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// touchAdImages renumbers positions, mirrors the cover into ads.image_path
+// (once its variants are ready), bumps updated_at, records ad.updated and
+// returns the ad with its images.
+func touchAdImages(ctx context.Context, tx pgx.Tx, adID string) (domain.Ad, error) {
+	if _, err := tx.Exec(ctx, `
+		UPDATE ad_images i SET position = r.pos
+		FROM (
+			SELECT id, row_number() OVER (ORDER BY position, created_at) - 1 AS pos
+			FROM ad_images WHERE ad_id = $1
+		) r
+		WHERE i.id = r.id AND i.position <> r.pos
+	`, adID); err != nil {
+		return domain.Ad{}, err
+	}
+
+	row := tx.QueryRow(ctx, `
+		UPDATE ads SET
+			image_path = (SELECT path FROM ad_images WHERE ad_id = $1 AND is_cover AND status = 'READY'),
+			updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')
+		WHERE id = $1
+		RETURNING `+adColumns, adID)
+
+	a, err := scanAd(row)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if err := appendEvent(ctx, tx, domain.EventAdUpdated, a); err != nil {
+		return domain.Ad{}, err
+	}
+	if err := loadAdImages(ctx, tx, []*domain.Ad{&a}); err != nil {
+		return domain.Ad{}, err
+	}
+	return a, nil
+}
+
+// withAdImagesTx runs fn inside a transaction holding a lock on the ad. It
+// returns nil when the ad is missing or archived, or when fn reports false.
+func (d *DB) withAdImagesTx(ctx context.Context, adID string, fn func(tx pgx.Tx) (bool, error)) (*domain.Ad, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	ok, err := lockLiveAd(ctx, tx, adID)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if ok, err = fn(tx); err != nil || !ok {
+		return nil, err
+	}
+
+	a, err := touchAdImages(ctx, tx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// AddAdImages appends images after the existing ones. If the ad has no cover
+// yet, the first new image becomes the cover.
+func (d *DB) AddAdImages(ctx context.Context, adID string, paths []string) (*domain.Ad, error) {
+	return d.withAdImagesTx(ctx, adID, func(tx pgx.Tx) (bool, error) {
+		var next int
+		var hasCover bool
+		if err := tx.QueryRow(ctx, `
+			SELECT COALESCE(MAX(position) + 1, 0), COALESCE(bool_or(is_cover), false)
+			FROM ad_images WHERE ad_id = $1
+		`, adID).Scan(&next, &hasCover); err != nil {
+			return false, err
+		}
+		_, err := insertAdImages(ctx, tx, adID, next, !hasCover, paths)
+		return err == nil, err
+	})
+}
+
+// DeleteAdImage removes one image. When it was the cover, the next image in
+// order takes its place.
+func (d *DB) DeleteAdImage(ctx context.Context, adID, imageID string) (*domain.Ad, error) {
+	return d.withAdImagesTx(ctx, adID, func(tx pgx.Tx) (bool, error) {
+		var wasCover bool
+		err := tx.QueryRow(ctx, `
+			DELETE FROM ad_images WHERE id = $2 AND ad_id = $1 RETURNING is_cover
+		`, adID, imageID).Scan(&wasCover)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if wasCover {
+			if _, err := tx.Exec(ctx, `
+				UPDATE ad_images SET is_cover = true
+				WHERE id = (SELECT id FROM ad_images WHERE ad_id = $1 ORDER BY position LIMIT 1)
+			`, adID); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// ReorderAdImages sets the image order to imageIDs, which must list every
+// image of the ad exactly once.
+func (d *DB) ReorderAdImages(ctx context.Context, adID string, imageIDs []string) (*domain.Ad, error) {
+	return d.withAdImagesTx(ctx, adID, func(tx pgx.Tx) (bool, error) {
+		var count int
+		if err := tx.QueryRow(ctx, `SELECT count(*) FROM ad_images WHERE ad_id = $1`, adID).Scan(&count); err != nil {
+			return false, err
+		}
+		if count != len(imageIDs) {
+			return false, nil
+		}
+
+		tag, err := tx.Exec(ctx, `
+			UPDATE ad_images i SET position = x.pos - 1
+			FROM unnest($2::uuid[]) WITH ORDINALITY AS x(id, pos)
+			WHERE i.id = x.id AND i.ad_id = $1
+		`, adID, imageIDs)
+		if err != nil {
+			return false, err
+		}
+		return int(tag.RowsAffected()) == len(imageIDs), nil
+	})
+}
+
+// SetAdCover makes imageID the cover of the ad.
+func (d *DB) SetAdCover(ctx context.Context, adID, imageID string) (*domain.Ad, error) {
+	return d.withAdImagesTx(ctx, adID, func(tx pgx.Tx) (bool, error) {
+		var exists bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM ad_images WHERE id = $2 AND ad_id = $1)
+		`, adID, imageID).Scan(&exists); err != nil || !exists {
+			return false, err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE ad_images SET is_cover = false WHERE ad_id = $1 AND is_cover AND id <> $2
+		`, adID, imageID); err != nil {
+			return false, err
+		}
+		_, err := tx.Exec(ctx, `UPDATE ad_images SET is_cover = true WHERE id = $1`, imageID)
+		return err == nil, err
+	})
+}
+
+// ClaimPendingImages marks up to limit PENDING images as PROCESSING and
+// returns them. Images left PROCESSING since before staleBefore (a worker
+// that died mid-way) are claimed again. SKIP LOCKED lets several API
+// instances share the queue.
+func (d *DB) ClaimPendingImages(ctx context.Context, limit int, staleBefore time.Time) ([]domain.AdImage, error) {
+	rows, err := d.Pool.Query(ctx, `
+		UPDATE ad_images SET status = 'PROCESSING', claimed_at = now()
+		WHERE id IN (
+			SELECT id FROM ad_images
+			WHERE status = 'PENDING' OR (status = 'PROCESSING' AND claimed_at < $2)
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+adImageColumns, limit, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.AdImage
+	for rows.Next() {
+		img, err := scanAdImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, img)
+	}
+	return out, rows.Err()
+}
+
+// CompleteAdImage stores the rendered variants of a claimed image; path is
+// the variant served as image_url. It returns false when the image was
+// removed (or reclaimed) in the meantime, in which case the caller owns the
+// rendered files.
+func (d *DB) CompleteAdImage(ctx context.Context, imageID, path string, variants map[string]string) (bool, error) {
+	return d.finishAdImage(ctx, imageID, `
+		UPDATE ad_images SET status = 'READY', path = $2, variants = $3, error = NULL, claimed_at = NULL
+		WHERE id = $1 AND status = 'PROCESSING'
+	`, path, variants)
+}
+
+// FailAdImage marks a claimed image as FAILED with the reason.
+func (d *DB) FailAdImage(ctx context.Context, imageID, reason string) (bool, error) {
+	return d.finishAdImage(ctx, imageID, `
+		UPDATE ad_images SET status = 'FAILED', error = $2, claimed_at = NULL
+		WHERE id = $1 AND status = 'PROCESSING'
+	`, reason)
+}
+
+// finishAdImage runs update (with imageID as $1) while holding the ad lock,
+// taken first like every other image change, then refreshes the ad.
+func (d *DB) finishAdImage(ctx context.Context, imageID, update string, args ...any) (bool, error) {
+	var adID string
+	err := d.Pool.QueryRow(ctx, `SELECT ad_id FROM ad_images WHERE id = $1`, imageID).Scan(&adID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM ads WHERE id = $1 FOR UPDATE`, adID); err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(ctx, update, append([]any{imageID}, args...)...)
+	if err != nil || tag.RowsAffected() == 0 {
+		return false, err
+	}
+	if _, err := touchAdImages(ctx, tx, adID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// ListImageRefs returns every object key the database points at: staged
+// uploads of images still waiting for the worker (legacy originals are in
+// the images storage), the variants of ready images and the legacy
+// ads.image_path mirror. FAILED images reference nothing, their upload is
+// discarded.
+func (d *DB) ListImageRefs(ctx context.Context) ([]domain.ImageRef, error) {
+	rows, err := d.Pool.Query(ctx, `
+		SELECT ad_id, id::text, path, NOT legacy, created_at
+		FROM ad_images WHERE status IN ('PENDING', 'PROCESSING')
+		UNION ALL
+		SELECT ad_id, id::text, path, false, created_at
+		FROM ad_images WHERE status = 'READY'
+		UNION ALL
+		SELECT i.ad_id, i.id::text, v.value, false, i.created_at
+		FROM ad_images i, jsonb_each_text(i.variants) v
+		WHERE i.status = 'READY'
+		UNION ALL
+		SELECT id, '', image_path, false, updated_at
+		FROM ads WHERE image_path IS NOT NULL AND image_path <> ''
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ImageRef
+	for rows.Next() {
+		var ref domain.ImageRef
+		if err := rows.Scan(&ref.AdID, &ref.ImageID, &ref.Key, &ref.Staged, &ref.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, ref)
+	}
+	return out, rows.Err()
+}
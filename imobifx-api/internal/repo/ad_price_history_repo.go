@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// ListAdPriceHistory returns the prices an ad has had, oldest first, each
+// with the latest quote effective at or before the change.
+func (d *DB) ListAdPriceHistory(ctx context.Context, adID string) ([]domain.AdPrice, error) {
+	rows, err := d.Pool.Query(ctx, `
+		SELECT h.price_brl, h.changed_at,
+		       q.id, q.brl_to_usd, q.effective_at, q.created_at
+		FROM ad_price_history h
+		LEFT JOIN LATERAL (
+			SELECT id, brl_to_usd, effective_at, created_at
+			FROM quotes
+			WHERE effective_at <= h.changed_at
+			ORDER BY effective_at DESC
+			LIMIT 1
+		) q ON true
+		WHERE h.ad_id = $1
+		ORDER BY h.changed_at, h.id
+	`, adID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.AdPrice{}
+	for rows.Next() {
+		var p domain.AdPrice
+		var quoteID *string
+		var rate *float64
+		var effectiveAt, createdAt *time.Time
+		if err := rows.Scan(&p.PriceBRL, &p.ChangedAt, &quoteID, &rate, &effectiveAt, &createdAt); err != nil {
+			return nil, err
+		}
+		if quoteID != nil {
+			p.Quote = &domain.Quote{ID: *quoteID, BrlToUsd: *rate, EffectiveAt: *effectiveAt, CreatedAt: *createdAt}
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
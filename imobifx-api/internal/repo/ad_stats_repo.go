@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// adStatsLevels maps GROUPING(state, city_key, neighborhood_key) to the level
+// of the grouping set that produced the row.
+var adStatsLevels = map[int]string{
+	0b111: domain.AdStatsByType,
+	0b011: domain.AdStatsByState,
+	0b001: domain.AdStatsByCity,
+	0b000: domain.AdStatsByNeighborhood,
+}
+
+// AdPriceStats summarizes the prices of the ads matching f per type, and per
+// type within each state, city and neighborhood. Cities and neighborhoods are
+// grouped ignoring case and accents, like the filters match them. Rows come
+// level by level, the largest groups first.
+func (d *DB) AdPriceStats(ctx context.Context, f AdsFilter) ([]domain.AdPriceStats, error) {
+	where, args := buildAdsWhere(f)
+
+	fractions := make([]float64, len(domain.AdStatsPercentiles))
+	for i, p := range domain.AdStatsPercentiles {
+		fractions[i] = float64(p) / 100
+	}
+	args = append(args, fractions)
+
+	rows, err := d.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT GROUPING(state, city_key, neighborhood_key), type, coalesce(state, ''),
+		       mode() WITHIN GROUP (ORDER BY city), mode() WITHIN GROUP (ORDER BY neighborhood),
+		       count(*), avg(price), percentile_cont(0.5) WITHIN GROUP (ORDER BY price),
+		       min(price), max(price),
+		       percentile_cont($%d::float8[]) WITHIN GROUP (ORDER BY price)
+		FROM (
+			SELECT type, state, city, neighborhood, price_brl::float8 AS price,
+			       lower(immutable_unaccent(city)) AS city_key,
+			       lower(immutable_unaccent(neighborhood)) AS neighborhood_key
+			FROM ads
+			%s
+		) a
+		GROUP BY GROUPING SETS ((type), (type, state), (type, state, city_key), (type, state, city_key, neighborhood_key))
+		ORDER BY 1 DESC, count(*) DESC, type, state, city_key, neighborhood_key
+	`, len(args), where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.AdPriceStats{}
+	for rows.Next() {
+		var s domain.AdPriceStats
+		var grouping int
+		if err := rows.Scan(&grouping, &s.Type, &s.State, &s.City, &s.Neighborhood,
+			&s.Count, &s.Avg, &s.Median, &s.Min, &s.Max, &s.Percentiles); err != nil {
+			return nil, err
+		}
+		s.Level = adStatsLevels[grouping]
+		switch s.Level {
+		case domain.AdStatsByType, domain.AdStatsByState:
+			s.City, s.Neighborhood = "", ""
+		case domain.AdStatsByCity:
+			s.Neighborhood = ""
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
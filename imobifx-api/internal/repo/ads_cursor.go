@@ -0,0 +1,275 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// ErrInvalidCursor is returned for cursors that cannot be decoded or do not
+// fit the requested sort.
+var ErrInvalidCursor = errors.New("repo: invalid cursor")
+
+// AdsCursor points just past the last ad of a page: the sort it was taken
+// from, the text value of each sort key (nil for NULL) and the ad id. A
+// distance cursor also records the point distances were measured from, as
+// its keys mean nothing from another one.
+type AdsCursor struct {
+	Sort string           `json:"s"`
+	Keys []*string        `json:"k"`
+	ID   string           `json:"id"`
+	Near *domain.GeoPoint `json:"n,omitempty"`
+}
+
+// Fits reports whether the cursor can continue a listing with filter f: same
+// sort and, for distance, same reference point.
+func (c AdsCursor) Fits(f AdsFilter) bool {
+	if c.Sort != f.Sort {
+		return false
+	}
+	if f.Sort == domain.AdSortDistance {
+		return c.Near != nil && f.Near != nil && *c.Near == *f.Near
+	}
+	return true
+}
+
+// Encode renders the cursor as the opaque token handed to clients.
+func (c AdsCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeAdsCursor(s string) (*AdsCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c AdsCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort == "" || len(c.Keys) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+type sortKey struct {
+	expr string
+	// typ is the SQL type cursor values are cast back to.
+	typ string
+}
+
+// adsSort describes a listing order. All keys share one direction and are
+// followed by id, so rows with equal keys keep their place between pages and
+// a row comparison can seek past a cursor.
+type adsSort struct {
+	keys []sortKey
+	desc bool
+	// nullable means the single key may be NULL; those rows sort last.
+	nullable bool
+}
+
+var (
+	createdAtKey  = sortKey{"created_at", "timestamptz"}
+	priceKey      = sortKey{"price_brl", "numeric"}
+	pricePerM2Key = sortKey{"price_per_m2", "numeric"}
+)
+
+var adsSorts = map[string]adsSort{
+	domain.AdSortNewest:         {keys: []sortKey{createdAtKey}, desc: true},
+	domain.AdSortOldest:         {keys: []sortKey{createdAtKey}},
+	domain.AdSortPriceAsc:       {keys: []sortKey{priceKey}},
+	domain.AdSortPriceDesc:      {keys: []sortKey{priceKey}, desc: true},
+	domain.AdSortPriceUSDAsc:    {keys: []sortKey{priceKey}},
+	domain.AdSortPriceUSDDesc:   {keys: []sortKey{priceKey}, desc: true},
+	domain.AdSortPricePerM2Asc:  {keys: []sortKey{pricePerM2Key}, nullable: true},
+	domain.AdSortPricePerM2Desc: {keys: []sortKey{pricePerM2Key}, desc: true, nullable: true},
+}
+
+// adsSortFor resolves f.Sort, falling back to newest first. Relevance ranks
+// against f.Query and distance is measured from f.Near; both are appended to
+// args.
+func adsSortFor(f AdsFilter, args []interface{}) (adsSort, []interface{}) {
+	if f.Sort == domain.AdSortRelevance && f.Query != nil {
+		args = append(args, *f.Query)
+		rank := sortKey{fmt.Sprintf("ts_rank_cd(search_tsv, websearch_to_tsquery('pt_unaccent', $%d))", len(args)), "real"}
+		return adsSort{keys: []sortKey{rank, createdAtKey}, desc: true}, args
+	}
+	if f.Sort == domain.AdSortDistance && f.Near != nil {
+		args = append(args, f.Near.Lat, f.Near.Lng)
+		dist := sortKey{fmt.Sprintf("haversine_km(latitude, longitude, $%d::float8, $%d::float8)", len(args)-1, len(args)), "float8"}
+		return adsSort{keys: []sortKey{dist}, nullable: true}, args
+	}
+	if s, ok := adsSorts[f.Sort]; ok {
+		return s, args
+	}
+	return adsSorts[domain.AdSortNewest], args
+}
+
+func (s adsSort) dir() string {
+	if s.desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+func (s adsSort) orderBy() string {
+	parts := make([]string, 0, len(s.keys)+1)
+	for _, k := range s.keys {
+		p := k.expr + s.dir()
+		if s.nullable {
+			p += " NULLS LAST"
+		}
+		parts = append(parts, p)
+	}
+	return strings.Join(append(parts, "id"+s.dir()), ", ")
+}
+
+// keyColumns selects the sort keys as text, for building the next cursor.
+func (s adsSort) keyColumns() string {
+	cols := make([]string, len(s.keys))
+	for i, k := range s.keys {
+		cols[i] = "(" + k.expr + ")::text"
+	}
+	return strings.Join(cols, ", ")
+}
+
+// after is the predicate matching the rows that follow c in this order.
+func (s adsSort) after(c *AdsCursor, args []interface{}) (string, []interface{}, error) {
+	if len(c.Keys) != len(s.keys) {
+		return "", nil, ErrInvalidCursor
+	}
+	op := ">"
+	if s.desc {
+		op = "<"
+	}
+
+	args = append(args, c.ID)
+	idArg := fmt.Sprintf("$%d::uuid", len(args))
+
+	if s.nullable && c.Keys[0] == nil {
+		return fmt.Sprintf("(%s IS NULL AND id %s %s)", s.keys[0].expr, op, idArg), args, nil
+	}
+
+	cols := make([]string, 0, len(s.keys)+1)
+	vals := make([]string, 0, len(s.keys)+1)
+	for i, k := range s.keys {
+		if c.Keys[i] == nil {
+			return "", nil, ErrInvalidCursor
+		}
+		args = append(args, *c.Keys[i])
+		cols = append(cols, k.expr)
+		vals = append(vals, fmt.Sprintf("$%d::%s", len(args), k.typ))
+	}
+	expr := fmt.Sprintf("(%s, id) %s (%s, %s)", strings.Join(cols, ", "), op, strings.Join(vals, ", "), idArg)
+	if s.nullable {
+		expr = "(" + expr + " OR " + s.keys[0].expr + " IS NULL)"
+	}
+	return expr, args, nil
+}
+
+// keyedRow scans the ad columns followed by the sort keys.
+type keyedRow struct {
+	rowScanner
+	keys []*string
+}
+
+func (r keyedRow) Scan(dest ...any) error {
+	for i := range r.keys {
+		dest = append(dest, &r.keys[i])
+	}
+	return r.rowScanner.Scan(dest...)
+}
+
+// ListAdsAfter returns up to limit ads following after (nil for the first
+// page) in f.Sort order, seeking on the sort keys instead of skipping rows,
+// and the cursor of the next page, nil on the last one.
+func (d *DB) ListAdsAfter(ctx context.Context, f AdsFilter, after *AdsCursor, limit int) ([]domain.Ad, *AdsCursor, error) {
+	where, args := buildAdsWhere(f)
+	sort, args := adsSortFor(f, args)
+	if after != nil {
+		pred, next, err := sort.after(after, args)
+		if err != nil {
+			return nil, nil, err
+		}
+		where, args = where+" AND "+pred, next
+	}
+
+	args = append(args, limit+1)
+	listSQL := fmt.Sprintf(`
+		SELECT %s, %s
+		FROM ads
+		%s
+		ORDER BY %s
+		LIMIT $%d
+	`, adColumns, sort.keyColumns(), where, sort.orderBy(), len(args))
+
+	rows, err := d.Pool.Query(ctx, listSQL, args...)
+	if err != nil {
+		return nil, nil, cursorErr(after, err)
+	}
+	defer rows.Close()
+
+	out := make([]domain.Ad, 0, limit+1)
+	var keys [][]*string
+	for rows.Next() {
+		row := keyedRow{rowScanner: rows, keys: make([]*string, len(sort.keys))}
+		a, err := scanAd(row)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, a)
+		keys = append(keys, row.keys)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, cursorErr(after, err)
+	}
+
+	var next *AdsCursor
+	if len(out) > limit {
+		out = out[:limit]
+		last := out[limit-1]
+		next = &AdsCursor{Sort: f.Sort, Keys: keys[limit-1], ID: last.ID}
+		if f.Sort == domain.AdSortDistance {
+			next.Near = f.Near
+		}
+	}
+
+	ptrs := make([]*domain.Ad, len(out))
+	for i := range out {
+		ptrs[i] = &out[i]
+	}
+	if err := loadAdImages(ctx, d.Pool, ptrs); err != nil {
+		return nil, nil, err
+	}
+	return out, next, nil
+}
+
+// cursorErr reports data exceptions (class 22) caused by a tampered cursor,
+// whose values its key types cannot parse, as ErrInvalidCursor.
+func cursorErr(after *AdsCursor, err error) error {
+	var pgErr *pgconn.PgError
+	if after != nil && errors.As(err, &pgErr) && strings.HasPrefix(pgErr.Code, "22") {
+		return ErrInvalidCursor
+	}
+	return err
+}
+
+func (d *DB) CountAds(ctx context.Context, f AdsFilter) (int, error) {
+	where, args := buildAdsWhere(f)
+	var total int
+	err := d.Pool.QueryRow(ctx, "SELECT count(*) FROM ads "+where, args...).Scan(&total)
+	return total, err
+}
@@ -5,6 +5,7 @@ package repo_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -22,7 +23,7 @@ func TestAds_CreateAndList_WithFilters(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	_, _ = db.Pool.Exec(context.Background(), "TRUNCATE TABLE ads RESTART IDENTITY")
+	_, _ = db.Pool.Exec(context.Background(), "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
 	_, _ = db.Pool.Exec(context.Background(), "TRUNCATE TABLE quotes RESTART IDENTITY")
 
 	_, err = db.CreateAd(context.Background(), domain.Ad{
@@ -55,3 +56,1068 @@ func TestAds_CreateAndList_WithFilters(t *testing.T) {
 	require.Len(t, items, 1)
 	require.Equal(t, "SALE", items[0].Type)
 }
+
+func TestAds_GetAd(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, _ = db.Pool.Exec(context.Background(), "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	created, err := db.CreateAd(context.Background(), domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     250000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+	})
+	require.NoError(t, err)
+
+	got, err := db.GetAd(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, created.ID, got.ID)
+
+	missing, err := db.GetAd(context.Background(), "00000000-0000-0000-0000-000000000000")
+	require.NoError(t, err)
+	require.Nil(t, missing)
+}
+
+func TestAds_UpdateAd_OptimisticLock(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, _ = db.Pool.Exec(context.Background(), "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	created, err := db.CreateAd(context.Background(), domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     250000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+	})
+	require.NoError(t, err)
+
+	next := created
+	next.PriceBRL = 240000
+	updated, err := db.UpdateAd(context.Background(), next, created.UpdatedAt)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	require.Equal(t, 240000.0, updated.PriceBRL)
+	require.True(t, updated.UpdatedAt.After(created.UpdatedAt))
+
+	stale, err := db.UpdateAd(context.Background(), next, created.UpdatedAt)
+	require.NoError(t, err)
+	require.Nil(t, stale)
+}
+
+func TestAds_SoftDelete_Restore_Purge(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	created, err := db.CreateAd(ctx, domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     250000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+	})
+	require.NoError(t, err)
+
+	ok, err := db.SoftDeleteAd(ctx, created.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, total, err := db.ListAds(ctx, repo.AdsFilter{}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, total)
+
+	_, total, err = db.ListAds(ctx, repo.AdsFilter{Archived: true}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	restored, err := db.RestoreAd(ctx, created.ID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, restored)
+	require.Nil(t, restored.DeletedAt)
+
+	ok, err = db.SoftDeleteAd(ctx, created.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	purged, err := db.PurgeDeletedAds(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, purged, 1)
+
+	got, err := db.GetAd(ctx, created.ID)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestAds_UpdateAdStatus_AndFilter(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	created, err := db.CreateAd(ctx, domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     250000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+	})
+	require.NoError(t, err)
+	require.Equal(t, domain.AdStatusActive, created.Status)
+
+	sold, err := db.UpdateAdStatus(ctx, created.ID, domain.AdStatusActive, domain.AdStatusSold)
+	require.NoError(t, err)
+	require.NotNil(t, sold)
+	require.Equal(t, domain.AdStatusSold, sold.Status)
+
+	again, err := db.UpdateAdStatus(ctx, created.ID, domain.AdStatusActive, domain.AdStatusPaused)
+	require.NoError(t, err)
+	require.Nil(t, again)
+
+	active := domain.AdStatusActive
+	_, total, err := db.ListAds(ctx, repo.AdsFilter{Status: &active}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, total)
+}
+
+func TestAds_Schedule_ActivateAndExpire(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	now := time.Now().UTC()
+	publishAt := now.Add(time.Hour)
+	expiresAt := now.Add(2 * time.Hour)
+	scheduled, err := db.CreateAd(ctx, domain.Ad{
+		Type:         "SALE",
+		Status:       domain.AdStatusDraft,
+		PriceBRL:     250000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+		PublishAt:    &publishAt,
+		ExpiresAt:    &expiresAt,
+	})
+	require.NoError(t, err)
+
+	active := domain.AdStatusActive
+	_, total, err := db.ListAds(ctx, repo.AdsFilter{Status: &active, OnlyLive: true}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, total)
+
+	n, err := db.ActivateScheduledAds(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), n)
+
+	n, err = db.ActivateScheduledAds(ctx, publishAt.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	n, err = db.ExpireAds(ctx, expiresAt.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	got, err := db.GetAd(ctx, scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.AdStatusExpired, got.Status)
+}
+
+func TestAds_Images_OrderAndCover(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	created, err := db.CreateAd(ctx, domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     250000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+		Images:       []domain.AdImage{{Path: "a.jpg"}, {Path: "b.jpg"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, created.Images, 2)
+	require.True(t, created.Images[0].IsCover)
+	require.Equal(t, domain.AdImagePending, created.Images[0].Status)
+	require.Nil(t, created.ImagePath)
+
+	added, err := db.AddAdImages(ctx, created.ID, []string{"c.jpg"})
+	require.NoError(t, err)
+	require.Len(t, added.Images, 3)
+	require.Equal(t, 2, added.Images[2].Position)
+
+	claimed, err := db.ClaimPendingImages(ctx, 10, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	for _, img := range claimed {
+		ok, err := db.CompleteAdImage(ctx, img.ID, "l_"+img.Path, map[string]string{domain.ImageSizeLarge: "l_" + img.Path})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	again, err := db.ClaimPendingImages(ctx, 10, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	ready, err := db.GetAd(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "l_a.jpg", *ready.ImagePath)
+	require.Equal(t, domain.AdImageReady, ready.Images[0].Status)
+
+	a, b, c := added.Images[0].ID, added.Images[1].ID, added.Images[2].ID
+
+	_, err = db.ReorderAdImages(ctx, created.ID, []string{c, a})
+	require.NoError(t, err)
+
+	reordered, err := db.ReorderAdImages(ctx, created.ID, []string{c, a, b})
+	require.NoError(t, err)
+	require.NotNil(t, reordered)
+	require.Equal(t, c, reordered.Images[0].ID)
+
+	covered, err := db.SetAdCover(ctx, created.ID, b)
+	require.NoError(t, err)
+	require.Equal(t, "l_b.jpg", *covered.ImagePath)
+
+	removed, err := db.DeleteAdImage(ctx, created.ID, b)
+	require.NoError(t, err)
+	require.Len(t, removed.Images, 2)
+	require.Equal(t, c, removed.Images[0].ID)
+	require.True(t, removed.Images[0].IsCover)
+	require.Equal(t, "l_c.jpg", *removed.ImagePath)
+}
+
+func TestAds_ListImageRefs(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	_, err = db.CreateAd(ctx, domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     250000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+		Images:       []domain.AdImage{{Path: "up-a.jpg"}, {Path: "up-b.jpg"}},
+	})
+	require.NoError(t, err)
+
+	claimed, err := db.ClaimPendingImages(ctx, 1, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	_, err = db.CompleteAdImage(ctx, claimed[0].ID, "v_large.jpg", map[string]string{
+		domain.ImageSizeThumb: "v_thumb.jpg",
+		domain.ImageSizeLarge: "v_large.jpg",
+	})
+	require.NoError(t, err)
+
+	refs, err := db.ListImageRefs(ctx)
+	require.NoError(t, err)
+
+	staged := map[string]bool{}
+	for _, ref := range refs {
+		staged[ref.Key] = ref.Staged
+	}
+	pending := "up-a.jpg"
+	if claimed[0].Path == pending {
+		pending = "up-b.jpg"
+	}
+	require.Equal(t, map[string]bool{pending: true, "v_large.jpg": false, "v_thumb.jpg": false}, staged)
+
+	// A legacy original waiting for the worker is in the images storage.
+	_, err = db.Pool.Exec(ctx, `UPDATE ad_images SET legacy = true WHERE path = $1`, pending)
+	require.NoError(t, err)
+	refs, err = db.ListImageRefs(ctx)
+	require.NoError(t, err)
+	for _, ref := range refs {
+		if ref.Key == pending {
+			require.False(t, ref.Staged)
+		}
+	}
+}
+
+func TestAds_AttributesAndRangeFilters(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	base := domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     300000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+	}
+
+	two, three := 2, 3
+	small, large := 55.0, 110.5
+	fee := 480.0
+
+	a := base
+	a.Bedrooms, a.AreaM2, a.CondoFeeBRL = &two, &small, &fee
+	created, err := db.CreateAd(ctx, a)
+	require.NoError(t, err)
+	require.Equal(t, 2, *created.Bedrooms)
+	require.Equal(t, 55.0, *created.AreaM2)
+	require.Equal(t, 480.0, *created.CondoFeeBRL)
+	require.Nil(t, created.Bathrooms)
+
+	b := base
+	b.Bedrooms, b.AreaM2 = &three, &large
+	_, err = db.CreateAd(ctx, b)
+	require.NoError(t, err)
+
+	_, err = db.CreateAd(ctx, base)
+	require.NoError(t, err)
+
+	items, total, err := db.ListAds(ctx, repo.AdsFilter{MinBedrooms: &three}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, 110.5, *items[0].AreaM2)
+
+	limit := 100.0
+	_, total, err = db.ListAds(ctx, repo.AdsFilter{MaxArea: &limit}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	_, total, err = db.ListAds(ctx, repo.AdsFilter{MinBedrooms: &two, MaxBedrooms: &three}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+
+	created.AreaM2 = &large
+	updated, err := db.UpdateAd(ctx, created, created.UpdatedAt)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	require.Equal(t, 110.5, *updated.AreaM2)
+	require.Equal(t, 2, *updated.Bedrooms)
+}
+
+func TestAds_FullTextSearch(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	create := func(typ, title, description string) domain.Ad {
+		ad, err := db.CreateAd(ctx, domain.Ad{
+			Type:         typ,
+			PriceBRL:     1000,
+			Title:        title,
+			Description:  description,
+			CEP:          "58000-000",
+			Street:       "Rua A",
+			Neighborhood: "Manaira",
+			City:         "Joao Pessoa",
+			State:        "PB",
+		})
+		require.NoError(t, err)
+		return ad
+	}
+
+	inTitle := create("SALE", "Apartamento com varanda gourmet", "Perto da praia.")
+	inDescription := create("SALE", "Apartamento 3 quartos", "Sala ampla e varandas gourmet com churrasqueira.")
+	create("RENT", "Casa com varanda gourmet", "")
+	create("SALE", "Cobertura", "Condomínio com piscina.")
+
+	q := "Varanda Gourmet"
+	typ := "SALE"
+	items, total, err := db.ListAds(ctx, repo.AdsFilter{Query: &q, Type: &typ}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, inTitle.ID, items[0].ID, "title matches rank above description matches")
+	require.Equal(t, inDescription.ID, items[1].ID)
+
+	q = "condominio"
+	_, total, err = db.ListAds(ctx, repo.AdsFilter{Query: &q}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	q = "de com"
+	_, total, err = db.ListAds(ctx, repo.AdsFilter{Query: &q}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 4, total)
+}
+
+func TestAds_ListSort_DeterministicPages(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	// Equal prices force the id tie-breaker to decide the order.
+	prices := []float64{500, 300, 300, 300, 300, 100}
+	areas := []float64{50, 10, 0, 20, 0, 0}
+	for i, price := range prices {
+		ad := domain.Ad{
+			Type:         "SALE",
+			PriceBRL:     price,
+			CEP:          "58000-000",
+			Street:       "Rua A",
+			Neighborhood: "Centro",
+			City:         "Joao Pessoa",
+			State:        "PB",
+		}
+		if areas[i] > 0 {
+			ad.AreaM2 = &areas[i]
+		}
+		_, err := db.CreateAd(ctx, ad)
+		require.NoError(t, err)
+	}
+
+	var paged []domain.Ad
+	for page := 1; page <= 3; page++ {
+		items, _, err := db.ListAds(ctx, repo.AdsFilter{Sort: domain.AdSortPriceAsc}, page, 2)
+		require.NoError(t, err)
+		paged = append(paged, items...)
+	}
+	all, _, err := db.ListAds(ctx, repo.AdsFilter{Sort: domain.AdSortPriceAsc}, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, paged, len(prices))
+	for i := range all {
+		require.Equal(t, all[i].ID, paged[i].ID)
+		if i > 0 {
+			require.LessOrEqual(t, all[i-1].PriceBRL, all[i].PriceBRL)
+			if all[i-1].PriceBRL == all[i].PriceBRL {
+				require.Less(t, all[i-1].ID, all[i].ID)
+			}
+		}
+	}
+
+	// 300/20 = 15, 500/50 = 10, 300/10 = 30; ads without area go last.
+	items, _, err := db.ListAds(ctx, repo.AdsFilter{Sort: domain.AdSortPricePerM2Asc}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 50.0, *items[0].AreaM2)
+	require.Equal(t, 20.0, *items[1].AreaM2)
+	require.Equal(t, 10.0, *items[2].AreaM2)
+	require.Nil(t, items[3].AreaM2)
+
+	items, _, err = db.ListAds(ctx, repo.AdsFilter{Sort: domain.AdSortPriceDesc}, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, 500.0, items[0].PriceBRL)
+}
+
+func TestAds_ListAdsAfter_WalksEverySort(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	prices := []float64{500, 300, 300, 300, 300, 100, 700}
+	areas := []float64{50, 10, 0, 20, 0, 0, 35}
+	titles := []string{"varanda", "varanda gourmet", "casa", "varanda", "varanda gourmet varanda", "casa", "varanda"}
+	for i, price := range prices {
+		ad := domain.Ad{
+			Type:         "SALE",
+			PriceBRL:     price,
+			Title:        titles[i],
+			CEP:          "58000-000",
+			Street:       "Rua A",
+			Neighborhood: "Centro",
+			City:         "Joao Pessoa",
+			State:        "PB",
+		}
+		if areas[i] > 0 {
+			ad.AreaM2 = &areas[i]
+		}
+		_, err := db.CreateAd(ctx, ad)
+		require.NoError(t, err)
+	}
+
+	q := "varanda"
+	filters := []repo.AdsFilter{{Query: &q, Sort: domain.AdSortRelevance}}
+	for _, sort := range domain.AdSorts {
+		if sort != domain.AdSortRelevance {
+			filters = append(filters, repo.AdsFilter{Sort: sort})
+		}
+	}
+
+	for _, f := range filters {
+		want, total, err := db.ListAds(ctx, f, 1, 50)
+		require.NoError(t, err)
+
+		var got []domain.Ad
+		var after *repo.AdsCursor
+		for {
+			items, next, err := db.ListAdsAfter(ctx, f, after, 2)
+			require.NoError(t, err, f.Sort)
+			got = append(got, items...)
+			if next == nil {
+				break
+			}
+			after, err = repo.DecodeAdsCursor(next.Encode())
+			require.NoError(t, err)
+		}
+
+		require.Len(t, got, total, f.Sort)
+		for i := range want {
+			require.Equal(t, want[i].ID, got[i].ID, "%s #%d", f.Sort, i)
+		}
+
+		count, err := db.CountAds(ctx, f)
+		require.NoError(t, err)
+		require.Equal(t, total, count)
+	}
+}
+
+func TestAds_ListAdsAfter_StableUnderInserts(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	newAd := func() {
+		_, err := db.CreateAd(ctx, domain.Ad{
+			Type:         "SALE",
+			PriceBRL:     1000,
+			CEP:          "58000-000",
+			Street:       "Rua A",
+			Neighborhood: "Centro",
+			City:         "Joao Pessoa",
+			State:        "PB",
+		})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 4; i++ {
+		newAd()
+	}
+
+	f := repo.AdsFilter{Sort: domain.AdSortNewest}
+	first, next, err := db.ListAdsAfter(ctx, f, nil, 2)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+
+	// Newer ads land before the cursor and do not shift the next page.
+	newAd()
+	newAd()
+
+	second, next, err := db.ListAdsAfter(ctx, f, next, 2)
+	require.NoError(t, err)
+	require.Nil(t, next)
+
+	seen := map[string]bool{}
+	for _, a := range append(first, second...) {
+		require.False(t, seen[a.ID])
+		seen[a.ID] = true
+	}
+	require.Len(t, seen, 4)
+
+	bad := "not a timestamp"
+	_, _, err = db.ListAdsAfter(ctx, f, &repo.AdsCursor{Sort: f.Sort, Keys: []*string{&bad}, ID: first[0].ID}, 2)
+	require.ErrorIs(t, err, repo.ErrInvalidCursor)
+}
+
+func TestAds_LocationFilters(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	for _, loc := range []struct{ cep, neighborhood, city string }{
+		{"58038-000", "Manaíra", "João Pessoa"},
+		{"58038-142", "manaira", "Joao Pessoa"},
+		{"58039-000", "Tambaú", "JOÃO PESSOA"},
+		{"50000-000", "Boa Viagem", "Recife"},
+	} {
+		_, err := db.CreateAd(ctx, domain.Ad{
+			Type:         "SALE",
+			PriceBRL:     1000,
+			CEP:          loc.cep,
+			Street:       "Rua A",
+			Neighborhood: loc.neighborhood,
+			City:         loc.city,
+			State:        "PB",
+		})
+		require.NoError(t, err)
+	}
+
+	count := func(f repo.AdsFilter) int {
+		t.Helper()
+		_, total, err := db.ListAds(ctx, f, 1, 10)
+		require.NoError(t, err)
+		return total
+	}
+
+	for _, city := range []string{"Joao Pessoa", "joão pessoa", "JOAO PESSOA"} {
+		require.Equal(t, 3, count(repo.AdsFilter{City: &city}), city)
+	}
+	neighborhood := "MANAIRA"
+	require.Equal(t, 2, count(repo.AdsFilter{Neighborhood: &neighborhood}))
+
+	from, to := "58038-000", "58038-999"
+	require.Equal(t, 2, count(repo.AdsFilter{CEPFrom: &from, CEPTo: &to}))
+	from, to = "58000-000", "58999-999"
+	require.Equal(t, 3, count(repo.AdsFilter{CEPFrom: &from, CEPTo: &to}))
+}
+
+func TestAds_GeoFiltersAndDistanceSort(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	// Distances from the Ponta do Seixas reference point below.
+	points := map[string]*domain.GeoPoint{
+		"tambau":  {Lat: -7.1153, Lng: -34.8253}, // ~5.3 km
+		"cabo":    {Lat: -7.1420, Lng: -34.7980}, // ~1.1 km
+		"bessa":   {Lat: -7.0700, Lng: -34.8350}, // ~10.1 km
+		"recife":  {Lat: -8.0476, Lng: -34.8770}, // ~100 km
+		"unknown": nil,
+	}
+	ids := map[string]string{}
+	for name, p := range points {
+		created, err := db.CreateAd(ctx, domain.Ad{
+			Type:         "SALE",
+			PriceBRL:     1000,
+			Title:        name,
+			CEP:          "58000-000",
+			Street:       "Rua A",
+			Neighborhood: "Centro",
+			City:         "Joao Pessoa",
+			State:        "PB",
+			Location:     p,
+		})
+		require.NoError(t, err)
+		ids[created.ID] = name
+	}
+
+	names := func(f repo.AdsFilter) []string {
+		t.Helper()
+		ads, _, err := db.ListAds(ctx, f, 1, 10)
+		require.NoError(t, err)
+		out := make([]string, 0, len(ads))
+		for _, a := range ads {
+			out = append(out, ids[a.ID])
+		}
+		return out
+	}
+
+	near := &domain.GeoPoint{Lat: -7.1515, Lng: -34.7947}
+	radius := 10.0
+	require.Equal(t, []string{"cabo", "tambau"}, names(repo.AdsFilter{Near: near, RadiusKM: &radius, Sort: domain.AdSortDistance}))
+	require.Equal(t, []string{"cabo", "tambau", "bessa", "recife", "unknown"}, names(repo.AdsFilter{Near: near, Sort: domain.AdSortDistance}))
+
+	box := &domain.GeoBox{MinLng: -34.9, MinLat: -7.13, MaxLng: -34.8, MaxLat: -7.0}
+	require.ElementsMatch(t, []string{"tambau", "bessa"}, names(repo.AdsFilter{BBox: box}))
+
+	triangle := []domain.GeoPoint{{Lng: -34.85, Lat: -7.2}, {Lng: -34.75, Lat: -7.2}, {Lng: -34.80, Lat: -7.1}}
+	require.Equal(t, []string{"cabo"}, names(repo.AdsFilter{Polygon: triangle}))
+
+	var got []string
+	var after *repo.AdsCursor
+	f := repo.AdsFilter{Near: near, Sort: domain.AdSortDistance}
+	for {
+		items, next, err := db.ListAdsAfter(ctx, f, after, 2)
+		require.NoError(t, err)
+		for _, a := range items {
+			got = append(got, ids[a.ID])
+		}
+		if next == nil {
+			break
+		}
+		after, err = repo.DecodeAdsCursor(next.Encode())
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"cabo", "tambau", "bessa", "recife", "unknown"}, got)
+}
+
+func TestAds_PriceHistoryAndPriceReducedSince(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE quotes RESTART IDENTITY")
+
+	_, err = db.CreateQuote(ctx, 0.2, time.Now().UTC().Add(-time.Hour))
+	require.NoError(t, err)
+
+	ad, err := db.CreateAd(ctx, domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     300000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+	})
+	require.NoError(t, err)
+	require.Nil(t, ad.PreviousPriceBRL)
+
+	setPrice := func(price float64) domain.Ad {
+		t.Helper()
+		next := ad
+		next.PriceBRL = price
+		updated, err := db.UpdateAd(ctx, next, ad.UpdatedAt)
+		require.NoError(t, err)
+		require.NotNil(t, updated)
+		return *updated
+	}
+
+	ad = setPrice(300000) // same price: no history entry
+	ad = setPrice(270000)
+	require.Equal(t, 300000.0, *ad.PreviousPriceBRL)
+	require.Equal(t, ad.UpdatedAt, *ad.PriceChangedAt)
+
+	_, err = db.CreateQuote(ctx, 0.25, time.Now().UTC())
+	require.NoError(t, err)
+	ad = setPrice(260000)
+
+	history, err := db.ListAdPriceHistory(ctx, ad.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, []float64{300000, 270000, 260000}, []float64{history[0].PriceBRL, history[1].PriceBRL, history[2].PriceBRL})
+	require.Equal(t, 0.2, history[1].Quote.BrlToUsd)
+	require.Equal(t, 0.25, history[2].Quote.BrlToUsd)
+
+	_, err = db.CreateAd(ctx, domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     100000,
+		CEP:          "58000-000",
+		Street:       "Rua B",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+	})
+	require.NoError(t, err)
+
+	since := time.Now().UTC().Add(-time.Minute)
+	items, total, err := db.ListAds(ctx, repo.AdsFilter{PriceReducedSince: &since}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, ad.ID, items[0].ID)
+
+	ad = setPrice(280000)
+	_, total, err = db.ListAds(ctx, repo.AdsFilter{PriceReducedSince: &since}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, total, "a raise ends the price drop")
+}
+
+func TestAds_CreateAds_AllOrNone(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	base := domain.Ad{
+		Type:         "SALE",
+		PriceBRL:     250000,
+		CEP:          "58000-000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "Joao Pessoa",
+		State:        "PB",
+	}
+	rent := base
+	rent.Type = "RENT"
+	rent.PriceBRL = 2000
+
+	created, err := db.CreateAds(ctx, []domain.Ad{base, rent}, nil)
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	require.NotEmpty(t, created[0].ID)
+	require.Equal(t, "RENT", created[1].Type)
+
+	bad := base
+	bad.Type = "LEASE" // rejected by the type check constraint
+	_, err = db.CreateAds(ctx, []domain.Ad{base, bad}, nil)
+	require.Error(t, err)
+
+	_, total, err := db.ListAds(ctx, repo.AdsFilter{}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+}
+
+func TestAds_ListOpenAdsAtAndSharingCEP(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	ad := func(cep, typ, status string) domain.Ad {
+		return domain.Ad{Type: typ, Status: status, PriceBRL: 250000, CEP: cep, Street: "Rua A",
+			Neighborhood: "Centro", City: "Joao Pessoa", State: "PB"}
+	}
+	created, err := db.CreateAds(ctx, []domain.Ad{
+		ad("58000-000", "SALE", domain.AdStatusActive),
+		ad("58000-000", "SALE", domain.AdStatusPaused),
+		ad("58000-000", "SALE", domain.AdStatusSold), // closed, never a duplicate
+		ad("58000-000", "RENT", domain.AdStatusActive),
+		ad("58000-001", "SALE", domain.AdStatusActive),
+	}, nil)
+	require.NoError(t, err)
+	deleted, err := db.CreateAd(ctx, ad("58000-000", "SALE", domain.AdStatusActive))
+	require.NoError(t, err)
+	_, err = db.SoftDeleteAd(ctx, deleted.ID)
+	require.NoError(t, err)
+
+	var at []domain.Ad
+	stored, err := db.CreateAds(ctx, []domain.Ad{ad("58000-000", "SALE", domain.AdStatusActive)}, func(open []domain.Ad) ([]domain.Ad, error) {
+		at = open
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Empty(t, stored)
+	require.Len(t, at, 2)
+	require.ElementsMatch(t, []string{created[0].ID, created[1].ID}, []string{at[0].ID, at[1].ID})
+
+	// The open RENT and the other CEP have no one to pair with.
+	sharing, err := db.ListOpenAdsSharingCEP(ctx)
+	require.NoError(t, err)
+	require.Len(t, sharing, 2)
+	require.ElementsMatch(t, []string{created[0].ID, created[1].ID}, []string{sharing[0].ID, sharing[1].ID})
+}
+
+func TestAds_CreateAds_ChecksDuplicatesUnderLock(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	sale := domain.Ad{Type: "SALE", Status: domain.AdStatusActive, PriceBRL: 250000, CEP: "58000-000", Street: "Rua A",
+		Neighborhood: "Centro", City: "Joao Pessoa", State: "PB"}
+	keepAll := func(open []domain.Ad) ([]domain.Ad, error) { return []domain.Ad{sale}, nil }
+
+	checking, proceed := make(chan struct{}), make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.CreateAds(ctx, []domain.Ad{sale}, func(open []domain.Ad) ([]domain.Ad, error) {
+			close(checking)
+			<-proceed
+			return []domain.Ad{sale}, nil
+		})
+		done <- err
+	}()
+	<-checking
+
+	// Another type at the CEP is not held up.
+	rent := sale
+	rent.Type = "RENT"
+	_, err = db.CreateAds(ctx, []domain.Ad{rent}, func(open []domain.Ad) ([]domain.Ad, error) { return []domain.Ad{rent}, nil })
+	require.NoError(t, err)
+
+	// The same key waits for the first check to commit.
+	waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	_, err = db.CreateAds(waitCtx, []domain.Ad{sale}, keepAll)
+	require.Error(t, err)
+
+	close(proceed)
+	require.NoError(t, <-done)
+
+	// The next check sees the ad the first one stored.
+	var seen []domain.Ad
+	_, err = db.CreateAds(ctx, []domain.Ad{sale}, func(open []domain.Ad) ([]domain.Ad, error) {
+		seen = open
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, seen, 1)
+}
+
+func TestAds_AdPriceStats(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads RESTART IDENTITY CASCADE")
+
+	for _, a := range []struct {
+		typ                string
+		price              float64
+		neighborhood, city string
+	}{
+		{"RENT", 1000, "Manaíra", "João Pessoa"},
+		{"RENT", 2000, "manaira", "Joao Pessoa"},
+		{"RENT", 4000, "Manaíra", "João Pessoa"},
+		{"RENT", 3000, "Tambaú", "João Pessoa"},
+		{"SALE", 500000, "Tambaú", "João Pessoa"},
+	} {
+		_, err := db.CreateAd(ctx, domain.Ad{Type: a.typ, Status: domain.AdStatusActive, PriceBRL: a.price, CEP: "58038-000",
+			Street: "Rua A", Neighborhood: a.neighborhood, City: a.city, State: "PB"})
+		require.NoError(t, err)
+	}
+
+	rent := "RENT"
+	stats, err := db.AdPriceStats(ctx, repo.AdsFilter{Type: &rent})
+	require.NoError(t, err)
+
+	levels := []string{}
+	for _, s := range stats {
+		levels = append(levels, s.Level)
+	}
+	require.Equal(t, []string{domain.AdStatsByType, domain.AdStatsByState, domain.AdStatsByCity,
+		domain.AdStatsByNeighborhood, domain.AdStatsByNeighborhood}, levels)
+
+	all := stats[0]
+	require.Equal(t, 4, all.Count)
+	require.Equal(t, 2500.0, all.Avg)
+	require.Equal(t, 2500.0, all.Median)
+	require.Equal(t, 1000.0, all.Min)
+	require.Equal(t, 4000.0, all.Max)
+	require.Len(t, all.Percentiles, len(domain.AdStatsPercentiles))
+	require.Empty(t, all.State)
+
+	require.Equal(t, "PB", stats[1].State)
+	require.Equal(t, "João Pessoa", stats[2].City) // the most common spelling
+
+	// Neighborhoods group ignoring accents; the largest comes first.
+	manaira := stats[3]
+	require.Equal(t, "Manaíra", manaira.Neighborhood)
+	require.Equal(t, 3, manaira.Count)
+	require.Equal(t, 2000.0, manaira.Median)
+	require.Equal(t, 1, stats[4].Count)
+}
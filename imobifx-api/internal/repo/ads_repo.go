@@ -2,58 +2,412 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/josinaldojr/imobifx-api/internal/domain"
 )
 
 type AdsFilter struct {
-	Type     *string
-	City     *string
+	// Query is matched against the search_tsv column with websearch syntax
+	// ("varanda gourmet" -praia) and ranks the results.
+	Query *string
+
+	Type *string
+	// City and Neighborhood match ignoring case and accents.
+	City         *string
+	Neighborhood *string
+	// CEPFrom and CEPTo are inclusive bounds on the formatted CEP.
+	CEPFrom  *string
+	CEPTo    *string
 	State    *string
 	MinPrice *float64
 	MaxPrice *float64
+	Status   *string
+	// PriceReducedSince keeps ads whose latest price change, at or after
+	// this time, lowered the price.
+	PriceReducedSince *time.Time
+
+	// Near with RadiusKM keeps ads within that distance; Near alone is the
+	// reference of the distance sort. BBox and Polygon keep ads located
+	// inside them.
+	Near     *domain.GeoPoint
+	RadiusKM *float64
+	BBox     *domain.GeoBox
+	Polygon  []domain.GeoPoint
+
+	MinBedrooms  *int
+	MaxBedrooms  *int
+	MinBathrooms *int
+	MaxBathrooms *int
+	MinParking   *int
+	MaxParking   *int
+	MinArea      *float64
+	MaxArea      *float64
+	MinCondoFee  *float64
+	MaxCondoFee  *float64
+	MinIPTU      *float64
+	MaxIPTU      *float64
+
+	// Sort is one of the domain.AdSort values; unknown values fall back to
+	// newest first. Relevance needs Query.
+	Sort string
+
+	// OnlyLive hides ads whose publish_at is still in the future or whose
+	// expires_at already passed, even before the scheduler updates them.
+	OnlyLive bool
+
+	// Archived lists soft-deleted ads instead of live ones.
+	Archived bool
 }
 
+const adColumns = `id, type, status, price_brl, previous_price_brl, price_changed_at,
+		       title, description, image_path,
+		       cep, street, number, complement, neighborhood, city, state,
+		       latitude, longitude,
+		       bedrooms, bathrooms, parking_spaces, area_m2, condo_fee_brl, iptu_brl,
+		       publish_at, expires_at, created_at, updated_at, deleted_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAd(row rowScanner) (domain.Ad, error) {
+	var a domain.Ad
+	var lat, lng *float64
+	err := row.Scan(&a.ID, &a.Type, &a.Status, &a.PriceBRL, &a.PreviousPriceBRL, &a.PriceChangedAt,
+		&a.Title, &a.Description, &a.ImagePath,
+		&a.CEP, &a.Street, &a.Number, &a.Complement, &a.Neighborhood, &a.City, &a.State,
+		&lat, &lng,
+		&a.Bedrooms, &a.Bathrooms, &a.Parking, &a.AreaM2, &a.CondoFeeBRL, &a.IPTUBRL,
+		&a.PublishAt, &a.ExpiresAt, &a.CreatedAt, &a.UpdatedAt, &a.DeletedAt)
+	if lat != nil && lng != nil {
+		a.Location = &domain.GeoPoint{Lat: *lat, Lng: *lng}
+	}
+	return a, err
+}
+
+// coordinates splits an optional location into nullable columns.
+func coordinates(p *domain.GeoPoint) (lat, lng *float64) {
+	if p == nil {
+		return nil, nil
+	}
+	return &p.Lat, &p.Lng
+}
+
+// CreateAd inserts the ad, its images and its ad.created event in one
+// transaction. The first image becomes the cover; image_path stays empty until
+// its variants are ready.
 func (d *DB) CreateAd(ctx context.Context, ad domain.Ad) (domain.Ad, error) {
-	row := d.Pool.QueryRow(ctx, `
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	out, err := insertAd(ctx, tx, ad)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if err := appendEvent(ctx, tx, domain.EventAdCreated, out); err != nil {
+		return domain.Ad{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Ad{}, err
+	}
+	return out, nil
+}
+
+// CreateAds inserts several ads in one transaction: either all of them are
+// stored or none is. A non-nil keep is a duplicate check run in the same
+// transaction: it gets the open ads sharing the CEP and type of any of ads,
+// read under lockAdAddresses, and returns the ads to insert. An error from
+// keep, or no ads to keep, stores nothing.
+func (d *DB) CreateAds(ctx context.Context, ads []domain.Ad, keep func(open []domain.Ad) ([]domain.Ad, error)) ([]domain.Ad, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if keep != nil {
+		if err := lockAdAddresses(ctx, tx, ads); err != nil {
+			return nil, err
+		}
+		open, err := listOpenAdsAt(ctx, tx, ads)
+		if err != nil {
+			return nil, err
+		}
+		if ads, err = keep(open); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]domain.Ad, 0, len(ads))
+	for _, ad := range ads {
+		created, err := insertAd(ctx, tx, ad)
+		if err != nil {
+			return nil, err
+		}
+		if err := appendEvent(ctx, tx, domain.EventAdCreated, created); err != nil {
+			return nil, err
+		}
+		out = append(out, created)
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func insertAd(ctx context.Context, q querier, ad domain.Ad) (domain.Ad, error) {
+	lat, lng := coordinates(ad.Location)
+	row := q.QueryRow(ctx, `
 		INSERT INTO ads (
-			type, price_brl, image_path,
-			cep, street, number, complement, neighborhood, city, state
+			type, status, price_brl, image_path,
+			cep, street, number, complement, neighborhood, city, state,
+			bedrooms, bathrooms, parking_spaces, area_m2, condo_fee_brl, iptu_brl,
+			publish_at, expires_at, title, description,
+			latitude, longitude
 		) VALUES (
-			$1,$2,$3,
-			$4,$5,$6,$7,$8,$9,$10
+			$1,COALESCE(NULLIF($2, ''), 'ACTIVE'),$3,$4,
+			$5,$6,$7,$8,$9,$10,$11,
+			$12,$13,$14,$15,$16,$17,
+			$18,$19,$20,$21,
+			$22,$23
 		)
-		RETURNING id, type, price_brl, image_path,
-		          cep, street, number, complement, neighborhood, city, state, created_at
-	`, ad.Type, ad.PriceBRL, ad.ImagePath,
-		ad.CEP, ad.Street, ad.Number, ad.Complement, ad.Neighborhood, ad.City, ad.State)
+		RETURNING `+adColumns,
+		ad.Type, ad.Status, ad.PriceBRL, ad.ImagePath,
+		ad.CEP, ad.Street, ad.Number, ad.Complement, ad.Neighborhood, ad.City, ad.State,
+		ad.Bedrooms, ad.Bathrooms, ad.Parking, ad.AreaM2, ad.CondoFeeBRL, ad.IPTUBRL,
+		ad.PublishAt, ad.ExpiresAt, ad.Title, ad.Description,
+		lat, lng)
+
+	out, err := scanAd(row)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+
+	paths := make([]string, 0, len(ad.Images))
+	for _, img := range ad.Images {
+		paths = append(paths, img.Path)
+	}
+	if out.Images, err = insertAdImages(ctx, q, out.ID, 0, true, paths); err != nil {
+		return domain.Ad{}, err
+	}
+	return out, nil
+}
+
+func (d *DB) GetAd(ctx context.Context, id string) (*domain.Ad, error) {
+	row := d.Pool.QueryRow(ctx, `
+		SELECT `+adColumns+`
+		FROM ads
+		WHERE id = $1
+	`, id)
 
-	var out domain.Ad
-	err := row.Scan(&out.ID, &out.Type, &out.PriceBRL, &out.ImagePath,
-		&out.CEP, &out.Street, &out.Number, &out.Complement, &out.Neighborhood, &out.City, &out.State, &out.CreatedAt)
-	return out, err
+	a, err := scanAd(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := loadAdImages(ctx, d.Pool, []*domain.Ad{&a}); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// UpdateAd overwrites the editable fields of an ad only if its updated_at
+// still equals expectedUpdatedAt. It returns nil when the row is missing or
+// was changed concurrently. updated_at always moves forward, even for two
+// writes within the same microsecond, so it can back an ETag. Like every
+// other change to an ad, it records an event in the outbox.
+func (d *DB) UpdateAd(ctx context.Context, ad domain.Ad, expectedUpdatedAt time.Time) (*domain.Ad, error) {
+	lat, lng := coordinates(ad.Location)
+	return d.writeAd(ctx, domain.EventAdUpdated, `
+		UPDATE ads SET
+			type = $2, price_brl = $3, image_path = $4,
+			cep = $5, street = $6, number = $7, complement = $8,
+			neighborhood = $9, city = $10, state = $11,
+			bedrooms = $12, bathrooms = $13, parking_spaces = $14,
+			area_m2 = $15, condo_fee_brl = $16, iptu_brl = $17,
+			title = $18, description = $19,
+			latitude = $20, longitude = $21,
+			updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')
+		WHERE id = $1 AND updated_at = $22 AND deleted_at IS NULL
+		RETURNING `+adColumns,
+		ad.ID, ad.Type, ad.PriceBRL, ad.ImagePath,
+		ad.CEP, ad.Street, ad.Number, ad.Complement,
+		ad.Neighborhood, ad.City, ad.State,
+		ad.Bedrooms, ad.Bathrooms, ad.Parking,
+		ad.AreaM2, ad.CondoFeeBRL, ad.IPTUBRL,
+		ad.Title, ad.Description,
+		lat, lng,
+		expectedUpdatedAt)
+}
+
+// UpdateAdStatus moves an ad from one status to another. It returns nil when
+// the ad is missing, archived or no longer in the from status.
+func (d *DB) UpdateAdStatus(ctx context.Context, id, from, to string) (*domain.Ad, error) {
+	return d.writeAd(ctx, domain.EventAdUpdated, `
+		UPDATE ads SET
+			status = $3,
+			updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
+		RETURNING `+adColumns, id, from, to)
+}
+
+// RenewAd sets a new expires_at, unless the current one is later, and moves
+// the ad from one status to another (from and to may be equal). It returns
+// nil when the ad is missing, archived or no longer in the from status.
+func (d *DB) RenewAd(ctx context.Context, id, from, to string, expiresAt time.Time) (*domain.Ad, error) {
+	return d.writeAd(ctx, domain.EventAdUpdated, `
+		UPDATE ads SET
+			status = $3,
+			expires_at = GREATEST(expires_at, $4),
+			updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
+		RETURNING `+adColumns, id, from, to, expiresAt)
+}
+
+// ActivateScheduledAds publishes DRAFT ads whose publish_at is due and which
+// have not expired meanwhile.
+func (d *DB) ActivateScheduledAds(ctx context.Context, now time.Time) (int64, error) {
+	return d.writeAds(ctx, domain.EventAdUpdated, `
+		UPDATE ads SET
+			status = 'ACTIVE',
+			updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')
+		WHERE status = 'DRAFT'
+		  AND publish_at IS NOT NULL AND publish_at <= $1
+		  AND (expires_at IS NULL OR expires_at > $1)
+		  AND deleted_at IS NULL
+		RETURNING `+adColumns, now)
+}
+
+// ExpireAds moves listed ads (ACTIVE or PAUSED, or scheduled DRAFTs) whose
+// expires_at passed to EXPIRED.
+func (d *DB) ExpireAds(ctx context.Context, now time.Time) (int64, error) {
+	return d.writeAds(ctx, domain.EventAdUpdated, `
+		UPDATE ads SET
+			status = 'EXPIRED',
+			updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')
+		WHERE (status IN ('ACTIVE', 'PAUSED') OR (status = 'DRAFT' AND publish_at IS NOT NULL))
+		  AND expires_at IS NOT NULL AND expires_at <= $1
+		  AND deleted_at IS NULL
+		RETURNING `+adColumns, now)
+}
+
+// SoftDeleteAd archives a live ad. It reports false when the ad does not
+// exist or is already archived.
+func (d *DB) SoftDeleteAd(ctx context.Context, id string) (bool, error) {
+	a, err := d.writeAd(ctx, domain.EventAdDeleted, `
+		UPDATE ads SET
+			deleted_at = now(),
+			updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING `+adColumns, id)
+	return a != nil, err
+}
+
+// RestoreAd brings back an ad archived at or after deletedSince. It returns
+// nil when no such archived ad exists.
+func (d *DB) RestoreAd(ctx context.Context, id string, deletedSince time.Time) (*domain.Ad, error) {
+	return d.writeAd(ctx, domain.EventAdUpdated, `
+		UPDATE ads SET
+			deleted_at = NULL,
+			updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')
+		WHERE id = $1 AND deleted_at IS NOT NULL AND deleted_at >= $2
+		RETURNING `+adColumns, id, deletedSince)
+}
+
+// PurgeDeletedAds hard-deletes ads archived before deletedBefore and returns
+// the removed rows, with their images, so the files can be cleaned up.
+func (d *DB) PurgeDeletedAds(ctx context.Context, deletedBefore time.Time) ([]domain.Ad, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT `+adColumns+`
+		FROM ads
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		FOR UPDATE
+	`, deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	out, err := collectAds(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	ptrs := make([]*domain.Ad, len(out))
+	ids := make([]string, len(out))
+	for i := range out {
+		ptrs[i] = &out[i]
+		ids[i] = out[i].ID
+	}
+	if err := loadAdImages(ctx, tx, ptrs); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM ads WHERE id = ANY($1)`, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func collectAds(rows pgx.Rows) ([]domain.Ad, error) {
+	defer rows.Close()
+
+	out := []domain.Ad{}
+	for rows.Next() {
+		a, err := scanAd(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
 }
 
 func (d *DB) ListAds(ctx context.Context, f AdsFilter, page, pageSize int) ([]domain.Ad, int, error) {
 	where, args := buildAdsWhere(f)
 	offset := (page - 1) * pageSize
 
-	countSQL := "SELECT count(*) FROM ads " + where
-	var total int
-	if err := d.Pool.QueryRow(ctx, countSQL, args...).Scan(&total); err != nil {
+	total, err := d.CountAds(ctx, f)
+	if err != nil {
 		return nil, 0, err
 	}
 
+	sort, args := adsSortFor(f, args)
+
 	listSQL := fmt.Sprintf(`
-		SELECT id, type, price_brl, image_path,
-		       cep, street, number, complement, neighborhood, city, state, created_at
+		SELECT %s
 		FROM ads
 		%s
-		ORDER BY created_at DESC
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, where, len(args)+1, len(args)+2)
+	`, adColumns, where, sort.orderBy(), len(args)+1, len(args)+2)
 
 	args = append(args, pageSize, offset)
 
@@ -65,13 +419,23 @@ func (d *DB) ListAds(ctx context.Context, f AdsFilter, page, pageSize int) ([]do
 
 	out := make([]domain.Ad, 0, pageSize)
 	for rows.Next() {
-		var a domain.Ad
-		if err := rows.Scan(&a.ID, &a.Type, &a.PriceBRL, &a.ImagePath,
-			&a.CEP, &a.Street, &a.Number, &a.Complement, &a.Neighborhood, &a.City, &a.State, &a.CreatedAt); err != nil {
+		a, err := scanAd(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		out = append(out, a)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	ptrs := make([]*domain.Ad, len(out))
+	for i := range out {
+		ptrs[i] = &out[i]
+	}
+	if err := loadAdImages(ctx, d.Pool, ptrs); err != nil {
+		return nil, 0, err
+	}
 	return out, total, nil
 }
 
@@ -84,11 +448,39 @@ func buildAdsWhere(f AdsFilter) (string, []interface{}) {
 		clauses = append(clauses, fmt.Sprintf(expr, len(args)))
 	}
 
+	if f.Archived {
+		clauses = append(clauses, "deleted_at IS NOT NULL")
+	} else {
+		clauses = append(clauses, "deleted_at IS NULL")
+	}
+	if f.OnlyLive {
+		clauses = append(clauses,
+			"(publish_at IS NULL OR publish_at <= now())",
+			"(expires_at IS NULL OR expires_at > now())",
+		)
+	}
+	if f.Query != nil {
+		// A query made only of stop words ("de", "com") parses to an empty
+		// tsquery, which would match nothing; treat it as no filter.
+		add("(numnode(websearch_to_tsquery('pt_unaccent', $%[1]d)) = 0 OR search_tsv @@ websearch_to_tsquery('pt_unaccent', $%[1]d))", *f.Query)
+	}
 	if f.Type != nil {
 		add("type = $%d", *f.Type)
 	}
+	if f.Status != nil {
+		add("status = $%d", *f.Status)
+	}
 	if f.City != nil {
-		add("city = $%d", *f.City)
+		add("lower(immutable_unaccent(city)) = lower(immutable_unaccent($%d))", *f.City)
+	}
+	if f.Neighborhood != nil {
+		add("lower(immutable_unaccent(neighborhood)) = lower(immutable_unaccent($%d))", *f.Neighborhood)
+	}
+	if f.CEPFrom != nil {
+		add("cep >= $%d", *f.CEPFrom)
+	}
+	if f.CEPTo != nil {
+		add("cep <= $%d", *f.CEPTo)
 	}
 	if f.State != nil {
 		add("state = $%d", *f.State)
@@ -99,9 +491,77 @@ func buildAdsWhere(f AdsFilter) (string, []interface{}) {
 	if f.MaxPrice != nil {
 		add("price_brl <= $%d", *f.MaxPrice)
 	}
-
-	if len(clauses) == 0 {
-		return "", args
+	if f.PriceReducedSince != nil {
+		add("previous_price_brl > price_brl AND price_changed_at >= $%d", *f.PriceReducedSince)
+	}
+	if f.Near != nil && f.RadiusKM != nil {
+		// The bounding box lets the location index discard far rows before
+		// the exact distance is computed.
+		box := domain.BoundingBox(*f.Near, *f.RadiusKM)
+		add("location <@ $%d::box", geoBox(box))
+		args = append(args, f.Near.Lat, f.Near.Lng, *f.RadiusKM)
+		n := len(args)
+		clauses = append(clauses, fmt.Sprintf("haversine_km(latitude, longitude, $%d::float8, $%d::float8) <= $%d::float8", n-2, n-1, n))
+	}
+	if f.BBox != nil {
+		add("location <@ $%d::box", geoBox(*f.BBox))
+	}
+	if len(f.Polygon) > 0 {
+		add("location <@ $%d::polygon", geoPolygon(f.Polygon))
+	}
+	if f.MinBedrooms != nil {
+		add("bedrooms >= $%d", *f.MinBedrooms)
+	}
+	if f.MaxBedrooms != nil {
+		add("bedrooms <= $%d", *f.MaxBedrooms)
+	}
+	if f.MinBathrooms != nil {
+		add("bathrooms >= $%d", *f.MinBathrooms)
 	}
+	if f.MaxBathrooms != nil {
+		add("bathrooms <= $%d", *f.MaxBathrooms)
+	}
+	if f.MinParking != nil {
+		add("parking_spaces >= $%d", *f.MinParking)
+	}
+	if f.MaxParking != nil {
+		add("parking_spaces <= $%d", *f.MaxParking)
+	}
+	if f.MinArea != nil {
+		add("area_m2 >= $%d", *f.MinArea)
+	}
+	if f.MaxArea != nil {
+		add("area_m2 <= $%d", *f.MaxArea)
+	}
+	if f.MinCondoFee != nil {
+		add("condo_fee_brl >= $%d", *f.MinCondoFee)
+	}
+	if f.MaxCondoFee != nil {
+		add("condo_fee_brl <= $%d", *f.MaxCondoFee)
+	}
+	if f.MinIPTU != nil {
+		add("iptu_brl >= $%d", *f.MinIPTU)
+	}
+	if f.MaxIPTU != nil {
+		add("iptu_brl <= $%d", *f.MaxIPTU)
+	}
+
 	return "WHERE " + strings.Join(clauses, " AND "), args
 }
+
+// geoBox and geoPolygon encode areas in the point space of the location
+// column, where x is the longitude.
+func geoBox(b domain.GeoBox) pgtype.Box {
+	return pgtype.Box{
+		P:     [2]pgtype.Vec2{{X: b.MaxLng, Y: b.MaxLat}, {X: b.MinLng, Y: b.MinLat}},
+		Valid: true,
+	}
+}
+
+func geoPolygon(vertices []domain.GeoPoint) pgtype.Polygon {
+	p := pgtype.Polygon{P: make([]pgtype.Vec2, len(vertices)), Valid: true}
+	for i, v := range vertices {
+		p.P[i] = pgtype.Vec2{X: v.Lng, Y: v.Lat}
+	}
+	return p
+}
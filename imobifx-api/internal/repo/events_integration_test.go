@@ -0,0 +1,102 @@
+//go:build integration
+
+package repo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+)
+
+func TestEvents_ListenAndReplay(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads, quotes, outbox_events RESTART IDENTITY CASCADE")
+
+	last, err := db.LastEventSeq(ctx)
+	require.NoError(t, err)
+	require.Zero(t, last)
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	wake := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() { done <- db.ListenEvents(listenCtx, wake) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	awake := func() bool {
+		select {
+		case <-wake:
+			return true
+		case <-time.After(5 * time.Second):
+			return false
+		}
+	}
+	// The first signal means LISTEN is active.
+	require.True(t, awake(), "no signal once listening")
+
+	ad, err := db.CreateAd(ctx, domain.Ad{Type: "RENT", PriceBRL: 2000, CEP: "58000-000", Street: "Rua A", Neighborhood: "Centro", City: "João Pessoa", State: "PB"})
+	require.NoError(t, err)
+	require.True(t, awake(), "commit not announced")
+
+	// A write that fails announces nothing.
+	_, err = db.CreateAd(ctx, domain.Ad{Type: "INVALID"})
+	require.Error(t, err)
+	select {
+	case <-wake:
+		t.Fatal("rolled back write announced")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// An event inserted first but committed last is positioned last.
+	tx, err := db.Pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	var lateID int64
+	require.NoError(t, tx.QueryRow(ctx, `INSERT INTO outbox_events (type, data) VALUES ('quote.created', '{}') RETURNING id`).Scan(&lateID))
+	_, err = db.CreateQuote(ctx, 0.2, time.Now())
+	require.NoError(t, err)
+
+	n, err := db.SequenceEvents(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+	require.NoError(t, tx.Commit(ctx))
+	n, err = db.SequenceEvents(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	events, err := db.ListEventsAfter(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, domain.EventAdCreated, events[0].Type)
+	require.Contains(t, string(events[0].Data), ad.ID)
+	require.Equal(t, domain.EventQuoteCreated, events[1].Type)
+	require.Equal(t, lateID, events[2].ID)
+	require.Greater(t, events[1].ID, lateID)
+	for i, e := range events {
+		require.EqualValues(t, i+1, e.Seq)
+	}
+
+	events, err = db.ListEventsAfter(ctx, 2, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, lateID, events[0].ID)
+
+	last, err = db.LastEventSeq(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, last)
+}
@@ -0,0 +1,199 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// eventsChannel is the LISTEN/NOTIFY channel announcing the ID of every
+// event, once committed.
+const eventsChannel = "outbox_events"
+
+// eventsSequenceLock is the advisory lock key serializing SequenceEvents.
+const eventsSequenceLock int64 = 0x6f7574626f78 // "outbox"
+
+const eventColumns = `id, COALESCE(seq, 0), type, created_at, data`
+
+func scanEvent(row rowScanner) (domain.Event, error) {
+	var e domain.Event
+	err := row.Scan(&e.ID, &e.Seq, &e.Type, &e.CreatedAt, &e.Data)
+	return e, err
+}
+
+// appendEvent records an event in the outbox through q, which must be the
+// transaction of the change it describes: the event is stored, and announced
+// on eventsChannel, if and only if the change is committed.
+func appendEvent(ctx context.Context, q querier, typ string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(ctx, `
+		WITH e AS (
+			INSERT INTO outbox_events (type, data) VALUES ($1, $2)
+			RETURNING id
+		)
+		SELECT pg_notify('`+eventsChannel+`', id::text) FROM e
+	`, typ, payload)
+	return err
+}
+
+// writeAd runs a statement returning adColumns and records event for the
+// returned ad in the same transaction. It returns nil when no row matched.
+func (d *DB) writeAd(ctx context.Context, event, sql string, args ...any) (*domain.Ad, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	a, err := scanAd(tx.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := appendEvent(ctx, tx, event, a); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// writeAds is writeAd for statements touching many ads. It returns how many
+// were written.
+func (d *DB) writeAds(ctx context.Context, event, sql string, args ...any) (int64, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	ads, err := collectAds(rows)
+	if err != nil {
+		return 0, err
+	}
+	for _, a := range ads {
+		if err := appendEvent(ctx, tx, event, a); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int64(len(ads)), nil
+}
+
+// DeleteOldEvents removes the events recorded before createdBefore once they
+// were handed to the webhooks and none of their deliveries is still pending.
+// Their delivery log goes with them.
+func (d *DB) DeleteOldEvents(ctx context.Context, createdBefore time.Time) (int64, error) {
+	tag, err := d.Pool.Exec(ctx, `
+		DELETE FROM outbox_events e
+		WHERE e.created_at < $1 AND e.dispatched_at IS NOT NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM webhook_deliveries
+			WHERE event_id = e.id AND status = 'PENDING'
+		  )
+	`, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// SequenceEvents gives the committed events that have none their stream
+// position, in ID order, and returns how many it numbered. Sequencing runs
+// one transaction at a time, so positions grow in the order they become
+// visible: once a reader has seen position N, no later commit gets a lower
+// one. IDs lack that guarantee, being taken when the event is inserted.
+func (d *DB) SequenceEvents(ctx context.Context) (int64, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, eventsSequenceLock); err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE outbox_events e SET seq = n.seq
+		FROM (
+			SELECT id, nextval('outbox_events_stream_seq') AS seq
+			FROM (SELECT id FROM outbox_events WHERE seq IS NULL ORDER BY id) u
+		) n
+		WHERE e.id = n.id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), tx.Commit(ctx)
+}
+
+// ListEventsAfter returns up to limit sequenced events positioned after
+// afterSeq, in stream order.
+func (d *DB) ListEventsAfter(ctx context.Context, afterSeq int64, limit int) ([]domain.Event, error) {
+	rows, err := d.Pool.Query(ctx, `
+		SELECT `+eventColumns+` FROM outbox_events
+		WHERE seq > $1
+		ORDER BY seq
+		LIMIT $2
+	`, afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Event, error) {
+		return scanEvent(row)
+	})
+}
+
+// LastEventSeq returns the position of the latest sequenced event, or 0 when
+// there is none.
+func (d *DB) LastEventSeq(ctx context.Context) (int64, error) {
+	var seq int64
+	err := d.Pool.QueryRow(ctx, `SELECT COALESCE(max(seq), 0) FROM outbox_events`).Scan(&seq)
+	return seq, err
+}
+
+// ListenEvents signals on wake once it is listening, then whenever events
+// are committed, on any instance sharing the database. Signals are coalesced:
+// wake should have a buffer of one, and a signal may stand for many events.
+// It holds a connection of its own and returns when ctx is done or the
+// connection fails.
+func (d *DB) ListenEvents(ctx context.Context, wake chan<- struct{}) error {
+	pooled, err := d.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// A connection left listening, or broken by a cancelled wait, must not
+	// go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, `LISTEN `+eventsChannel); err != nil {
+		return err
+	}
+	for {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+	}
+}
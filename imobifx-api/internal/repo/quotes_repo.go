@@ -7,16 +7,31 @@ import (
 	"github.com/josinaldojr/imobifx-api/internal/domain"
 )
 
+// CreateQuote stores a quote and its quote.created event in one transaction.
 func (d *DB) CreateQuote(ctx context.Context, brlToUsd float64, effectiveAt time.Time) (domain.Quote, error) {
-	row := d.Pool.QueryRow(ctx, `
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, `
 		INSERT INTO quotes (brl_to_usd, effective_at)
 		VALUES ($1, $2)
 		RETURNING id, brl_to_usd, effective_at, created_at
 	`, brlToUsd, effectiveAt)
 
 	var q domain.Quote
-	err := row.Scan(&q.ID, &q.BrlToUsd, &q.EffectiveAt, &q.CreatedAt)
-	return q, err
+	if err := row.Scan(&q.ID, &q.BrlToUsd, &q.EffectiveAt, &q.CreatedAt); err != nil {
+		return domain.Quote{}, err
+	}
+	if err := appendEvent(ctx, tx, domain.EventQuoteCreated, q); err != nil {
+		return domain.Quote{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Quote{}, err
+	}
+	return q, nil
 }
 
 func (d *DB) GetCurrentQuote(ctx context.Context) (*domain.Quote, error) {
@@ -0,0 +1,107 @@
+//go:build integration
+
+package repo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+)
+
+func TestSavedSearches_MatchLifecycle(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads, saved_searches RESTART IDENTITY CASCADE")
+
+	email := "ana@example.com"
+	search, err := db.CreateSavedSearch(ctx, domain.SavedSearch{Name: "JP", Email: &email, Filters: []byte(`{"City":"Joao Pessoa"}`), Query: "city=Joao+Pessoa"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"City":"Joao Pessoa"}`, string(search.Filters))
+
+	future := time.Now().Add(time.Hour)
+	ad := func(city, status string, publishAt *time.Time) domain.Ad {
+		return domain.Ad{Type: "RENT", Status: status, PriceBRL: 2000, CEP: "58000-000", Street: "Rua A",
+			Neighborhood: "Centro", City: city, State: "PB", PublishAt: publishAt}
+	}
+	created, err := db.CreateAds(ctx, []domain.Ad{
+		ad("João Pessoa", domain.AdStatusActive, nil),
+		ad("Recife", domain.AdStatusActive, nil),
+		ad("João Pessoa", domain.AdStatusDraft, &future), // not live yet
+	}, nil)
+	require.NoError(t, err)
+
+	claimed, err := db.ClaimUnmatchedAds(ctx, 10)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{created[0].ID, created[1].ID}, claimed)
+	again, err := db.ClaimUnmatchedAds(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	city := "joao pessoa"
+	active := domain.AdStatusActive
+	matched, err := db.MatchAds(ctx, claimed, []repo.AdsFilter{
+		{City: &city, Status: &active, OnlyLive: true},
+		{Status: &active, OnlyLive: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{created[0].ID}, matched[0])
+	require.Len(t, matched[1], 2)
+
+	require.NoError(t, db.RecordSavedSearchMatches(ctx, search.ID, matched[0]))
+	require.NoError(t, db.RecordSavedSearchMatches(ctx, search.ID, matched[0])) // already recorded
+
+	page, total, err := db.ListSavedSearchMatches(ctx, search.ID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, created[0].ID, page[0].Ad.ID)
+	require.Equal(t, search.ID, page[0].SearchID)
+	require.Nil(t, page[0].NotifiedAt)
+
+	pending, err := db.ListPendingSavedSearchMatches(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.NoError(t, db.MarkSavedSearchMatchesNotified(ctx, search.ID, []string{created[0].ID}))
+	pending, err = db.ListPendingSavedSearchMatches(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// Matches of archived ads are neither listed nor notified.
+	archived, err := db.CreateAd(ctx, ad("João Pessoa", domain.AdStatusActive, nil))
+	require.NoError(t, err)
+	require.NoError(t, db.RecordSavedSearchMatches(ctx, search.ID, []string{archived.ID}))
+	_, err = db.SoftDeleteAd(ctx, archived.ID)
+	require.NoError(t, err)
+	page, total, err = db.ListSavedSearchMatches(ctx, search.ID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, page, 1)
+	pending, err = db.ListPendingSavedSearchMatches(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// Released ads are claimed again.
+	require.NoError(t, db.ReleaseUnmatchedAds(ctx, []string{created[1].ID}))
+	claimed, err = db.ClaimUnmatchedAds(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{created[1].ID}, claimed)
+
+	ok, err := db.DeleteSavedSearch(ctx, search.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, total, err = db.ListSavedSearchMatches(ctx, search.ID, 1, 10)
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
@@ -0,0 +1,247 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+const savedSearchColumns = `id, name, email, filters, query, created_at, updated_at`
+
+func scanSavedSearch(row rowScanner) (domain.SavedSearch, error) {
+	var s domain.SavedSearch
+	err := row.Scan(&s.ID, &s.Name, &s.Email, &s.Filters, &s.Query, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func (d *DB) CreateSavedSearch(ctx context.Context, s domain.SavedSearch) (domain.SavedSearch, error) {
+	row := d.Pool.QueryRow(ctx, `
+		INSERT INTO saved_searches (name, email, filters, query)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+savedSearchColumns,
+		s.Name, s.Email, s.Filters, s.Query)
+	return scanSavedSearch(row)
+}
+
+func (d *DB) GetSavedSearch(ctx context.Context, id string) (*domain.SavedSearch, error) {
+	row := d.Pool.QueryRow(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches WHERE id = $1`, id)
+	s, err := scanSavedSearch(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListSavedSearches returns every saved search, oldest first.
+func (d *DB) ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	rows, err := d.Pool.Query(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.SavedSearch{}
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// UpdateSavedSearch replaces the name, contact and filters of a saved search.
+// Matches already recorded are kept. It returns nil when the row is missing.
+func (d *DB) UpdateSavedSearch(ctx context.Context, s domain.SavedSearch) (*domain.SavedSearch, error) {
+	row := d.Pool.QueryRow(ctx, `
+		UPDATE saved_searches
+		SET name = $2, email = $3, filters = $4, query = $5, updated_at = now()
+		WHERE id = $1
+		RETURNING `+savedSearchColumns,
+		s.ID, s.Name, s.Email, s.Filters, s.Query)
+	out, err := scanSavedSearch(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSavedSearch removes a saved search and its matches. It reports
+// whether the row existed.
+func (d *DB) DeleteSavedSearch(ctx context.Context, id string) (bool, error) {
+	tag, err := d.Pool.Exec(ctx, `DELETE FROM saved_searches WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClaimUnmatchedAds marks up to limit ads that went live since the matcher
+// last ran, oldest first, and returns their IDs. Concurrent matchers claim
+// different ads.
+func (d *DB) ClaimUnmatchedAds(ctx context.Context, limit int) ([]string, error) {
+	rows, err := d.Pool.Query(ctx, `
+		UPDATE ads SET searches_matched_at = now()
+		WHERE id IN (
+			SELECT id FROM ads
+			WHERE searches_matched_at IS NULL AND deleted_at IS NULL
+			  AND status = 'ACTIVE'
+			  AND (publish_at IS NULL OR publish_at <= now())
+			  AND (expires_at IS NULL OR expires_at > now())
+			ORDER BY created_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// ReleaseUnmatchedAds gives claimed ads back to the matcher, for a run that
+// failed before recording their matches.
+func (d *DB) ReleaseUnmatchedAds(ctx context.Context, ids []string) error {
+	_, err := d.Pool.Exec(ctx, `UPDATE ads SET searches_matched_at = NULL WHERE id = ANY($1::uuid[])`, ids)
+	return err
+}
+
+// MatchAds returns, for each filter, which of the given ads it matches. The
+// filters are applied exactly as the listing applies them, in a single round
+// trip.
+func (d *DB) MatchAds(ctx context.Context, adIDs []string, filters []AdsFilter) ([][]string, error) {
+	batch := &pgx.Batch{}
+	for _, f := range filters {
+		where, args := buildAdsWhere(f)
+		args = append(args, adIDs)
+		batch.Queue(fmt.Sprintf(`SELECT id FROM ads %s AND id = ANY($%d::uuid[]) ORDER BY created_at, id`, where, len(args)), args...)
+	}
+
+	results := d.Pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	out := make([][]string, len(filters))
+	for i := range filters {
+		rows, err := results.Query()
+		if err != nil {
+			return nil, err
+		}
+		if out[i], err = pgx.CollectRows(rows, pgx.RowTo[string]); err != nil {
+			return nil, err
+		}
+	}
+	return out, results.Close()
+}
+
+// RecordSavedSearchMatches stores the ads a saved search matched, ignoring
+// the ones already recorded.
+func (d *DB) RecordSavedSearchMatches(ctx context.Context, searchID string, adIDs []string) error {
+	_, err := d.Pool.Exec(ctx, `
+		INSERT INTO saved_search_matches (saved_search_id, ad_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`, searchID, adIDs)
+	return err
+}
+
+// ListSavedSearchMatches returns a page of the ads a saved search matched,
+// newest match first, and the total. Archived ads are left out.
+func (d *DB) ListSavedSearchMatches(ctx context.Context, searchID string, page, pageSize int) ([]domain.SavedSearchMatch, int, error) {
+	var total int
+	if err := d.Pool.QueryRow(ctx, `
+		SELECT count(*) FROM saved_search_matches m
+		JOIN ads ON ads.id = m.ad_id AND ads.deleted_at IS NULL
+		WHERE m.saved_search_id = $1
+	`, searchID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := d.Pool.Query(ctx, `
+		SELECT `+adColumns+`, m.saved_search_id, m.matched_at, m.notified_at
+		FROM saved_search_matches m
+		JOIN ads ON ads.id = m.ad_id AND ads.deleted_at IS NULL
+		WHERE m.saved_search_id = $1
+		ORDER BY m.matched_at DESC, m.ad_id
+		LIMIT $2 OFFSET $3
+	`, searchID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	out, err := collectSavedSearchMatches(ctx, d, rows)
+	return out, total, err
+}
+
+// ListPendingSavedSearchMatches returns up to limit matches not notified yet,
+// grouped by saved search and oldest first within each. Matches of archived
+// ads wait until the ad is restored.
+func (d *DB) ListPendingSavedSearchMatches(ctx context.Context, limit int) ([]domain.SavedSearchMatch, error) {
+	rows, err := d.Pool.Query(ctx, `
+		SELECT `+adColumns+`, m.saved_search_id, m.matched_at, m.notified_at
+		FROM saved_search_matches m
+		JOIN ads ON ads.id = m.ad_id AND ads.deleted_at IS NULL
+		WHERE m.notified_at IS NULL
+		ORDER BY m.saved_search_id, m.matched_at, m.ad_id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectSavedSearchMatches(ctx, d, rows)
+}
+
+func (d *DB) MarkSavedSearchMatchesNotified(ctx context.Context, searchID string, adIDs []string) error {
+	_, err := d.Pool.Exec(ctx, `
+		UPDATE saved_search_matches SET notified_at = now()
+		WHERE saved_search_id = $1 AND ad_id = ANY($2::uuid[]) AND notified_at IS NULL
+	`, searchID, adIDs)
+	return err
+}
+
+// matchRow scans the ad columns followed by the match columns.
+type matchRow struct {
+	rowScanner
+	m *domain.SavedSearchMatch
+}
+
+func (r matchRow) Scan(dest ...any) error {
+	return r.rowScanner.Scan(append(dest, &r.m.SearchID, &r.m.MatchedAt, &r.m.NotifiedAt)...)
+}
+
+func collectSavedSearchMatches(ctx context.Context, d *DB, rows pgx.Rows) ([]domain.SavedSearchMatch, error) {
+	defer rows.Close()
+
+	out := []domain.SavedSearchMatch{}
+	for rows.Next() {
+		var m domain.SavedSearchMatch
+		a, err := scanAd(matchRow{rowScanner: rows, m: &m})
+		if err != nil {
+			return nil, err
+		}
+		m.Ad = a
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*domain.Ad, len(out))
+	for i := range out {
+		ptrs[i] = &out[i].Ad
+	}
+	if err := loadAdImages(ctx, d.Pool, ptrs); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+//go:build integration
+
+package repo_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+)
+
+func TestWebhooks_OutboxDeliveryLifecycle(t *testing.T) {
+	dsn := testDSN()
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN/DB_DSN not set")
+	}
+
+	db, err := repo.NewPostgres(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Pool.Exec(ctx, "TRUNCATE TABLE ads, quotes, outbox_events, webhooks RESTART IDENTITY CASCADE")
+
+	adsHook, err := db.CreateWebhook(ctx, domain.Webhook{URL: "https://crm.example.com/ads", Secret: "s1", Events: []string{domain.EventAdCreated, domain.EventAdUpdated}, Active: true})
+	require.NoError(t, err)
+	_, err = db.CreateWebhook(ctx, domain.Webhook{URL: "https://crm.example.com/off", Secret: "s2", Events: domain.EventTypes, Active: false})
+	require.NoError(t, err)
+
+	// Every write records its event; a failed write records none.
+	ad, err := db.CreateAd(ctx, domain.Ad{Type: "RENT", PriceBRL: 2000, CEP: "58000-000", Street: "Rua A", Neighborhood: "Centro", City: "João Pessoa", State: "PB"})
+	require.NoError(t, err)
+	_, err = db.UpdateAdStatus(ctx, ad.ID, domain.AdStatusActive, domain.AdStatusPaused)
+	require.NoError(t, err)
+	_, err = db.CreateQuote(ctx, 0.2, time.Now())
+	require.NoError(t, err)
+	_, err = db.CreateAd(ctx, domain.Ad{Type: "INVALID"})
+	require.Error(t, err)
+
+	var types []string
+	rows, err := db.Pool.Query(ctx, `SELECT type FROM outbox_events ORDER BY id`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var typ string
+		require.NoError(t, rows.Scan(&typ))
+		types = append(types, typ)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{domain.EventAdCreated, domain.EventAdUpdated, domain.EventQuoteCreated}, types)
+
+	// Only the active webhook subscribed to the type gets a delivery.
+	n, err := db.DispatchEvents(ctx, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+	n, err = db.DispatchEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	d, err := db.ClaimWebhookDelivery(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	require.Equal(t, adsHook.ID, d.WebhookID)
+	require.Equal(t, "https://crm.example.com/ads", d.URL)
+	require.Equal(t, "s1", d.Secret)
+	require.Equal(t, domain.EventAdCreated, d.Event.Type)
+	require.Equal(t, 1, d.Attempts)
+	var data domain.Ad
+	require.NoError(t, json.Unmarshal(d.Event.Data, &data))
+	require.Equal(t, ad.ID, data.ID)
+	require.NoError(t, db.CompleteWebhookDelivery(ctx, d.ID, d.Attempts, 204))
+
+	// Failed deliveries wait for their next attempt, then go DEAD.
+	d2, err := db.ClaimWebhookDelivery(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, domain.EventAdUpdated, d2.Event.Type)
+	none, err := db.ClaimWebhookDelivery(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Nil(t, none, "leased")
+	code := 500
+	require.NoError(t, db.FailWebhookDelivery(ctx, d2.ID, d2.Attempts, &code, "unexpected status 500", nil))
+
+	dead := domain.WebhookDeliveryDead
+	log, total, err := db.ListWebhookDeliveries(ctx, adsHook.ID, &dead, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, d2.ID, log[0].ID)
+	require.Equal(t, 500, *log[0].LastStatusCode)
+	require.Nil(t, log[0].NextAttemptAt)
+
+	redelivered, err := db.RedeliverWebhookDelivery(ctx, adsHook.ID, d2.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.WebhookDeliveryPending, redelivered.Status)
+	require.Zero(t, redelivered.Attempts)
+	again, err := db.ClaimWebhookDelivery(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, d2.ID, again.ID)
+
+	_, total, err = db.ListWebhookDeliveries(ctx, adsHook.ID, nil, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+
+	// Events still being delivered are kept.
+	n, err = db.DeleteOldEvents(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+}
@@ -0,0 +1,239 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+const webhookColumns = `id, url, secret, events, active, created_at, updated_at`
+
+func scanWebhook(row rowScanner) (domain.Webhook, error) {
+	var w domain.Webhook
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &w.Events, &w.Active, &w.CreatedAt, &w.UpdatedAt)
+	return w, err
+}
+
+const webhookDeliveryColumns = `d.id, d.webhook_id, e.id, e.type, e.created_at,
+	d.status, d.attempts, d.next_attempt_at, d.last_attempt_at, d.last_status_code,
+	d.last_error, d.delivered_at, d.created_at`
+
+func scanWebhookDelivery(row rowScanner) (domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	err := row.Scan(&d.ID, &d.WebhookID, &d.Event.ID, &d.Event.Type, &d.Event.CreatedAt,
+		&d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastAttemptAt, &d.LastStatusCode,
+		&d.LastError, &d.DeliveredAt, &d.CreatedAt)
+	return d, err
+}
+
+func (d *DB) CreateWebhook(ctx context.Context, w domain.Webhook) (domain.Webhook, error) {
+	return scanWebhook(d.Pool.QueryRow(ctx, `
+		INSERT INTO webhooks (url, secret, events, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+webhookColumns,
+		w.URL, w.Secret, w.Events, w.Active))
+}
+
+func (d *DB) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	w, err := scanWebhook(d.Pool.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ListWebhooks returns every webhook, oldest first.
+func (d *DB) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := d.Pool.Query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// UpdateWebhook replaces the URL, events and state of a webhook, and its
+// secret unless w.Secret is empty. Pending deliveries go to the new URL. It
+// returns nil when the row is missing.
+func (d *DB) UpdateWebhook(ctx context.Context, w domain.Webhook) (*domain.Webhook, error) {
+	out, err := scanWebhook(d.Pool.QueryRow(ctx, `
+		UPDATE webhooks
+		SET url = $2, secret = COALESCE(NULLIF($3, ''), secret), events = $4, active = $5,
+		    updated_at = now()
+		WHERE id = $1
+		RETURNING `+webhookColumns,
+		w.ID, w.URL, w.Secret, w.Events, w.Active))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log. It reports whether
+// the row existed.
+func (d *DB) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	tag, err := d.Pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DispatchEvents queues a delivery of up to limit outbox events, oldest
+// first, for every active webhook subscribed to their type, and marks them
+// dispatched. It returns how many events were dispatched.
+func (d *DB) DispatchEvents(ctx context.Context, limit int) (int64, error) {
+	tag, err := d.Pool.Exec(ctx, `
+		WITH e AS (
+			SELECT id, type FROM outbox_events
+			WHERE dispatched_at IS NULL
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), queued AS (
+			INSERT INTO webhook_deliveries (webhook_id, event_id)
+			SELECT w.id, e.id
+			FROM e JOIN webhooks w ON w.active AND e.type = ANY(w.events)
+			ON CONFLICT DO NOTHING
+		)
+		UPDATE outbox_events o SET dispatched_at = now()
+		FROM e
+		WHERE o.id = e.id
+	`, limit)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClaimWebhookDelivery takes the PENDING delivery of an active webhook that
+// has been due the longest, counts the attempt and returns it with the URL,
+// secret and event data, or nil when nothing is due. The delivery stays
+// PENDING but is not due again before leaseUntil, so a worker that dies
+// mid-attempt only delays it.
+func (d *DB) ClaimWebhookDelivery(ctx context.Context, leaseUntil time.Time) (*domain.WebhookDelivery, error) {
+	var out domain.WebhookDelivery
+	err := d.Pool.QueryRow(ctx, `
+		WITH c AS (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhooks w ON w.id = d.webhook_id AND w.active
+			WHERE d.status = 'PENDING' AND d.next_attempt_at <= now()
+			ORDER BY d.next_attempt_at, d.event_id
+			LIMIT 1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, last_attempt_at = now(), next_attempt_at = $1
+		FROM c, webhooks w, outbox_events e
+		WHERE d.id = c.id AND w.id = d.webhook_id AND e.id = d.event_id
+		RETURNING `+webhookDeliveryColumns+`, w.url, w.secret, e.data
+	`, leaseUntil).Scan(&out.ID, &out.WebhookID, &out.Event.ID, &out.Event.Type, &out.Event.CreatedAt,
+		&out.Status, &out.Attempts, &out.NextAttemptAt, &out.LastAttemptAt, &out.LastStatusCode,
+		&out.LastError, &out.DeliveredAt, &out.CreatedAt,
+		&out.URL, &out.Secret, &out.Event.Data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CompleteWebhookDelivery records that the given attempt was accepted. It is
+// a no-op when the delivery was claimed again or redelivered meanwhile.
+func (d *DB) CompleteWebhookDelivery(ctx context.Context, id string, attempt, statusCode int) error {
+	_, err := d.Pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'DELIVERED', last_status_code = $3, last_error = NULL,
+		    next_attempt_at = NULL, delivered_at = now()
+		WHERE id = $1 AND attempts = $2 AND status = 'PENDING'
+	`, id, attempt, statusCode)
+	return err
+}
+
+// FailWebhookDelivery records a failed attempt. The delivery is due again at
+// nextAttemptAt, or DEAD when nextAttemptAt is nil. statusCode is nil when no
+// response was received.
+func (d *DB) FailWebhookDelivery(ctx context.Context, id string, attempt int, statusCode *int, reason string, nextAttemptAt *time.Time) error {
+	_, err := d.Pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = CASE WHEN $5::timestamptz IS NULL THEN 'DEAD' ELSE 'PENDING' END,
+		    last_status_code = $3, last_error = $4, next_attempt_at = $5
+		WHERE id = $1 AND attempts = $2 AND status = 'PENDING'
+	`, id, attempt, statusCode, reason, nextAttemptAt)
+	return err
+}
+
+// RedeliverWebhookDelivery queues a delivery again, with a fresh count of
+// attempts, whatever its state. It returns nil when the webhook has no such
+// delivery.
+func (d *DB) RedeliverWebhookDelivery(ctx context.Context, webhookID, id string) (*domain.WebhookDelivery, error) {
+	out, err := scanWebhookDelivery(d.Pool.QueryRow(ctx, `
+		UPDATE webhook_deliveries d
+		SET status = 'PENDING', attempts = 0, next_attempt_at = now(), delivered_at = NULL
+		FROM outbox_events e
+		WHERE d.id = $2 AND d.webhook_id = $1 AND e.id = d.event_id
+		RETURNING `+webhookDeliveryColumns, webhookID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListWebhookDeliveries returns a page of the delivery log of a webhook,
+// newest first, optionally in one status, and the total.
+func (d *DB) ListWebhookDeliveries(ctx context.Context, webhookID string, status *string, page, pageSize int) ([]domain.WebhookDelivery, int, error) {
+	var total int
+	if err := d.Pool.QueryRow(ctx, `
+		SELECT count(*) FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2::text IS NULL OR status = $2)
+	`, webhookID, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := d.Pool.Query(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON e.id = d.event_id
+		WHERE d.webhook_id = $1 AND ($2::text IS NULL OR d.status = $2)
+		ORDER BY d.created_at DESC, d.id
+		LIMIT $3 OFFSET $4
+	`, webhookID, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := []domain.WebhookDelivery{}
+	for rows.Next() {
+		dl, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, dl)
+	}
+	return out, total, rows.Err()
+}
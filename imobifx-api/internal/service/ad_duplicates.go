@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+)
+
+// rejectDuplicates is the duplicate check of a new ad: it fails when ad
+// looks like one already open at the same address, listing the matches so
+// the client can link to them or retry with force.
+func rejectDuplicates(ad domain.Ad) func(open []domain.Ad) ([]domain.Ad, error) {
+	return func(open []domain.Ad) ([]domain.Ad, error) {
+		if ids := duplicatesOf(ad, open); len(ids) > 0 {
+			return nil, duplicateAd(map[string]any{"duplicate_ids": ids})
+		}
+		return []domain.Ad{ad}, nil
+	}
+}
+
+// duplicatesOf returns the IDs of the ads among candidates that are probable
+// duplicates of ad.
+func duplicatesOf(ad domain.Ad, candidates []domain.Ad) []string {
+	ids := []string{}
+	for _, c := range candidates {
+		if domain.ProbableDuplicates(ad, c, domain.DuplicatePriceTolerance) {
+			ids = append(ids, c.ID)
+		}
+	}
+	return ids
+}
+
+func duplicateAd(details map[string]any) error {
+	return errors.New(http.StatusConflict, "DUPLICATE_AD", "Já existe um anúncio para este imóvel. Envie force=true para criar mesmo assim.", details)
+}
+
+// DuplicateClusters groups the open ads into sets of probable duplicates:
+// same type and normalized address, each ad within tolerance of the price of
+// another in the set. Ads without a match are left out.
+func (s *AdsService) DuplicateClusters(ctx context.Context, tolerance float64) (domain.DuplicateClustersResponse, error) {
+	ads, err := s.db.ListOpenAdsSharingCEP(ctx)
+	if err != nil {
+		return domain.DuplicateClustersResponse{}, err
+	}
+
+	out := domain.DuplicateClustersResponse{PriceTolerance: tolerance, Clusters: []domain.DuplicateCluster{}}
+	// ads come grouped by CEP and type; cluster each group on its own.
+	for start := 0; start < len(ads); {
+		end := start + 1
+		for end < len(ads) && ads[end].CEP == ads[start].CEP && ads[end].Type == ads[start].Type {
+			end++
+		}
+		out.Clusters = append(out.Clusters, clusterDuplicates(ads[start:end], tolerance)...)
+		start = end
+	}
+	return out, nil
+}
+
+// clusterDuplicates links every pair of probable duplicates and returns the
+// connected sets of two or more ads, keeping the input (age) order.
+func clusterDuplicates(ads []domain.Ad, tolerance float64) []domain.DuplicateCluster {
+	parent := make([]int, len(ads))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range ads {
+		for j := i + 1; j < len(ads); j++ {
+			if domain.ProbableDuplicates(ads[i], ads[j], tolerance) {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+
+	members := map[int][]int{}
+	var roots []int
+	for i := range ads {
+		r := root(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], i)
+	}
+
+	var out []domain.DuplicateCluster
+	for _, r := range roots {
+		if len(members[r]) < 2 {
+			continue
+		}
+		first := ads[members[r][0]]
+		c := domain.DuplicateCluster{
+			Type:       first.Type,
+			CEP:        first.CEP,
+			Street:     first.Street,
+			Number:     first.Number,
+			Complement: first.Complement,
+		}
+		for _, i := range members[r] {
+			c.Ads = append(c.Ads, domain.ToDuplicateAd(ads[i]))
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+func existingAd(id, typ, cep, street, number string, complement *string, price float64, age time.Duration) domain.Ad {
+	return domain.Ad{
+		ID: id, Type: typ, Status: domain.AdStatusActive, PriceBRL: price,
+		CEP: cep, Street: street, Number: &number, Complement: complement,
+		Neighborhood: "Tambaú", City: "João Pessoa", State: "PB",
+		CreatedAt: time.Now().Add(-age),
+	}
+}
+
+func TestAdsService_Create_RejectsDuplicates(t *testing.T) {
+	db := &fakeAdsRepo{openAds: []domain.Ad{
+		existingAd("a1", "SALE", "58039-000", "Av. Epitácio Pessoa", "1200", ptr("Apto 101"), 300000, time.Hour),
+	}}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	in := usecase.CreateAdInput{
+		Type: "SALE", PriceBRL: 320000, CEP: "58039000", Street: "avenida epitacio pessoa",
+		Number: ptr("1200"), Complement: ptr("ap. 101"), Neighborhood: "Tambaú", City: "João Pessoa", State: "PB",
+	}
+	_, err := svc.Create(context.Background(), in, nil)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 409, appErr.Status)
+	require.Equal(t, "DUPLICATE_AD", appErr.Code)
+	require.Equal(t, map[string]any{"duplicate_ids": []string{"a1"}}, appErr.Details)
+	require.False(t, db.createCalled)
+	require.Len(t, db.checked, 1)
+	require.Equal(t, "58039-000", db.checked[0].CEP)
+
+	in.Force = true
+	_, err = svc.Create(context.Background(), in, nil)
+	require.NoError(t, err)
+	require.True(t, db.createCalled)
+}
+
+func TestAdsService_Create_AcceptsDifferentProperties(t *testing.T) {
+	db := &fakeAdsRepo{openAds: []domain.Ad{
+		existingAd("a1", "SALE", "58039-000", "Av. Epitácio Pessoa", "1200", ptr("Apto 101"), 300000, time.Hour),
+	}}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	base := usecase.CreateAdInput{
+		Type: "SALE", PriceBRL: 300000, CEP: "58039-000", Street: "Av. Epitácio Pessoa",
+		Number: ptr("1200"), Complement: ptr("Apto 101"), Neighborhood: "Tambaú", City: "João Pessoa", State: "PB",
+	}
+
+	for name, change := range map[string]func(*usecase.CreateAdInput){
+		"other unit":        func(in *usecase.CreateAdInput) { in.Complement = ptr("Apto 102") },
+		"other number":      func(in *usecase.CreateAdInput) { in.Number = ptr("1210") },
+		"for rent":          func(in *usecase.CreateAdInput) { in.Type, in.PriceBRL = "RENT", 3000 },
+		"price far apart":   func(in *usecase.CreateAdInput) { in.PriceBRL = 340000 },
+		"no complement":     func(in *usecase.CreateAdInput) { in.Complement = nil },
+		"other postal code": func(in *usecase.CreateAdInput) { in.CEP = "58039-001" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			in := base
+			change(&in)
+			_, err := svc.Create(context.Background(), in, nil)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAdsService_DuplicateClusters(t *testing.T) {
+	db := &fakeAdsRepo{openAds: []domain.Ad{
+		// a1 ~ a3 ~ a4 chain within 10% (a1 and a4 alone do not); a2 has no
+		// number and a5 is another street.
+		existingAd("a1", "SALE", "58039-000", "Av. Epitácio Pessoa", "1200", nil, 300000, 3*time.Hour),
+		existingAd("a2", "SALE", "58039-000", "Avenida Epitacio Pessoa", "s/n", nil, 310000, 2*time.Hour),
+		existingAd("a3", "SALE", "58039-000", "AV EPITACIO PESSOA", "1200", nil, 320000, 2*time.Hour),
+		existingAd("a4", "SALE", "58039-000", "Av. Epitácio Pessoa", "1200", nil, 345000, time.Hour),
+		existingAd("a5", "SALE", "58039-000", "Rua Silvino Lopes", "1200", nil, 300000, time.Hour),
+		// Same address, prices too far apart.
+		existingAd("b1", "SALE", "58040-000", "Rua Bananeiras", "10", nil, 100000, 2*time.Hour),
+		existingAd("b2", "SALE", "58040-000", "Rua Bananeiras", "10", nil, 200000, time.Hour),
+	}}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	resp, err := svc.DuplicateClusters(context.Background(), domain.DuplicatePriceTolerance)
+	require.NoError(t, err)
+	require.Equal(t, 0.1, resp.PriceTolerance)
+	require.Len(t, resp.Clusters, 1)
+
+	c := resp.Clusters[0]
+	require.Equal(t, "Av. Epitácio Pessoa", c.Street)
+	var ids []string
+	for _, a := range c.Ads {
+		ids = append(ids, a.ID)
+	}
+	require.Equal(t, []string{"a1", "a3", "a4"}, ids)
+
+	resp, err = svc.DuplicateClusters(context.Background(), 0.6)
+	require.NoError(t, err)
+	require.Len(t, resp.Clusters, 2)
+}
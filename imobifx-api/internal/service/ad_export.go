@@ -0,0 +1,310 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/export"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/storage"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+const (
+	exportBatchSize = 500
+
+	// exportClaimTimeout is how long a job may stay RUNNING before another
+	// worker takes it over.
+	exportClaimTimeout = 30 * time.Minute
+
+	exportDownloadPath = "/api/ads/exports/"
+)
+
+// exportColumns are the columns of every export, in order; exportRow fills
+// them.
+var exportColumns = []string{
+	"id", "type", "status", "title", "description",
+	"price_brl", "price_usd", "previous_price_brl", "price_changed_at",
+	"cep", "street", "number", "complement", "neighborhood", "city", "state",
+	"latitude", "longitude", "distance_km",
+	"bedrooms", "bathrooms", "parking_spaces", "area_m2", "price_per_m2", "condo_fee_brl", "iptu_brl",
+	"image_url", "publish_at", "expires_at", "created_at", "updated_at",
+}
+
+// ExportService runs background exports of the ads matching a listing
+// filter. Files go to their own storage and are removed after retention.
+type ExportService struct {
+	db        ExportsRepository
+	ads       *AdsService
+	files     storage.Storage
+	retention time.Duration
+}
+
+func NewExportService(db ExportsRepository, ads *AdsService, files storage.Storage, retention time.Duration) *ExportService {
+	return &ExportService{db: db, ads: ads, files: files, retention: retention}
+}
+
+// Create queues an export of the ads matching in. Pagination fields are
+// ignored: the export holds every matching ad in the listing order.
+func (s *ExportService) Create(ctx context.Context, format string, in usecase.ListAdsInput) (domain.AdExportResponse, error) {
+	if !slices.Contains(export.Formats, format) {
+		return domain.AdExportResponse{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", map[string]string{
+			"format": "must be one of " + strings.Join(export.Formats, ", "),
+		})
+	}
+	if err := validation.ValidateListAdsInput(in); err != nil {
+		return domain.AdExportResponse{}, err
+	}
+	in.Page, in.PageSize, in.Cursor, in.WithTotal = 0, 0, nil, false
+
+	// Fail now rather than in the worker when the filters cannot be applied.
+	if _, _, err := s.ads.listFilter(ctx, in); err != nil {
+		return domain.AdExportResponse{}, err
+	}
+
+	filters, err := json.Marshal(in)
+	if err != nil {
+		return domain.AdExportResponse{}, err
+	}
+	e, err := s.db.CreateAdExport(ctx, format, filters)
+	if err != nil {
+		return domain.AdExportResponse{}, err
+	}
+	return s.response(e), nil
+}
+
+func (s *ExportService) Get(ctx context.Context, id string) (domain.AdExportResponse, error) {
+	e, err := s.get(ctx, id)
+	if err != nil {
+		return domain.AdExportResponse{}, err
+	}
+	return s.response(e), nil
+}
+
+// Open returns the file of a finished export. The caller closes it.
+func (s *ExportService) Open(ctx context.Context, id string) (io.ReadCloser, domain.AdExport, error) {
+	e, err := s.get(ctx, id)
+	if err != nil {
+		return nil, domain.AdExport{}, err
+	}
+	if e.Status != domain.AdExportDone {
+		return nil, domain.AdExport{}, exportNotReady(id, e.Status)
+	}
+	f, err := s.files.Get(ctx, e.FileKey)
+	if stderrors.Is(err, storage.ErrNotFound) {
+		return nil, domain.AdExport{}, exportNotFound(id)
+	}
+	if err != nil {
+		return nil, domain.AdExport{}, err
+	}
+	return f, e, nil
+}
+
+func (s *ExportService) get(ctx context.Context, id string) (domain.AdExport, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.AdExport{}, exportNotFound(id)
+	}
+	e, err := s.db.GetAdExport(ctx, id)
+	if err != nil {
+		return domain.AdExport{}, err
+	}
+	if e == nil {
+		return domain.AdExport{}, exportNotFound(id)
+	}
+	return *e, nil
+}
+
+func (s *ExportService) response(e domain.AdExport) domain.AdExportResponse {
+	return domain.ToAdExportResponse(e, exportDownloadPath+e.ID+"/download")
+}
+
+// ProcessPendingExports runs the queued exports one at a time, then removes
+// the expired ones. It is run periodically by the export worker.
+func (s *ExportService) ProcessPendingExports(ctx context.Context) error {
+	for ctx.Err() == nil {
+		e, err := s.db.ClaimAdExport(ctx, time.Now().UTC().Add(-exportClaimTimeout))
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			return s.purgeExpired(ctx)
+		}
+		if err := s.run(ctx, *e); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// run writes one claimed export. Filters that no longer apply mark the job
+// FAILED; only infrastructure errors are returned, leaving the job to be
+// claimed again.
+func (s *ExportService) run(ctx context.Context, e domain.AdExport) error {
+	var in usecase.ListAdsInput
+	if err := json.Unmarshal(e.Filters, &in); err != nil {
+		return s.fail(ctx, e, err)
+	}
+	f, quote, err := s.ads.listFilter(ctx, in)
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) {
+		return s.fail(ctx, e, appErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "ad-export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	rows, err := s.write(ctx, tmp, e.Format, f, quote)
+	if stderrors.Is(err, export.ErrTooManyRows) {
+		return s.fail(ctx, e, err)
+	}
+	if err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := e.ID + "." + e.Format
+	if err := s.files.Put(ctx, key, tmp, size, export.ContentType(e.Format)); err != nil {
+		return err
+	}
+	ok, err := s.db.CompleteAdExport(ctx, e.ID, *e.ClaimedAt, key, rows, size, quote, time.Now().UTC().Add(s.retention))
+	if err != nil || !ok {
+		_ = s.files.Delete(ctx, key)
+		return err
+	}
+	slog.Info("ad_export_done",
+		slog.String("export_id", e.ID),
+		slog.String("format", e.Format),
+		slog.Int("rows", rows),
+		slog.Int64("size_bytes", size),
+	)
+	return nil
+}
+
+// write streams every ad matching f to w, a page at a time in listing order.
+func (s *ExportService) write(ctx context.Context, w io.Writer, format string, f repo.AdsFilter, quote *domain.Quote) (int, error) {
+	buf := bufio.NewWriter(w)
+	out, err := export.New(format, buf, exportColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	var after *repo.AdsCursor
+	for {
+		ads, next, err := s.ads.db.ListAdsAfter(ctx, f, after, exportBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, a := range ads {
+			if err := out.Write(s.exportRow(a, quote, f.Near)); err != nil {
+				return 0, err
+			}
+			rows++
+		}
+		if next == nil {
+			break
+		}
+		after = next
+	}
+
+	if err := out.Close(); err != nil {
+		return 0, err
+	}
+	return rows, buf.Flush()
+}
+
+func (s *ExportService) exportRow(a domain.Ad, quote *domain.Quote, near *domain.GeoPoint) []any {
+	item := domain.ToAdItemWithQuote(a, quote, s.ads.images)
+
+	var lat, lng, distance any
+	if a.Location != nil {
+		lat, lng = a.Location.Lat, a.Location.Lng
+		if near != nil {
+			distance = math.Round(domain.DistanceKM(*near, *a.Location)*100) / 100
+		}
+	}
+
+	return []any{
+		item.ID, item.Type, item.Status, item.Title, item.Description,
+		item.PriceBRL, value(item.PriceUSD), value(item.PreviousPriceBRL), value(item.PriceChangedAt),
+		item.Address.CEP, item.Address.Street, value(item.Address.Number), value(item.Address.Complement),
+		item.Address.Neighborhood, item.Address.City, item.Address.State,
+		lat, lng, distance,
+		value(item.Bedrooms), value(item.Bathrooms), value(item.Parking), value(item.AreaM2),
+		value(item.PricePerM2), value(item.CondoFeeBRL), value(item.IPTUBRL),
+		value(item.ImageURL), value(item.PublishAt), value(item.ExpiresAt), item.CreatedAt, item.UpdatedAt,
+	}
+}
+
+func (s *ExportService) fail(ctx context.Context, e domain.AdExport, cause error) error {
+	slog.Warn("ad_export_failed",
+		slog.String("export_id", e.ID),
+		slog.String("error", cause.Error()),
+	)
+	_, err := s.db.FailAdExport(ctx, e.ID, *e.ClaimedAt, cause.Error(), time.Now().UTC().Add(s.retention))
+	return err
+}
+
+// purgeExpired removes jobs past their retention and their files.
+func (s *ExportService) purgeExpired(ctx context.Context) error {
+	expired, err := s.db.DeleteExpiredAdExports(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for _, e := range expired {
+		if e.FileKey == "" {
+			continue
+		}
+		if err := s.files.Delete(ctx, e.FileKey); err != nil {
+			slog.Warn("remove_export_file_failed",
+				slog.String("export_id", e.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return nil
+}
+
+// value dereferences an optional field; nil stays an empty cell.
+func value[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func exportNotFound(id string) error {
+	return errors.New(http.StatusNotFound, "EXPORT_NOT_FOUND", "Exportação não encontrada.", map[string]string{"id": id})
+}
+
+func exportNotReady(id, status string) error {
+	return errors.New(http.StatusConflict, "EXPORT_NOT_READY", "A exportação não está pronta para download.", map[string]string{"id": id, "status": status})
+}
@@ -0,0 +1,215 @@
+package service_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// fakeExportsRepo keeps the jobs in memory with the same state rules as the
+// ad_exports queries.
+type fakeExportsRepo struct {
+	jobs []*domain.AdExport
+}
+
+func (f *fakeExportsRepo) CreateAdExport(ctx context.Context, format string, filters []byte) (domain.AdExport, error) {
+	e := &domain.AdExport{
+		ID:        "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e1" + string(rune('0'+len(f.jobs))),
+		Format:    format,
+		Status:    domain.AdExportPending,
+		Filters:   filters,
+		CreatedAt: time.Now().UTC(),
+	}
+	f.jobs = append(f.jobs, e)
+	return *e, nil
+}
+
+func (f *fakeExportsRepo) GetAdExport(ctx context.Context, id string) (*domain.AdExport, error) {
+	for _, e := range f.jobs {
+		if e.ID == id {
+			cp := *e
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeExportsRepo) ClaimAdExport(ctx context.Context, staleBefore time.Time) (*domain.AdExport, error) {
+	for _, e := range f.jobs {
+		if e.Status == domain.AdExportPending {
+			now := time.Now().UTC()
+			e.Status, e.ClaimedAt = domain.AdExportRunning, &now
+			cp := *e
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeExportsRepo) CompleteAdExport(ctx context.Context, id string, claimedAt time.Time, fileKey string, rows int, size int64, quote *domain.Quote, expiresAt time.Time) (bool, error) {
+	for _, e := range f.jobs {
+		if e.ID == id && e.Status == domain.AdExportRunning {
+			e.Status, e.FileKey, e.Rows, e.SizeBytes, e.Quote, e.ExpiresAt = domain.AdExportDone, fileKey, &rows, &size, quote, &expiresAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeExportsRepo) FailAdExport(ctx context.Context, id string, claimedAt time.Time, reason string, expiresAt time.Time) (bool, error) {
+	for _, e := range f.jobs {
+		if e.ID == id && e.Status == domain.AdExportRunning {
+			e.Status, e.Error, e.ExpiresAt = domain.AdExportFailed, &reason, &expiresAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeExportsRepo) DeleteExpiredAdExports(ctx context.Context, now time.Time) ([]domain.AdExport, error) {
+	var kept []*domain.AdExport
+	var out []domain.AdExport
+	for _, e := range f.jobs {
+		if e.ExpiresAt != nil && e.ExpiresAt.Before(now) {
+			out = append(out, *e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	f.jobs = kept
+	return out, nil
+}
+
+func TestExportService_Create_Validates(t *testing.T) {
+	ads := service.NewAdsService(&fakeAdsRepo{}, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	exports := &fakeExportsRepo{}
+	svc := service.NewExportService(exports, ads, localStore(t, t.TempDir()), time.Hour)
+
+	var appErr *errors.AppError
+	_, err := svc.Create(context.Background(), "pdf", usecase.ListAdsInput{Page: 1, PageSize: 10})
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+
+	_, err = svc.Create(context.Background(), "csv", usecase.ListAdsInput{Page: 1, PageSize: 10, MaxPriceUSD: ptr(100000.0)})
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "QUOTE_NOT_FOUND", appErr.Code)
+	require.Empty(t, exports.jobs)
+
+	job, err := svc.Create(context.Background(), "xlsx", usecase.ListAdsInput{Page: 3, PageSize: 10, Cursor: ptr(""), City: ptr("João Pessoa")})
+	require.NoError(t, err)
+	require.Equal(t, domain.AdExportPending, job.Status)
+	require.Nil(t, job.DownloadURL)
+
+	var stored usecase.ListAdsInput
+	require.NoError(t, json.Unmarshal(exports.jobs[0].Filters, &stored))
+	require.Equal(t, "João Pessoa", *stored.City)
+	require.Zero(t, stored.Page)
+	require.Nil(t, stored.Cursor)
+}
+
+func TestExportService_ProcessPendingExports_WritesEveryPage(t *testing.T) {
+	quote := &domain.Quote{BrlToUsd: 0.2, EffectiveAt: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)}
+	var sorts []string
+	db := &fakeAdsRepo{
+		quoteFn: func(ctx context.Context) (*domain.Quote, error) { return quote, nil },
+		afterFn: func(ctx context.Context, f repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error) {
+			sorts = append(sorts, f.Sort)
+			require.Equal(t, 500, limit)
+			require.Equal(t, domain.AdStatusActive, *f.Status)
+			require.Equal(t, 250000.02, *f.MaxPrice) // highest BRL shown as US$ 50k at 0.2
+			if after == nil {
+				return []domain.Ad{{ID: "a1", Type: "SALE", PriceBRL: 200000, City: "João Pessoa", Bedrooms: ptr(3)}},
+					&repo.AdsCursor{Sort: f.Sort, ID: "a1"}, nil
+			}
+			require.Equal(t, "a1", after.ID)
+			return []domain.Ad{{ID: "a2", Type: "SALE", PriceBRL: 150000, City: "Cabedelo"}}, nil, nil
+		},
+	}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	exports := &fakeExportsRepo{}
+	dir := t.TempDir()
+	svc := service.NewExportService(exports, ads, localStore(t, dir), time.Hour)
+
+	job, err := svc.Create(context.Background(), "csv", usecase.ListAdsInput{Page: 1, PageSize: 10, Sort: domain.AdSortPriceDesc, MaxPriceUSD: ptr(50000.0)})
+	require.NoError(t, err)
+
+	_, _, err = svc.Open(context.Background(), job.ID)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "EXPORT_NOT_READY", appErr.Code)
+
+	require.NoError(t, svc.ProcessPendingExports(context.Background()))
+	require.Equal(t, []string{domain.AdSortPriceDesc, domain.AdSortPriceDesc}, sorts)
+
+	got, err := svc.Get(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.AdExportDone, got.Status)
+	require.Equal(t, 2, *got.Rows)
+	require.Equal(t, 0.2, got.QuoteUsed.BrlToUsd)
+	require.Equal(t, "/api/ads/exports/"+job.ID+"/download", *got.DownloadURL)
+
+	f, _, err := svc.Open(context.Background(), job.ID)
+	require.NoError(t, err)
+	records, err := csv.NewReader(f).ReadAll()
+	f.Close()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	col := map[string]int{}
+	for i, name := range records[0] {
+		col[name] = i
+	}
+	require.Equal(t, "a1", records[1][col["id"]])
+	require.Equal(t, "200000", records[1][col["price_brl"]])
+	require.Equal(t, "40000", records[1][col["price_usd"]])
+	require.Equal(t, "3", records[1][col["bedrooms"]])
+	require.Equal(t, "João Pessoa", records[1][col["city"]])
+	require.Equal(t, "", records[2][col["bedrooms"]])
+
+	// Once past retention the job and its file are gone.
+	past := time.Now().Add(-time.Minute)
+	exports.jobs[0].ExpiresAt = &past
+	require.NoError(t, svc.ProcessPendingExports(context.Background()))
+	_, err = svc.Get(context.Background(), job.ID)
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "EXPORT_NOT_FOUND", appErr.Code)
+	_, err = os.Stat(filepath.Join(dir, job.ID+".csv"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestExportService_ProcessPendingExports_FailsWhenQuoteIsGone(t *testing.T) {
+	quote := &domain.Quote{BrlToUsd: 0.2}
+	db := &fakeAdsRepo{quoteFn: func(ctx context.Context) (*domain.Quote, error) { return quote, nil }}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	exports := &fakeExportsRepo{}
+	svc := service.NewExportService(exports, ads, localStore(t, t.TempDir()), time.Hour)
+
+	job, err := svc.Create(context.Background(), "jsonl", usecase.ListAdsInput{Page: 1, PageSize: 10, MinPriceUSD: ptr(1000.0)})
+	require.NoError(t, err)
+
+	quote = nil
+	require.NoError(t, svc.ProcessPendingExports(context.Background()))
+
+	got, err := svc.Get(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.AdExportFailed, got.Status)
+	require.Contains(t, *got.Error, "QUOTE_NOT_FOUND")
+	require.Nil(t, got.DownloadURL)
+
+	_, _, err = svc.Open(context.Background(), job.ID)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 409, appErr.Status)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/feeds"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/storage"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// FeedService publishes the portal feeds of the live active ads. Feeds are
+// rendered periodically into private storage and served from there, so the
+// portals crawling them never wait on a full scan of the ads.
+type FeedService struct {
+	ads        *AdsService
+	files      storage.Storage
+	header     feeds.Header
+	publicURL  string
+	listingURL string
+	generators map[string]feeds.Generator
+
+	// renders shares a render of a feed among everyone asking for it at once:
+	// concurrent first hits and the worker.
+	renders singleflight.Group
+}
+
+// NewFeedService serves one feed per generator. publicURL is prepended to
+// relative image URLs (images served by the API itself); listingURL is the
+// public page of an ad with {id} in place of its ID, or empty.
+func NewFeedService(ads *AdsService, files storage.Storage, header feeds.Header, publicURL, listingURL string, generators ...feeds.Generator) *FeedService {
+	s := &FeedService{
+		ads:        ads,
+		files:      files,
+		header:     header,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		listingURL: listingURL,
+		generators: map[string]feeds.Generator{},
+	}
+	for _, g := range generators {
+		s.generators[g.Name()] = g
+	}
+	return s
+}
+
+// Refresh renders every feed. It is run periodically by the feed worker.
+func (s *FeedService) Refresh(ctx context.Context) error {
+	names := make([]string, 0, len(s.generators))
+	for name := range s.generators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := s.render(ctx, s.generators[name]); err != nil {
+			errs = append(errs, fmt.Errorf("feed %s: %w", name, err))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// Open returns the latest rendered feed and its content type. The caller
+// closes it. A feed not rendered yet is rendered on the spot, once however
+// many crawlers are waiting for it.
+func (s *FeedService) Open(ctx context.Context, name string) (io.ReadCloser, string, error) {
+	g, ok := s.generators[name]
+	if !ok {
+		return nil, "", feedNotFound(name)
+	}
+
+	f, err := s.files.Get(ctx, feedKey(name))
+	if stderrors.Is(err, storage.ErrNotFound) {
+		if err := s.render(ctx, g); err != nil {
+			slog.Error("feed_render_failed", slog.String("feed", name), slog.String("error", err.Error()))
+			return nil, "", feedUnavailable(name)
+		}
+		f, err = s.files.Get(ctx, feedKey(name))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return f, g.ContentType(), nil
+}
+
+// render runs refresh, joining the render of the same feed already under way
+// if any. The render outlives the caller that started it, as others may be
+// waiting on it.
+func (s *FeedService) render(ctx context.Context, g feeds.Generator) error {
+	_, err, _ := s.renders.Do(g.Name(), func() (any, error) {
+		return nil, s.refresh(context.WithoutCancel(ctx), g)
+	})
+	return err
+}
+
+// refresh renders a feed to a temporary file and publishes it only when it
+// passes the portal schema; otherwise the previous feed stays in place.
+func (s *FeedService) refresh(ctx context.Context, g feeds.Generator) error {
+	f, _, err := s.ads.listFilter(ctx, usecase.ListAdsInput{Sort: domain.AdSortOldest})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "ad-feed-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	header := s.header
+	header.PublishedAt = time.Now().UTC()
+	buf := bufio.NewWriter(tmp)
+	res, err := g.Write(buf, header, func(fn func(feeds.Listing) error) error {
+		return s.walk(ctx, f, fn)
+	})
+	if err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := g.Validate(bufio.NewReader(tmp)); err != nil {
+		return fmt.Errorf("invalid feed, keeping the previous one: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := s.files.Put(ctx, feedKey(g.Name()), tmp, size, g.ContentType()); err != nil {
+		return err
+	}
+
+	for id, reason := range res.Skipped {
+		slog.Warn("feed_listing_skipped",
+			slog.String("feed", g.Name()),
+			slog.String("ad_id", id),
+			slog.String("reason", reason),
+		)
+	}
+	slog.Info("feed_published",
+		slog.String("feed", g.Name()),
+		slog.Int("listings", res.Listings),
+		slog.Int("skipped", len(res.Skipped)),
+		slog.Int64("size_bytes", size),
+	)
+	return nil
+}
+
+// walk calls fn for every ad matching f, a page at a time.
+func (s *FeedService) walk(ctx context.Context, f repo.AdsFilter, fn func(feeds.Listing) error) error {
+	var after *repo.AdsCursor
+	for {
+		ads, next, err := s.ads.db.ListAdsAfter(ctx, f, after, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, a := range ads {
+			if err := fn(s.listing(a)); err != nil {
+				return err
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		after = next
+	}
+}
+
+func (s *FeedService) listing(a domain.Ad) feeds.Listing {
+	item := domain.ToAdItem(a, s.ads.images)
+	l := feeds.Listing{Ad: a}
+	if s.listingURL != "" {
+		l.URL = strings.ReplaceAll(s.listingURL, "{id}", a.ID)
+	}
+
+	// Cover first, then the gallery order; images still processing have no
+	// URL yet and wait for the next refresh.
+	images := slices.Clone(item.Images)
+	slices.SortStableFunc(images, func(x, y domain.AdImageItem) int {
+		if x.IsCover != y.IsCover {
+			if x.IsCover {
+				return -1
+			}
+			return 1
+		}
+		return x.Position - y.Position
+	})
+	for _, img := range images {
+		if img.URL != nil {
+			l.ImageURLs = append(l.ImageURLs, s.absolute(*img.URL))
+		}
+	}
+	if len(l.ImageURLs) == 0 && item.ImageURL != nil {
+		l.ImageURLs = append(l.ImageURLs, s.absolute(*item.ImageURL))
+	}
+	return l
+}
+
+func (s *FeedService) absolute(u string) string {
+	if strings.HasPrefix(u, "/") {
+		return s.publicURL + u
+	}
+	return u
+}
+
+func feedKey(name string) string {
+	return "feed-" + name + ".xml"
+}
+
+func feedNotFound(name string) error {
+	return errors.New(http.StatusNotFound, "FEED_NOT_FOUND", "Feed não encontrado.", map[string]string{"feed": name})
+}
+
+func feedUnavailable(name string) error {
+	return errors.New(http.StatusServiceUnavailable, "FEED_UNAVAILABLE", "Feed indisponível no momento.", map[string]string{"feed": name})
+}
@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/feeds"
+	"github.com/josinaldojr/imobifx-api/internal/feeds/vrsync"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+var feedHeader = feeds.Header{Provider: "ImobiFX", Email: "anuncios@example.com"}
+
+// stubGenerator writes the IDs of the listings it is given and fails
+// validation on demand.
+type stubGenerator struct {
+	invalid bool
+}
+
+func (g *stubGenerator) Name() string        { return "stub" }
+func (g *stubGenerator) ContentType() string { return "text/plain" }
+
+func (g *stubGenerator) Write(w io.Writer, h feeds.Header, listings feeds.Listings) (feeds.Result, error) {
+	var res feeds.Result
+	err := listings(func(l feeds.Listing) error {
+		res.Listings++
+		_, err := io.WriteString(w, l.Ad.ID+" "+strings.Join(l.ImageURLs, ",")+"\n")
+		return err
+	})
+	return res, err
+}
+
+func (g *stubGenerator) Validate(r io.Reader) error {
+	if g.invalid {
+		return stderrors.New("does not conform")
+	}
+	return nil
+}
+
+func readFeed(t *testing.T, svc *service.FeedService, name string) (string, string) {
+	t.Helper()
+	f, contentType, err := svc.Open(context.Background(), name)
+	require.NoError(t, err)
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(b), contentType
+}
+
+func TestFeedService_Refresh_PublishesLiveAds(t *testing.T) {
+	db := &fakeAdsRepo{
+		afterFn: func(ctx context.Context, f repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error) {
+			require.Equal(t, domain.AdStatusActive, *f.Status)
+			require.True(t, f.OnlyLive)
+			require.Equal(t, domain.AdSortOldest, f.Sort)
+			if after == nil {
+				return []domain.Ad{{
+					ID: "a1", Type: "SALE", PriceBRL: 300000, City: "João Pessoa", State: "PB",
+					Images: []domain.AdImage{
+						{ID: "i1", Status: domain.AdImageReady, Variants: map[string]string{"large": "i1_large.jpg"}, Position: 0},
+						{ID: "i2", Status: domain.AdImageReady, Variants: map[string]string{"large": "i2_large.jpg"}, Position: 1, IsCover: true},
+						{ID: "i3", Status: domain.AdImagePending, Position: 2},
+					},
+				}}, &repo.AdsCursor{Sort: f.Sort, ID: "a1"}, nil
+			}
+			return []domain.Ad{{ID: "a2", Type: "RENT", PriceBRL: 1800, City: "Natal", State: "XX"}}, nil, nil
+		},
+	}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	g, err := vrsync.New(vrsync.Options{UsageType: "Residential", PropertyType: "Residential / Apartment"})
+	require.NoError(t, err)
+	svc := service.NewFeedService(ads, localStore(t, t.TempDir()), feedHeader, "https://api.example.com/", "https://imobifx.example.com/anuncios/{id}", g)
+
+	require.NoError(t, svc.Refresh(context.Background()))
+
+	body, contentType := readFeed(t, svc, "vrsync")
+	require.Equal(t, "application/xml; charset=utf-8", contentType)
+	require.NoError(t, g.Validate(strings.NewReader(body)))
+	require.Contains(t, body, "<ListingID>a1</ListingID>")
+	require.Contains(t, body, "<DetailViewUrl>https://imobifx.example.com/anuncios/a1</DetailViewUrl>")
+	require.Contains(t, body, `<Item medium="image" primary="true">https://api.example.com/static/images/i2_large.jpg</Item>`)
+	require.Contains(t, body, `<Item medium="image">https://api.example.com/static/images/i1_large.jpg</Item>`)
+	require.Equal(t, 2, strings.Count(body, "<Item "))
+	require.NotContains(t, body, "<ListingID>a2</ListingID>") // unknown state, skipped
+}
+
+func TestFeedService_Open_RendersMissingFeedAndRejectsUnknown(t *testing.T) {
+	db := &fakeAdsRepo{
+		afterFn: func(ctx context.Context, f repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error) {
+			return []domain.Ad{{ID: "a1"}}, nil, nil
+		},
+	}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	g := &stubGenerator{}
+	svc := service.NewFeedService(ads, localStore(t, t.TempDir()), feedHeader, "", "", g)
+
+	body, contentType := readFeed(t, svc, "stub")
+	require.Equal(t, "a1 \n", body)
+	require.Equal(t, "text/plain", contentType)
+
+	var appErr *errors.AppError
+	_, _, err := svc.Open(context.Background(), "olx")
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "FEED_NOT_FOUND", appErr.Code)
+}
+
+func TestFeedService_Refresh_KeepsPreviousFeedWhenInvalid(t *testing.T) {
+	id := "a1"
+	db := &fakeAdsRepo{
+		afterFn: func(ctx context.Context, f repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error) {
+			return []domain.Ad{{ID: id}}, nil, nil
+		},
+	}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	g := &stubGenerator{}
+	svc := service.NewFeedService(ads, localStore(t, t.TempDir()), feedHeader, "", "", g)
+	require.NoError(t, svc.Refresh(context.Background()))
+
+	id, g.invalid = "a2", true
+	require.ErrorContains(t, svc.Refresh(context.Background()), "does not conform")
+
+	body, _ := readFeed(t, svc, "stub")
+	require.Equal(t, "a1 \n", body)
+
+	// With no previous feed to fall back on, the feed is unavailable.
+	empty := service.NewFeedService(ads, localStore(t, t.TempDir()), feedHeader, "", "", g)
+	var appErr *errors.AppError
+	_, _, err := empty.Open(context.Background(), "stub")
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 503, appErr.Status)
+}
+
+func TestFeedService_Open_RendersOnceForConcurrentFirstHits(t *testing.T) {
+	var renders atomic.Int32
+	release := make(chan struct{})
+	db := &fakeAdsRepo{
+		afterFn: func(ctx context.Context, f repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error) {
+			renders.Add(1)
+			<-release
+			return []domain.Ad{{ID: "a1"}}, nil, nil
+		},
+	}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	svc := service.NewFeedService(ads, localStore(t, t.TempDir()), feedHeader, "", "", &stubGenerator{})
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 10)
+	for i := range bodies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bodies[i], _ = readFeed(t, svc, "stub")
+		}()
+	}
+	// Let every crawler reach the render before it completes.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.EqualValues(t, 1, renders.Load())
+	for _, body := range bodies {
+		require.Equal(t, "a1 \n", body)
+	}
+}
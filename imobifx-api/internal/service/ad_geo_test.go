@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/integrations/geocode"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+var testGeocoder = geocode.NewLocal(map[string]domain.GeoPoint{
+	"58000": {Lat: -7.1153, Lng: -34.8641},
+	"58038": {Lat: -7.1195, Lng: -34.8229},
+})
+
+func TestAdsService_Create_GeocodesAddress(t *testing.T) {
+	db := &fakeAdsRepo{}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), testGeocoder, 5*1024*1024, maxImages, retention, ttl)
+
+	in := usecase.CreateAdInput{
+		Type: "SALE", PriceBRL: 250000, CEP: "58038-000",
+		Street: "Av. Cabo Branco", Neighborhood: "Cabo Branco", City: "João Pessoa", State: "PB",
+	}
+	_, err := svc.Create(context.Background(), in, nil)
+	require.NoError(t, err)
+	require.Equal(t, &domain.GeoPoint{Lat: -7.1195, Lng: -34.8229}, db.lastCreated.Location)
+
+	in.Latitude, in.Longitude = ptr(-7.2), ptr(-34.9)
+	_, err = svc.Create(context.Background(), in, nil)
+	require.NoError(t, err)
+	require.Equal(t, &domain.GeoPoint{Lat: -7.2, Lng: -34.9}, db.lastCreated.Location, "explicit coordinates win")
+
+	in.Latitude, in.Longitude = nil, nil
+	in.CEP = "01310-100"
+	_, err = svc.Create(context.Background(), in, nil)
+	require.NoError(t, err, "a geocoding miss must not block the write")
+	require.Nil(t, db.lastCreated.Location)
+}
+
+func TestAdsService_Patch_RegeocodesOnAddressChange(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	updatedAt := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+	db := existingAdRepo(id, updatedAt)
+	current, _ := db.getFn(context.Background(), id)
+	current.Location = &domain.GeoPoint{Lat: -7.5, Lng: -35}
+	db.getFn = func(ctx context.Context, got string) (*domain.Ad, error) {
+		ad := *current
+		return &ad, nil
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), testGeocoder, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Patch(context.Background(), id, usecase.PatchAdInput{PriceBRL: ptr(240000.0)}, domain.AdETag(updatedAt))
+	require.NoError(t, err)
+	require.Equal(t, &domain.GeoPoint{Lat: -7.5, Lng: -35}, db.lastUpdated.Location, "unchanged address keeps the location")
+
+	_, err = svc.Patch(context.Background(), id, usecase.PatchAdInput{CEP: ptr("58038000")}, domain.AdETag(updatedAt))
+	require.NoError(t, err)
+	require.Equal(t, &domain.GeoPoint{Lat: -7.1195, Lng: -34.8229}, db.lastUpdated.Location)
+
+	_, err = svc.Patch(context.Background(), id, usecase.PatchAdInput{Latitude: ptr(-7.3), Longitude: ptr(-34.7)}, domain.AdETag(updatedAt))
+	require.NoError(t, err)
+	require.Equal(t, &domain.GeoPoint{Lat: -7.3, Lng: -34.7}, db.lastUpdated.Location)
+}
+
+func TestAdsService_List_GeoFiltersAndDistance(t *testing.T) {
+	var got repo.AdsFilter
+	db := &fakeAdsRepo{
+		listFn: func(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error) {
+			got = f
+			return []domain.Ad{
+				{ID: "near", Type: "SALE", Location: &domain.GeoPoint{Lat: -7.1195, Lng: -34.8229}},
+				{ID: "unlocated", Type: "SALE"},
+			}, 2, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	resp, err := svc.List(context.Background(), usecase.ListAdsInput{
+		Page: 1, PageSize: 10,
+		Lat: ptr(-7.1153), Lng: ptr(-34.8641), RadiusKM: ptr(10.0),
+		Sort:    domain.AdSortDistance,
+		BBox:    &[4]float64{-35, -7.2, -34.7, -7},
+		Polygon: [][2]float64{{-35, -7.2}, {-34.7, -7.2}, {-34.8, -7}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &domain.GeoPoint{Lat: -7.1153, Lng: -34.8641}, got.Near)
+	require.Equal(t, 10.0, *got.RadiusKM)
+	require.Equal(t, &domain.GeoBox{MinLng: -35, MinLat: -7.2, MaxLng: -34.7, MaxLat: -7}, got.BBox)
+	require.Equal(t, domain.GeoPoint{Lng: -34.8, Lat: -7}, got.Polygon[2])
+
+	require.Len(t, resp.Items, 2)
+	require.NotNil(t, resp.Items[0].DistanceKM)
+	require.InDelta(t, 4.57, *resp.Items[0].DistanceKM, 0.01)
+	require.Nil(t, resp.Items[1].DistanceKM)
+}
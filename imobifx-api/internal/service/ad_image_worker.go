@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/imaging"
+	"github.com/josinaldojr/imobifx-api/internal/storage"
+)
+
+const (
+	imageBatchSize = 10
+
+	// imageClaimTimeout is how long an image may stay PROCESSING before
+	// another worker takes it over.
+	imageClaimTimeout = 10 * time.Minute
+)
+
+// ProcessPendingImages renders the size variants of uploaded images. Each
+// call drains the queue in batches; it is run periodically by the image
+// worker so uploads return without waiting for the resize.
+func (s *AdsService) ProcessPendingImages(ctx context.Context) error {
+	for {
+		imgs, err := s.db.ClaimPendingImages(ctx, imageBatchSize, time.Now().UTC().Add(-imageClaimTimeout))
+		if err != nil {
+			return err
+		}
+		for _, img := range imgs {
+			if err := s.processImage(ctx, img); err != nil {
+				return err
+			}
+		}
+		if len(imgs) < imageBatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// processImage renders one claimed image. Files that cannot be decoded mark
+// the image FAILED; only infrastructure errors are returned.
+func (s *AdsService) processImage(ctx context.Context, img domain.AdImage) error {
+	originals := s.uploads
+	if img.Legacy {
+		originals = s.images
+	}
+	src, err := originals.Get(ctx, img.Path)
+	if stderrors.Is(err, storage.ErrNotFound) {
+		return s.failImage(ctx, img, err)
+	}
+	if err != nil {
+		return err
+	}
+	rendered, err := imaging.Render(src)
+	src.Close()
+	if err != nil {
+		return s.failImage(ctx, img, err)
+	}
+
+	base := uuid.NewString()
+	variants := make(map[string]string, len(rendered))
+	for size, b := range rendered {
+		name := base + "_" + size + ".jpg"
+		if err := s.images.Put(ctx, name, bytes.NewReader(b), int64(len(b)), "image/jpeg"); err != nil {
+			s.discardVariants(ctx, variants)
+			return err
+		}
+		variants[size] = name
+	}
+
+	ok, err := s.db.CompleteAdImage(ctx, img.ID, variants[domain.ImageSizeLarge], variants)
+	if err != nil || !ok {
+		s.discardVariants(ctx, variants)
+		return err
+	}
+
+	if err := originals.Delete(ctx, img.Path); err != nil {
+		slog.Warn("remove_staged_image_failed",
+			slog.String("image_id", img.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+	slog.Info("ad_image_processed",
+		slog.String("ad_id", img.AdID),
+		slog.String("image_id", img.ID),
+	)
+	return nil
+}
+
+func (s *AdsService) failImage(ctx context.Context, img domain.AdImage, cause error) error {
+	slog.Warn("ad_image_failed",
+		slog.String("ad_id", img.AdID),
+		slog.String("image_id", img.ID),
+		slog.String("error", cause.Error()),
+	)
+	if _, err := s.db.FailAdImage(ctx, img.ID, cause.Error()); err != nil {
+		return err
+	}
+	return s.uploads.Delete(ctx, img.Path)
+}
+
+func (s *AdsService) discardVariants(ctx context.Context, variants map[string]string) {
+	for _, name := range variants {
+		_ = s.images.Delete(ctx, name)
+	}
+}
@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+func TestAdsService_ProcessPendingImages_RendersVariants(t *testing.T) {
+	imagesDir, uploadsDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(uploadsDir, "up.jpg"), testJPEG(t), 0o644))
+
+	db := &fakeAdsRepo{pendingImages: []domain.AdImage{
+		{ID: "img-1", AdID: "ad-1", Path: "up.jpg", Status: domain.AdImageProcessing},
+	}}
+	svc := service.NewAdsService(db, localStore(t, imagesDir), localStore(t, uploadsDir), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	require.NoError(t, svc.ProcessPendingImages(context.Background()))
+
+	variants := db.completed["img-1"]
+	require.Len(t, variants, len(domain.ImageSizes))
+	for _, size := range domain.ImageSizes {
+		_, err := os.Stat(filepath.Join(imagesDir, variants[size]))
+		require.NoError(t, err, size)
+	}
+
+	_, err := os.Stat(filepath.Join(uploadsDir, "up.jpg"))
+	require.True(t, os.IsNotExist(err), "staged original must be removed")
+}
+
+func TestAdsService_ProcessPendingImages_LegacyOriginalFromImages(t *testing.T) {
+	imagesDir, uploadsDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(imagesDir, "old.jpg"), testJPEG(t), 0o644))
+
+	db := &fakeAdsRepo{pendingImages: []domain.AdImage{
+		{ID: "img-1", AdID: "ad-1", Path: "old.jpg", Status: domain.AdImageProcessing, Legacy: true},
+	}}
+	svc := service.NewAdsService(db, localStore(t, imagesDir), localStore(t, uploadsDir), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	require.NoError(t, svc.ProcessPendingImages(context.Background()))
+	require.Len(t, db.completed["img-1"], len(domain.ImageSizes))
+
+	_, err := os.Stat(filepath.Join(imagesDir, "old.jpg"))
+	require.True(t, os.IsNotExist(err), "served original must be removed")
+}
+
+func TestAdsService_ProcessPendingImages_UndecodableFails(t *testing.T) {
+	imagesDir, uploadsDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(uploadsDir, "bad.jpg"), []byte("garbage"), 0o644))
+
+	db := &fakeAdsRepo{pendingImages: []domain.AdImage{
+		{ID: "img-1", AdID: "ad-1", Path: "bad.jpg", Status: domain.AdImageProcessing},
+		{ID: "img-2", AdID: "ad-1", Path: "missing.jpg", Status: domain.AdImageProcessing},
+	}}
+	svc := service.NewAdsService(db, localStore(t, imagesDir), localStore(t, uploadsDir), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	require.NoError(t, svc.ProcessPendingImages(context.Background()))
+	require.Contains(t, db.failed, "img-1")
+	require.Contains(t, db.failed, "img-2")
+	require.Empty(t, db.completed)
+
+	entries, err := os.ReadDir(imagesDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+// AddImages appends images to an ad. If the ad has no cover yet, the first
+// new image becomes the cover.
+func (s *AdsService) AddImages(ctx context.Context, id string, files []*multipart.FileHeader) (domain.Ad, error) {
+	ad, err := s.getLive(ctx, id)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if len(files) == 0 {
+		return domain.Ad{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", map[string]string{"images": "required"})
+	}
+	if err := validation.ValidateImages(files, len(ad.Images), s.maxImages, s.maxImageSize); err != nil {
+		return domain.Ad{}, err
+	}
+	if err := checkImages(files); err != nil {
+		return domain.Ad{}, err
+	}
+
+	keys, err := s.stageImages(ctx, files)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+
+	updated, err := s.db.AddAdImages(ctx, id, keys)
+	if err != nil || updated == nil {
+		s.discardStaged(ctx, keys)
+	}
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if updated == nil {
+		return domain.Ad{}, adNotFound(id)
+	}
+	return *updated, nil
+}
+
+// RemoveImage deletes one image from the ad and its files.
+func (s *AdsService) RemoveImage(ctx context.Context, id, imageID string) (domain.Ad, error) {
+	ad, err := s.getLive(ctx, id)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	img, ok := findAdImage(ad, imageID)
+	if !ok {
+		return domain.Ad{}, adImageNotFound(imageID)
+	}
+
+	updated, err := s.db.DeleteAdImage(ctx, id, imageID)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if updated == nil {
+		return domain.Ad{}, adVersionConflict(id)
+	}
+
+	if err := s.removeImageFiles(ctx, img); err != nil {
+		slog.Warn("remove_image_failed",
+			slog.String("ad_id", id),
+			slog.String("image_path", img.Path),
+			slog.String("error", err.Error()),
+		)
+	}
+	return *updated, nil
+}
+
+// ReorderImages sets the display order. imageIDs must list every image of the
+// ad exactly once.
+func (s *AdsService) ReorderImages(ctx context.Context, id string, imageIDs []string) (domain.Ad, error) {
+	ad, err := s.getLive(ctx, id)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+
+	seen := make(map[string]bool, len(imageIDs))
+	for _, imgID := range imageIDs {
+		if _, ok := findAdImage(ad, imgID); !ok || seen[imgID] {
+			return domain.Ad{}, imageOrderMismatch()
+		}
+		seen[imgID] = true
+	}
+	if len(imageIDs) != len(ad.Images) {
+		return domain.Ad{}, imageOrderMismatch()
+	}
+
+	updated, err := s.db.ReorderAdImages(ctx, id, imageIDs)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if updated == nil {
+		return domain.Ad{}, adVersionConflict(id)
+	}
+	return *updated, nil
+}
+
+// SetCover makes one of the ad images its cover, which is what image_url
+// points at.
+func (s *AdsService) SetCover(ctx context.Context, id, imageID string) (domain.Ad, error) {
+	ad, err := s.getLive(ctx, id)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if _, ok := findAdImage(ad, imageID); !ok {
+		return domain.Ad{}, adImageNotFound(imageID)
+	}
+
+	updated, err := s.db.SetAdCover(ctx, id, imageID)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if updated == nil {
+		return domain.Ad{}, adVersionConflict(id)
+	}
+	return *updated, nil
+}
+
+func findAdImage(ad domain.Ad, imageID string) (domain.AdImage, bool) {
+	for _, img := range ad.Images {
+		if img.ID == imageID {
+			return img, true
+		}
+	}
+	return domain.AdImage{}, false
+}
+
+func adImageNotFound(imageID string) error {
+	return errors.New(http.StatusNotFound, "AD_IMAGE_NOT_FOUND", "Imagem não encontrada.", map[string]string{"image_id": imageID})
+}
+
+func imageOrderMismatch() error {
+	return errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", map[string]string{"image_ids": "must list every image of the ad exactly once"})
+}
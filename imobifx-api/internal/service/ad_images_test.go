@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/storage"
+)
+
+func adWithImages(paths ...string) *fakeAdsRepo {
+	images := make([]domain.AdImage, len(paths))
+	for i, p := range paths {
+		images[i] = domain.AdImage{ID: "img-" + p, Path: p, Position: i, IsCover: i == 0}
+	}
+	return &fakeAdsRepo{
+		getFn: func(ctx context.Context, id string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: "SALE", Status: domain.AdStatusActive, Images: images}, nil
+		},
+	}
+}
+
+func TestAdsService_AddImages_StagesFilesAndCallsRepo(t *testing.T) {
+	tmp := t.TempDir()
+	db := adWithImages("a.jpg")
+	var got []string
+	db.imagesFn = func(ctx context.Context, op, adID string, args []string) (*domain.Ad, error) {
+		require.Equal(t, "add", op)
+		got = args
+		return &domain.Ad{ID: adID}, nil
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, tmp), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	files := []*multipart.FileHeader{
+		makeMultipartFileHeader(t, "images", "b.png", "image/png", testJPEG(t)),
+		makeMultipartFileHeader(t, "images", "c.webp", "image/webp", testJPEG(t)),
+	}
+	_, err := svc.AddImages(context.Background(), statusAdID, files)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for _, p := range got {
+		_, statErr := os.Stat(filepath.Join(tmp, p))
+		require.NoError(t, statErr)
+	}
+}
+
+func TestAdsService_AddImages_CountsExisting(t *testing.T) {
+	db := adWithImages("a.jpg", "b.jpg")
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	files := []*multipart.FileHeader{
+		makeMultipartFileHeader(t, "images", "c.jpg", "image/jpeg", testJPEG(t)),
+		makeMultipartFileHeader(t, "images", "d.jpg", "image/jpeg", testJPEG(t)),
+	}
+	_, err := svc.AddImages(context.Background(), statusAdID, files)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "TOO_MANY_IMAGES", appErr.Code)
+}
+
+func TestAdsService_RemoveImage_DeletesFile(t *testing.T) {
+	tmp := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "a.jpg"), []byte("x"), 0o644))
+	db := adWithImages("a.jpg", "b.jpg")
+	svc := service.NewAdsService(db, localStore(t, tmp), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.RemoveImage(context.Background(), statusAdID, "img-a.jpg")
+	require.NoError(t, err)
+
+	_, statErr := os.Stat(filepath.Join(tmp, "a.jpg"))
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestAdsService_RemoveImage_Unknown(t *testing.T) {
+	svc := service.NewAdsService(adWithImages("a.jpg"), localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.RemoveImage(context.Background(), statusAdID, "img-x")
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "AD_IMAGE_NOT_FOUND", appErr.Code)
+}
+
+func TestAdsService_ReorderImages_RequiresPermutation(t *testing.T) {
+	db := adWithImages("a.jpg", "b.jpg")
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	cases := [][]string{
+		{"img-a.jpg"},
+		{"img-a.jpg", "img-a.jpg"},
+		{"img-a.jpg", "img-x"},
+	}
+	for _, ids := range cases {
+		_, err := svc.ReorderImages(context.Background(), statusAdID, ids)
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr, "%v", ids)
+		require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+	}
+
+	_, err := svc.ReorderImages(context.Background(), statusAdID, []string{"img-b.jpg", "img-a.jpg"})
+	require.NoError(t, err)
+}
+
+func TestAdsService_SetCover_ConcurrentChange_Conflict(t *testing.T) {
+	db := adWithImages("a.jpg", "b.jpg")
+	db.imagesFn = func(ctx context.Context, op, adID string, args []string) (*domain.Ad, error) {
+		return nil, nil
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.SetCover(context.Background(), statusAdID, "img-b.jpg")
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 412, appErr.Status)
+}
+
+func TestAdsService_Item_BuildsURLsThroughStorage(t *testing.T) {
+	images, err := storage.NewLocal(t.TempDir(), "https://cdn.example.com/img")
+	require.NoError(t, err)
+	svc := service.NewAdsService(&fakeAdsRepo{}, images, localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	cover := "x_large.jpg"
+	item := svc.Item(domain.Ad{
+		ID:        "ad-1",
+		ImagePath: &cover,
+		Images: []domain.AdImage{{
+			ID:       "img-1",
+			Path:     cover,
+			Status:   domain.AdImageReady,
+			Variants: map[string]string{domain.ImageSizeThumb: "x_thumb.jpg", domain.ImageSizeLarge: cover},
+			IsCover:  true,
+		}},
+	})
+
+	require.Equal(t, "https://cdn.example.com/img/x_large.jpg", *item.ImageURL)
+	require.Equal(t, "https://cdn.example.com/img/x_thumb.jpg", item.ImageURLs[domain.ImageSizeThumb])
+	require.Equal(t, "https://cdn.example.com/img/x_large.jpg", *item.Images[0].URL)
+}
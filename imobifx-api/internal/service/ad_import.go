@@ -0,0 +1,175 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	stderrors "errors"
+	"net/http"
+	"slices"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+// ImportService creates ads in bulk from parsed CSV or JSONL rows. Each row
+// goes through the same validation and defaults as a single create.
+type ImportService struct {
+	ads     *AdsService
+	address AddressLookup
+}
+
+// NewImportService builds the import service. address backs the optional CEP
+// enrichment; nil disables it.
+func NewImportService(ads *AdsService, address AddressLookup) *ImportService {
+	return &ImportService{ads: ads, address: address}
+}
+
+// Import validates every row and stores the valid ones in a single
+// transaction. Unless Force is set, rows that look like duplicates of an
+// open ad or of an earlier row fail like invalid ones. With AllOrNothing,
+// any failed row aborts the whole import.
+// Row failures are reported, not returned; the error is for failures of the
+// import itself.
+func (s *ImportService) Import(ctx context.Context, rows []usecase.ImportAdRow, opts usecase.ImportAdsOptions) (domain.AdImportReport, error) {
+	report := domain.AdImportReport{
+		Mode:    domain.AdImportModePartial,
+		Total:   len(rows),
+		Created: []domain.AdImportResult{},
+		Errors:  []domain.AdImportError{},
+	}
+	if opts.AllOrNothing {
+		report.Mode = domain.AdImportModeAllOrNothing
+	}
+
+	addresses := map[string]*domain.Address{}
+	ads := make([]domain.Ad, 0, len(rows))
+	lines := make([]int, 0, len(rows))
+	for _, row := range rows {
+		if row.Err != nil {
+			report.Errors = append(report.Errors, importError(row.Line, row.Err))
+			continue
+		}
+
+		in := row.Input
+		if opts.EnrichCEP {
+			s.enrich(ctx, &in, addresses)
+		}
+		if err := validation.ValidateCreateAdInput(&in); err != nil {
+			report.Errors = append(report.Errors, importError(row.Line, err))
+			continue
+		}
+
+		ad := s.ads.newAd(in, nil)
+		if opts.Geocode && ad.Location == nil {
+			ad.Location = s.ads.geocode(ctx, ad)
+		}
+		ads = append(ads, ad)
+		lines = append(lines, row.Line)
+	}
+
+	report.Failed = len(report.Errors)
+	if len(ads) == 0 || (opts.AllOrNothing && report.Failed > 0) {
+		return report, nil
+	}
+
+	var keep func(open []domain.Ad) ([]domain.Ad, error)
+	if !opts.Force {
+		keep = func(open []domain.Ad) ([]domain.Ad, error) {
+			ads, lines = dropDuplicates(ads, lines, open, &report)
+			report.Failed = len(report.Errors)
+			if opts.AllOrNothing && report.Failed > 0 {
+				return nil, nil
+			}
+			return ads, nil
+		}
+	}
+	created, err := s.ads.db.CreateAds(ctx, ads, keep)
+	if err != nil {
+		return domain.AdImportReport{}, err
+	}
+	if len(created) == 0 {
+		return report, nil
+	}
+	for i, ad := range created {
+		report.Created = append(report.Created, domain.AdImportResult{Line: lines[i], ID: ad.ID})
+	}
+	report.Imported = len(created)
+	report.Committed = true
+	return report, nil
+}
+
+// dropDuplicates reports the rows that are probable duplicates of an open
+// ad or of an earlier row and returns the others, with their lines.
+func dropDuplicates(ads []domain.Ad, lines []int, open []domain.Ad, report *domain.AdImportReport) ([]domain.Ad, []int) {
+	type accepted struct {
+		ad   domain.Ad
+		line int
+	}
+	rows := map[string][]accepted{}
+	keptAds, keptLines := make([]domain.Ad, 0, len(ads)), make([]int, 0, len(lines))
+	for i, ad := range ads {
+		key := ad.CEP + "|" + ad.Type
+		ids := duplicatesOf(ad, open)
+		dupLines := []int{}
+		for _, r := range rows[key] {
+			if domain.ProbableDuplicates(ad, r.ad, domain.DuplicatePriceTolerance) {
+				dupLines = append(dupLines, r.line)
+			}
+		}
+		if len(ids) > 0 || len(dupLines) > 0 {
+			report.Errors = append(report.Errors, importError(lines[i], duplicateAd(map[string]any{
+				"duplicate_ids":   ids,
+				"duplicate_lines": dupLines,
+			})))
+			continue
+		}
+		rows[key] = append(rows[key], accepted{ad: ad, line: lines[i]})
+		keptAds = append(keptAds, ad)
+		keptLines = append(keptLines, lines[i])
+	}
+	slices.SortStableFunc(report.Errors, func(a, b domain.AdImportError) int { return cmp.Compare(a.Line, b.Line) })
+	return keptAds, keptLines
+}
+
+// enrich fills the empty address fields of in from its CEP. Lookups are
+// cached per CEP, failures included; a failed lookup leaves the row as sent
+// and validation reports whatever is still missing.
+func (s *ImportService) enrich(ctx context.Context, in *usecase.CreateAdInput, cache map[string]*domain.Address) {
+	if s.address == nil {
+		return
+	}
+	cep8, ok := validation.NormalizeCEP(in.CEP)
+	if !ok {
+		return
+	}
+	addr, seen := cache[cep8]
+	if !seen {
+		if a, err := s.address.Lookup(ctx, cep8); err == nil {
+			addr = &a
+		}
+		cache[cep8] = addr
+	}
+	if addr == nil {
+		return
+	}
+
+	fill := func(dst *string, v string) {
+		if *dst == "" {
+			*dst = v
+		}
+	}
+	fill(&in.Street, addr.Street)
+	fill(&in.Neighborhood, addr.Neighborhood)
+	fill(&in.City, addr.City)
+	fill(&in.State, addr.State)
+}
+
+func importError(line int, err error) domain.AdImportError {
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) {
+		appErr = errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", map[string]string{"row": err.Error()})
+	}
+	return domain.AdImportError{Line: line, Code: appErr.Code, Message: appErr.Message, Details: appErr.Details}
+}
@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+type fakeAddressLookup struct {
+	calls int
+	fn    func(ctx context.Context, cep string) (domain.Address, error)
+}
+
+func (f *fakeAddressLookup) Lookup(ctx context.Context, cep string) (domain.Address, error) {
+	f.calls++
+	return f.fn(ctx, cep)
+}
+
+func importRows() []usecase.ImportAdRow {
+	return []usecase.ImportAdRow{
+		{Line: 2, Input: usecase.CreateAdInput{Type: "SALE", PriceBRL: 250000, CEP: "58000000", Street: "Rua A", Neighborhood: "Centro", City: "João Pessoa", State: "PB"}},
+		{Line: 3, Input: usecase.CreateAdInput{Type: "LEASE", PriceBRL: 2000, CEP: "58000000", Street: "Rua B", Neighborhood: "Centro", City: "João Pessoa", State: "PB"}},
+		{Line: 4, Err: stderrors.New("wrong number of fields")},
+		{Line: 5, Input: usecase.CreateAdInput{Type: "RENT", PriceBRL: 3000, CEP: "58000-001", Street: "Rua C", Neighborhood: "Tambaú", City: "João Pessoa", State: "PB"}},
+	}
+}
+
+func TestImportService_Partial_CommitsValidRows(t *testing.T) {
+	db := &fakeAdsRepo{}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	svc := service.NewImportService(ads, nil)
+
+	report, err := svc.Import(context.Background(), importRows(), usecase.ImportAdsOptions{})
+	require.NoError(t, err)
+	require.True(t, report.Committed)
+	require.Equal(t, domain.AdImportModePartial, report.Mode)
+	require.Equal(t, 4, report.Total)
+	require.Equal(t, 2, report.Imported)
+	require.Equal(t, 2, report.Failed)
+
+	require.Equal(t, []domain.AdImportResult{{Line: 2, ID: "ad-1"}, {Line: 5, ID: "ad-2"}}, report.Created)
+	require.Len(t, db.bulkCreated, 2)
+	require.Equal(t, "58000-000", db.bulkCreated[0].CEP)
+	require.Equal(t, domain.AdStatusActive, db.bulkCreated[1].Status)
+
+	require.Len(t, report.Errors, 2)
+	require.Equal(t, 3, report.Errors[0].Line)
+	require.Equal(t, "VALIDATION_ERROR", report.Errors[0].Code)
+	require.Contains(t, report.Errors[0].Details, "type")
+	require.Equal(t, 4, report.Errors[1].Line)
+	require.Equal(t, map[string]string{"row": "wrong number of fields"}, report.Errors[1].Details)
+}
+
+func TestImportService_AllOrNothing_StoresNothingOnFailure(t *testing.T) {
+	db := &fakeAdsRepo{}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	svc := service.NewImportService(ads, nil)
+
+	report, err := svc.Import(context.Background(), importRows(), usecase.ImportAdsOptions{AllOrNothing: true})
+	require.NoError(t, err)
+	require.False(t, report.Committed)
+	require.Equal(t, domain.AdImportModeAllOrNothing, report.Mode)
+	require.Zero(t, report.Imported)
+	require.Equal(t, 2, report.Failed)
+	require.Empty(t, report.Created)
+	require.Nil(t, db.bulkCreated)
+
+	rows := importRows()
+	report, err = svc.Import(context.Background(), []usecase.ImportAdRow{rows[0], rows[3]}, usecase.ImportAdsOptions{AllOrNothing: true})
+	require.NoError(t, err)
+	require.True(t, report.Committed)
+	require.Equal(t, 2, report.Imported)
+}
+
+func TestImportService_EnrichCEP_FillsMissingFieldsOncePerCEP(t *testing.T) {
+	db := &fakeAdsRepo{}
+	lookup := &fakeAddressLookup{fn: func(ctx context.Context, cep string) (domain.Address, error) {
+		require.Equal(t, "58038000", cep)
+		return domain.Address{CEP: "58038-000", Street: "Av. Cabo Branco", Neighborhood: "Cabo Branco", City: "João Pessoa", State: "PB"}, nil
+	}}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	svc := service.NewImportService(ads, lookup)
+
+	rows := []usecase.ImportAdRow{
+		{Line: 2, Input: usecase.CreateAdInput{Type: "SALE", PriceBRL: 900000, CEP: "58038-000", Number: ptr("100")}},
+		{Line: 3, Input: usecase.CreateAdInput{Type: "RENT", PriceBRL: 4500, CEP: "58038000", Street: "Rua Própria"}},
+	}
+	report, err := svc.Import(context.Background(), rows, usecase.ImportAdsOptions{EnrichCEP: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Imported)
+	require.Equal(t, 1, lookup.calls)
+
+	require.Equal(t, "Av. Cabo Branco", db.bulkCreated[0].Street)
+	require.Equal(t, "Cabo Branco", db.bulkCreated[0].Neighborhood)
+	require.Equal(t, "Rua Própria", db.bulkCreated[1].Street)
+	require.Equal(t, "PB", db.bulkCreated[1].State)
+
+	lookup.calls = 0
+	report, err = svc.Import(context.Background(), rows[:1], usecase.ImportAdsOptions{})
+	require.NoError(t, err)
+	require.Zero(t, lookup.calls)
+	require.Equal(t, 1, report.Failed)
+}
+
+func TestImportService_ReportsDuplicateRowsUnlessForced(t *testing.T) {
+	db := &fakeAdsRepo{openAds: []domain.Ad{
+		existingAd("a1", "SALE", "58039-000", "Av. Epitácio Pessoa", "1200", nil, 300000, time.Hour),
+	}}
+	ads := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	svc := service.NewImportService(ads, nil)
+	row := func(line int, street, number string, price float64) usecase.ImportAdRow {
+		return usecase.ImportAdRow{Line: line, Input: usecase.CreateAdInput{
+			Type: "SALE", PriceBRL: price, CEP: "58039000", Street: street, Number: &number,
+			Neighborhood: "Tambaú", City: "João Pessoa", State: "PB",
+		}}
+	}
+	rows := []usecase.ImportAdRow{
+		row(2, "AV EPITACIO PESSOA", "1200", 310000),
+		row(3, "Rua Silvino Lopes", "40", 500000),
+		{Line: 4, Err: stderrors.New("wrong number of fields")},
+		row(5, "R. Silvino Lopes", "40", 510000),
+	}
+
+	report, err := svc.Import(context.Background(), rows, usecase.ImportAdsOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []domain.AdImportResult{{Line: 3, ID: "ad-1"}}, report.Created)
+	require.Equal(t, 3, report.Failed)
+	require.Len(t, report.Errors, 3)
+	require.Equal(t, 2, report.Errors[0].Line)
+	require.Equal(t, "DUPLICATE_AD", report.Errors[0].Code)
+	require.Equal(t, map[string]any{"duplicate_ids": []string{"a1"}, "duplicate_lines": []int{}}, report.Errors[0].Details)
+	require.Equal(t, 4, report.Errors[1].Line)
+	require.Equal(t, 5, report.Errors[2].Line)
+	require.Equal(t, map[string]any{"duplicate_ids": []string{}, "duplicate_lines": []int{3}}, report.Errors[2].Details)
+	require.Len(t, db.checked, 3)
+
+	db.checked = nil
+	report, err = svc.Import(context.Background(), rows, usecase.ImportAdsOptions{Force: true})
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Imported)
+	require.Equal(t, 1, report.Failed)
+	require.Empty(t, db.checked)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+)
+
+// PriceHistory lists the prices of a live ad, oldest first, converted to USD
+// at the quote effective at each change.
+func (s *AdsService) PriceHistory(ctx context.Context, id string) (domain.AdPriceHistoryResponse, error) {
+	ad, err := s.getLive(ctx, id)
+	if err != nil {
+		return domain.AdPriceHistoryResponse{}, err
+	}
+
+	prices, err := s.db.ListAdPriceHistory(ctx, ad.ID)
+	if err != nil {
+		return domain.AdPriceHistoryResponse{}, err
+	}
+
+	resp := domain.AdPriceHistoryResponse{AdID: ad.ID, Items: make([]domain.AdPriceChange, 0, len(prices))}
+	for _, p := range prices {
+		resp.Items = append(resp.Items, domain.ToAdPriceChange(p))
+	}
+	return resp, nil
+}
@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+func TestAdsService_PriceHistory_ConvertsAtEachQuote(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	db := existingAdRepo(id, time.Now().UTC())
+	db.prices = []domain.AdPrice{
+		{PriceBRL: 300000, ChangedAt: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{PriceBRL: 280000, ChangedAt: time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), Quote: &domain.Quote{BrlToUsd: 0.2}},
+		{PriceBRL: 250000, ChangedAt: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), Quote: &domain.Quote{BrlToUsd: 0.18}},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	resp, err := svc.PriceHistory(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, id, resp.AdID)
+	require.Len(t, resp.Items, 3)
+	require.Nil(t, resp.Items[0].PriceUSD)
+	require.Nil(t, resp.Items[0].QuoteUsed)
+	require.Equal(t, 56000.0, *resp.Items[1].PriceUSD)
+	require.Equal(t, 45000.0, *resp.Items[2].PriceUSD)
+	require.Equal(t, 0.18, resp.Items[2].QuoteUsed.BrlToUsd)
+
+	_, err = svc.PriceHistory(context.Background(), "not-a-uuid")
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "AD_NOT_FOUND", appErr.Code)
+}
+
+func TestAdsService_Get_PriceDrop(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	changedAt := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	db := &fakeAdsRepo{
+		getFn: func(ctx context.Context, got string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: "SALE", PriceBRL: 270000, PreviousPriceBRL: ptr(300000.0), PriceChangedAt: &changedAt}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	resp, err := svc.Get(context.Background(), id)
+	require.NoError(t, err)
+	require.True(t, resp.PriceDrop)
+	require.Equal(t, 10.0, *resp.PriceDropPct)
+	require.Equal(t, 300000.0, *resp.PreviousPriceBRL)
+
+	db.getFn = func(ctx context.Context, got string) (*domain.Ad, error) {
+		return &domain.Ad{ID: id, Type: "SALE", PriceBRL: 320000, PreviousPriceBRL: ptr(300000.0), PriceChangedAt: &changedAt}, nil
+	}
+	resp, err = svc.Get(context.Background(), id)
+	require.NoError(t, err)
+	require.False(t, resp.PriceDrop)
+	require.Nil(t, resp.PriceDropPct)
+}
+
+func TestAdsService_List_PassesPriceReducedSince(t *testing.T) {
+	var got repo.AdsFilter
+	db := &fakeAdsRepo{
+		listFn: func(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error) {
+			got = f
+			return nil, 0, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, PriceReducedSince: &since})
+	require.NoError(t, err)
+	require.Equal(t, since, *got.PriceReducedSince)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+)
+
+// Renew extends the expiry of an ad. Without expiresAt the ad gets the default
+// TTL counted from now, or from its current expiry if that is still ahead;
+// an expiresAt before the current expiry is rejected, renewing never
+// shortens it. Expired ads go back to ACTIVE.
+func (s *AdsService) Renew(ctx context.Context, id string, expiresAt *time.Time) (domain.Ad, error) {
+	ad, err := s.getLive(ctx, id)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+
+	switch ad.Status {
+	case domain.AdStatusSold, domain.AdStatusRented:
+		return domain.Ad{}, errors.New(http.StatusConflict, "AD_NOT_RENEWABLE", "Anúncios vendidos ou alugados não podem ser renovados.", map[string]string{"status": ad.Status})
+	}
+
+	now := time.Now().UTC()
+	var exp time.Time
+	switch {
+	case expiresAt != nil:
+		exp = expiresAt.UTC()
+	case s.defaultTTL > 0:
+		exp = latest(now, ad.ExpiresAt).Add(s.defaultTTL)
+	default:
+		return domain.Ad{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", map[string]string{"expires_at": "required"})
+	}
+	if !exp.After(latest(now, ad.PublishAt)) {
+		return domain.Ad{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", map[string]string{"expires_at": "must be in the future and after publish_at"})
+	}
+	if ad.ExpiresAt != nil && exp.Before(*ad.ExpiresAt) {
+		return domain.Ad{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", map[string]string{"expires_at": "must not be before the current expires_at"})
+	}
+
+	to := ad.Status
+	if to == domain.AdStatusExpired {
+		to = domain.AdStatusActive
+		if ad.PublishAt != nil && ad.PublishAt.After(now) {
+			to = domain.AdStatusDraft
+		}
+	}
+
+	renewed, err := s.db.RenewAd(ctx, id, ad.Status, to, exp)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if renewed == nil {
+		return domain.Ad{}, adVersionConflict(id)
+	}
+	return *renewed, nil
+}
+
+// RunSchedule publishes ads whose publish_at is due and expires ads whose
+// expires_at passed. It is meant to be called periodically.
+func (s *AdsService) RunSchedule(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	activated, err := s.db.ActivateScheduledAds(ctx, now)
+	if err != nil {
+		return err
+	}
+	expired, err := s.db.ExpireAds(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	if activated > 0 || expired > 0 {
+		slog.Info("ads_schedule",
+			slog.Int64("activated", activated),
+			slog.Int64("expired", expired),
+		)
+	}
+	return nil
+}
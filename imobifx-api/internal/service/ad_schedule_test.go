@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+func scheduleInput() usecase.CreateAdInput {
+	return usecase.CreateAdInput{
+		Type:         "SALE",
+		PriceBRL:     250000,
+		CEP:          "58000000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "João Pessoa",
+		State:        "PB",
+	}
+}
+
+func TestAdsService_Create_DefaultExpiry(t *testing.T) {
+	db := &fakeAdsRepo{}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Create(context.Background(), scheduleInput(), nil)
+	require.NoError(t, err)
+
+	require.Equal(t, domain.AdStatusActive, db.lastCreated.Status)
+	require.Nil(t, db.lastCreated.PublishAt)
+	require.NotNil(t, db.lastCreated.ExpiresAt)
+	require.WithinDuration(t, time.Now().Add(ttl), *db.lastCreated.ExpiresAt, time.Minute)
+}
+
+func TestAdsService_Create_FuturePublishAt_IsScheduledDraft(t *testing.T) {
+	db := &fakeAdsRepo{}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	publishAt := time.Now().UTC().Add(48 * time.Hour)
+	in := scheduleInput()
+	in.PublishAt = &publishAt
+
+	_, err := svc.Create(context.Background(), in, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, domain.AdStatusDraft, db.lastCreated.Status)
+	require.NotNil(t, db.lastCreated.ExpiresAt)
+	require.WithinDuration(t, publishAt.Add(ttl), *db.lastCreated.ExpiresAt, time.Second)
+}
+
+func TestAdsService_Create_ExpiresBeforePublish_Invalid(t *testing.T) {
+	db := &fakeAdsRepo{}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	publishAt := time.Now().UTC().Add(48 * time.Hour)
+	expiresAt := publishAt.Add(-time.Hour)
+	in := scheduleInput()
+	in.PublishAt = &publishAt
+	in.ExpiresAt = &expiresAt
+
+	_, err := svc.Create(context.Background(), in, nil)
+	require.Error(t, err)
+	require.False(t, db.createCalled)
+}
+
+func TestAdsService_Renew_Expired_Reactivates(t *testing.T) {
+	expiredAt := time.Now().UTC().Add(-time.Hour)
+	var from, to string
+	var exp time.Time
+	db := &fakeAdsRepo{
+		getFn: func(ctx context.Context, id string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: "SALE", Status: domain.AdStatusExpired, ExpiresAt: &expiredAt}, nil
+		},
+		renewFn: func(ctx context.Context, id, f, tt string, expiresAt time.Time) (*domain.Ad, error) {
+			from, to, exp = f, tt, expiresAt
+			return &domain.Ad{ID: id, Status: tt, ExpiresAt: &expiresAt}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	got, err := svc.Renew(context.Background(), statusAdID, nil)
+	require.NoError(t, err)
+	require.Equal(t, domain.AdStatusExpired, from)
+	require.Equal(t, domain.AdStatusActive, to)
+	require.Equal(t, domain.AdStatusActive, got.Status)
+	require.WithinDuration(t, time.Now().Add(ttl), exp, time.Minute)
+}
+
+func TestAdsService_Renew_Active_ExtendsFromCurrentExpiry(t *testing.T) {
+	current := time.Now().UTC().Add(10 * 24 * time.Hour)
+	var exp time.Time
+	db := &fakeAdsRepo{
+		getFn: func(ctx context.Context, id string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: "RENT", Status: domain.AdStatusActive, ExpiresAt: &current}, nil
+		},
+		renewFn: func(ctx context.Context, id, f, tt string, expiresAt time.Time) (*domain.Ad, error) {
+			exp = expiresAt
+			return &domain.Ad{ID: id, Status: tt, ExpiresAt: &expiresAt}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Renew(context.Background(), statusAdID, nil)
+	require.NoError(t, err)
+	require.WithinDuration(t, current.Add(ttl), exp, time.Second)
+}
+
+func TestAdsService_Renew_Sold_Conflict(t *testing.T) {
+	svc := service.NewAdsService(adWithStatus("SALE", domain.AdStatusSold), localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Renew(context.Background(), statusAdID, nil)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 409, appErr.Status)
+	require.Equal(t, "AD_NOT_RENEWABLE", appErr.Code)
+}
+
+func TestAdsService_Renew_PastExpiresAt_Invalid(t *testing.T) {
+	svc := service.NewAdsService(adWithStatus("SALE", domain.AdStatusActive), localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	past := time.Now().Add(-time.Hour)
+	_, err := svc.Renew(context.Background(), statusAdID, &past)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+}
+
+func TestAdsService_Renew_EarlierThanCurrentExpiry_Invalid(t *testing.T) {
+	current := time.Now().UTC().Add(30 * 24 * time.Hour)
+	db := &fakeAdsRepo{
+		getFn: func(ctx context.Context, id string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: "RENT", Status: domain.AdStatusActive, ExpiresAt: &current}, nil
+		},
+		renewFn: func(ctx context.Context, id, f, tt string, expiresAt time.Time) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Status: tt, ExpiresAt: &expiresAt}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	sooner := current.Add(-24 * time.Hour)
+	_, err := svc.Renew(context.Background(), statusAdID, &sooner)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+	require.Contains(t, appErr.Details, "expires_at")
+
+	later := current.Add(24 * time.Hour)
+	renewed, err := svc.Renew(context.Background(), statusAdID, &later)
+	require.NoError(t, err)
+	require.Equal(t, later, *renewed.ExpiresAt)
+}
+
+func TestAdsService_RunSchedule_ActivatesAndExpires(t *testing.T) {
+	db := &fakeAdsRepo{}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	require.NoError(t, svc.RunSchedule(context.Background()))
+	require.WithinDuration(t, time.Now(), db.activatedAt, time.Minute)
+	require.WithinDuration(t, time.Now(), db.expiredAt, time.Minute)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+// Stats summarizes the prices of the ads matching in, with the listing
+// defaults (active and live ads only). Pagination and sort are ignored. USD
+// figures use the current quote and are absent without one.
+func (s *AdsService) Stats(ctx context.Context, in usecase.ListAdsInput) (domain.AdStatsResponse, error) {
+	if err := validation.ValidateListAdsInput(in); err != nil {
+		return domain.AdStatsResponse{}, err
+	}
+
+	f, quote, err := s.listFilter(ctx, in)
+	if err != nil {
+		return domain.AdStatsResponse{}, err
+	}
+	stats, err := s.db.AdPriceStats(ctx, f)
+	if err != nil {
+		return domain.AdStatsResponse{}, err
+	}
+
+	resp := domain.AdStatsResponse{
+		QuoteUsed:      domain.ToQuoteUsed(quote),
+		ByType:         []domain.AdStatsGroup{},
+		ByState:        []domain.AdStatsGroup{},
+		ByCity:         []domain.AdStatsGroup{},
+		ByNeighborhood: []domain.AdStatsGroup{},
+	}
+	for _, st := range stats {
+		g := domain.ToAdStatsGroup(st, quote)
+		switch st.Level {
+		case domain.AdStatsByType:
+			resp.ByType = append(resp.ByType, g)
+		case domain.AdStatsByState:
+			resp.ByState = append(resp.ByState, g)
+		case domain.AdStatsByCity:
+			resp.ByCity = append(resp.ByCity, g)
+		case domain.AdStatsByNeighborhood:
+			resp.ByNeighborhood = append(resp.ByNeighborhood, g)
+		}
+	}
+	return resp, nil
+}
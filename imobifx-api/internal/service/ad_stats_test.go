@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+func TestAdsService_Stats_GroupsLevelsAndConvertsToUSD(t *testing.T) {
+	var got repo.AdsFilter
+	db := &fakeAdsRepo{
+		quoteFn: func(ctx context.Context) (*domain.Quote, error) {
+			return &domain.Quote{BrlToUsd: 0.2, EffectiveAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}, nil
+		},
+		statsFn: func(ctx context.Context, f repo.AdsFilter) ([]domain.AdPriceStats, error) {
+			got = f
+			summary := domain.AdPriceStats{Type: "RENT", Count: 3, Avg: 2000.333, Median: 2000, Min: 1500, Max: 2500.5,
+				Percentiles: []float64{1600, 1750, 2250, 2400}}
+			byType, byState, byCity, byNeighborhood := summary, summary, summary, summary
+			byType.Level = domain.AdStatsByType
+			byState.Level, byState.State = domain.AdStatsByState, "PB"
+			byCity.Level, byCity.State, byCity.City = domain.AdStatsByCity, "PB", "João Pessoa"
+			byNeighborhood.Level, byNeighborhood.State, byNeighborhood.City, byNeighborhood.Neighborhood = domain.AdStatsByNeighborhood, "PB", "João Pessoa", "Tambaú"
+			return []domain.AdPriceStats{byType, byState, byCity, byNeighborhood}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	resp, err := svc.Stats(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, Type: ptr("RENT"), MaxPriceUSD: ptr(600.0)})
+	require.NoError(t, err)
+
+	require.Equal(t, domain.AdStatusActive, *got.Status)
+	require.True(t, got.OnlyLive)
+	require.Equal(t, 3000.02, *got.MaxPrice) // the highest BRL price still at most US$ 600
+
+	require.Equal(t, 0.2, resp.QuoteUsed.BrlToUsd)
+	require.Len(t, resp.ByType, 1)
+	require.Len(t, resp.ByState, 1)
+	require.Len(t, resp.ByCity, 1)
+	require.Len(t, resp.ByNeighborhood, 1)
+
+	g := resp.ByNeighborhood[0]
+	require.Equal(t, "Tambaú", g.Neighborhood)
+	require.Equal(t, 3, g.Count)
+	require.Equal(t, 2000.33, g.PriceBRL.Avg)
+	require.Equal(t, map[string]float64{"p10": 1600, "p25": 1750, "p75": 2250, "p90": 2400}, g.PriceBRL.Percentiles)
+	require.NotNil(t, g.PriceUSD)
+	require.Equal(t, 400.07, g.PriceUSD.Avg)
+	require.Equal(t, 400.0, g.PriceUSD.Median)
+	require.Equal(t, 500.1, g.PriceUSD.Max)
+	require.Equal(t, 480.0, g.PriceUSD.Percentiles["p90"])
+}
+
+func TestAdsService_Stats_WithoutQuoteOmitsUSD(t *testing.T) {
+	db := &fakeAdsRepo{
+		statsFn: func(ctx context.Context, f repo.AdsFilter) ([]domain.AdPriceStats, error) {
+			return []domain.AdPriceStats{{Level: domain.AdStatsByType, Type: "SALE", Count: 1, Avg: 300000, Median: 300000,
+				Min: 300000, Max: 300000, Percentiles: []float64{300000, 300000, 300000, 300000}}}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	resp, err := svc.Stats(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.Nil(t, resp.QuoteUsed)
+	require.Len(t, resp.ByType, 1)
+	require.Nil(t, resp.ByType[0].PriceUSD)
+	require.Empty(t, resp.ByCity)
+
+	// USD filters cannot be applied without a quote.
+	_, err = svc.Stats(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, MinPriceUSD: ptr(100.0)})
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+// adTransitions lists, for each status, the statuses an ad may move to.
+// SOLD is final; a RENTED ad can be listed again once the lease ends.
+var adTransitions = map[string][]string{
+	domain.AdStatusDraft:   {domain.AdStatusActive},
+	domain.AdStatusActive:  {domain.AdStatusPaused, domain.AdStatusSold, domain.AdStatusRented, domain.AdStatusExpired},
+	domain.AdStatusPaused:  {domain.AdStatusActive, domain.AdStatusSold, domain.AdStatusRented, domain.AdStatusExpired},
+	domain.AdStatusExpired: {domain.AdStatusActive, domain.AdStatusDraft},
+	domain.AdStatusRented:  {domain.AdStatusActive},
+	domain.AdStatusSold:    {},
+}
+
+func checkAdTransition(ad domain.Ad, to string) error {
+	if !slices.Contains(adTransitions[ad.Status], to) {
+		return errors.New(http.StatusConflict, "AD_INVALID_TRANSITION", "Transição de status não permitida.", map[string]any{
+			"from":    ad.Status,
+			"to":      to,
+			"allowed": adTransitions[ad.Status],
+		})
+	}
+	if err := checkStatusMatchesType(ad.Type, to); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkStatusMatchesType keeps SOLD reserved for SALE ads and RENTED for RENT
+// ads.
+func checkStatusMatchesType(typ, status string) error {
+	if (status == domain.AdStatusSold && typ != "SALE") || (status == domain.AdStatusRented && typ != "RENT") {
+		return errors.New(http.StatusConflict, "AD_STATUS_TYPE_MISMATCH", "Status incompatível com o tipo do anúncio.", map[string]string{
+			"type":   typ,
+			"status": status,
+		})
+	}
+	return nil
+}
+
+func (s *AdsService) Transition(ctx context.Context, id, to string) (domain.Ad, error) {
+	if err := validation.ValidateAdStatus(to); err != nil {
+		return domain.Ad{}, err
+	}
+	ad, err := s.getLive(ctx, id)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if err := checkAdTransition(ad, to); err != nil {
+		return domain.Ad{}, err
+	}
+
+	updated, err := s.db.UpdateAdStatus(ctx, id, ad.Status, to)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if updated == nil {
+		return domain.Ad{}, adVersionConflict(id)
+	}
+	return *updated, nil
+}
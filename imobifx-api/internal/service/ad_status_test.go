@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+const statusAdID = "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+
+func adWithStatus(typ, status string) *fakeAdsRepo {
+	return &fakeAdsRepo{
+		getFn: func(ctx context.Context, id string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: typ, Status: status}, nil
+		},
+	}
+}
+
+func TestAdsService_Transition_Allowed(t *testing.T) {
+	cases := []struct {
+		typ, from, to string
+	}{
+		{"SALE", domain.AdStatusDraft, domain.AdStatusActive},
+		{"SALE", domain.AdStatusActive, domain.AdStatusPaused},
+		{"SALE", domain.AdStatusPaused, domain.AdStatusActive},
+		{"SALE", domain.AdStatusActive, domain.AdStatusSold},
+		{"RENT", domain.AdStatusActive, domain.AdStatusRented},
+		{"RENT", domain.AdStatusRented, domain.AdStatusActive},
+		{"SALE", domain.AdStatusExpired, domain.AdStatusActive},
+	}
+	for _, tc := range cases {
+		var from, to string
+		db := adWithStatus(tc.typ, tc.from)
+		db.statusFn = func(ctx context.Context, id, f, tt string) (*domain.Ad, error) {
+			from, to = f, tt
+			return &domain.Ad{ID: id, Type: tc.typ, Status: tt}, nil
+		}
+		svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+		got, err := svc.Transition(context.Background(), statusAdID, tc.to)
+		require.NoError(t, err, "%s %s->%s", tc.typ, tc.from, tc.to)
+		require.Equal(t, tc.to, got.Status)
+		require.Equal(t, tc.from, from)
+		require.Equal(t, tc.to, to)
+	}
+}
+
+func TestAdsService_Transition_Forbidden(t *testing.T) {
+	cases := []struct {
+		typ, from, to, code string
+	}{
+		{"SALE", domain.AdStatusSold, domain.AdStatusActive, "AD_INVALID_TRANSITION"},
+		{"SALE", domain.AdStatusDraft, domain.AdStatusSold, "AD_INVALID_TRANSITION"},
+		{"RENT", domain.AdStatusActive, domain.AdStatusSold, "AD_STATUS_TYPE_MISMATCH"},
+		{"SALE", domain.AdStatusActive, domain.AdStatusRented, "AD_STATUS_TYPE_MISMATCH"},
+	}
+	for _, tc := range cases {
+		svc := service.NewAdsService(adWithStatus(tc.typ, tc.from), localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+		_, err := svc.Transition(context.Background(), statusAdID, tc.to)
+
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr, "%s %s->%s", tc.typ, tc.from, tc.to)
+		require.Equal(t, 409, appErr.Status)
+		require.Equal(t, tc.code, appErr.Code)
+	}
+}
+
+func TestAdsService_Transition_UnknownStatus(t *testing.T) {
+	svc := service.NewAdsService(adWithStatus("SALE", domain.AdStatusActive), localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Transition(context.Background(), statusAdID, "ARCHIVED")
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+}
+
+func TestAdsService_Transition_ConcurrentChange_Conflict(t *testing.T) {
+	db := adWithStatus("SALE", domain.AdStatusActive)
+	db.statusFn = func(ctx context.Context, id, from, to string) (*domain.Ad, error) { return nil, nil }
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Transition(context.Background(), statusAdID, domain.AdStatusPaused)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 412, appErr.Status)
+}
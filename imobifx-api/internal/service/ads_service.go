@@ -2,62 +2,384 @@ package service
 
 import (
 	"context"
-	"io"
+	stderrors "errors"
+	"log/slog"
+	"math"
 	"mime/multipart"
-	"os"
+	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/imaging"
 	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/storage"
 	"github.com/josinaldojr/imobifx-api/internal/usecase"
 	"github.com/josinaldojr/imobifx-api/internal/validation"
 )
 
 type AdsService struct {
 	db           AdsRepository
-	imagesDir    string
+	images       storage.Storage
+	uploads      storage.Storage
+	geocoder     Geocoder
 	maxImageSize int64
+	maxImages    int
+	retention    time.Duration
+	defaultTTL   time.Duration
 }
 
-func NewAdsService(db AdsRepository, imagesDir string, maxImageSize int64) *AdsService {
-	_ = os.MkdirAll(imagesDir, 0o755)
-	return &AdsService{db: db, imagesDir: imagesDir, maxImageSize: maxImageSize}
+// NewAdsService builds the ads service. Uploads are staged in uploads, which
+// must not be publicly readable, and the rendered variants are written to
+// images. geocoder locates ads sent without coordinates; nil disables it.
+// maxImages caps the images per ad;
+// retention is how long archived ads stay restorable; defaultTTL is the
+// expiry given to ads created or renewed without an explicit expires_at (zero
+// disables it).
+func NewAdsService(db AdsRepository, images, uploads storage.Storage, geocoder Geocoder, maxImageSize int64, maxImages int, retention, defaultTTL time.Duration) *AdsService {
+	return &AdsService{
+		db:           db,
+		images:       images,
+		uploads:      uploads,
+		geocoder:     geocoder,
+		maxImageSize: maxImageSize,
+		maxImages:    maxImages,
+		retention:    retention,
+		defaultTTL:   defaultTTL,
+	}
 }
 
-func (s *AdsService) Create(ctx context.Context, in usecase.CreateAdInput, image *multipart.FileHeader) (domain.Ad, error) {
+// Create validates and stores a new ad. The first image, if any, is the
+// cover.
+func (s *AdsService) Create(ctx context.Context, in usecase.CreateAdInput, images []*multipart.FileHeader) (domain.Ad, error) {
 	if err := validation.ValidateCreateAdInput(&in); err != nil {
 		return domain.Ad{}, err
 	}
-	if err := validation.ValidateImage(image, s.maxImageSize); err != nil {
+	if err := validation.ValidateImages(images, 0, s.maxImages, s.maxImageSize); err != nil {
 		return domain.Ad{}, err
 	}
 
-	var imageName *string
-	if image != nil {
-		name, err := s.saveImage(image)
-		if err != nil {
-			return domain.Ad{}, err
+	if err := checkImages(images); err != nil {
+		return domain.Ad{}, err
+	}
+
+	keys, err := s.stageImages(ctx, images)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	ad := s.newAd(in, keys)
+	if ad.Location == nil {
+		ad.Location = s.geocode(ctx, ad)
+	}
+
+	var created domain.Ad
+	if in.Force {
+		created, err = s.db.CreateAd(ctx, ad)
+	} else {
+		var out []domain.Ad
+		if out, err = s.db.CreateAds(ctx, []domain.Ad{ad}, rejectDuplicates(ad)); err == nil {
+			created = out[0]
 		}
-		imageName = &name
+	}
+	if err != nil {
+		s.discardStaged(ctx, keys)
+		return domain.Ad{}, err
+	}
+	return created, nil
+}
+
+// newAd builds a validated input into a new ad: the status defaults to
+// ACTIVE, or DRAFT while publish_at is in the future, and expires_at to
+// defaultTTL after publication.
+func (s *AdsService) newAd(in usecase.CreateAdInput, imageKeys []string) domain.Ad {
+	adImages := make([]domain.AdImage, 0, len(imageKeys))
+	for _, key := range imageKeys {
+		adImages = append(adImages, domain.AdImage{Path: key})
 	}
 
+	now := time.Now().UTC()
 	ad := domain.Ad{
-		Type:         in.Type,
-		PriceBRL:     in.PriceBRL,
-		ImagePath:    imageName,
-		CEP:          in.CEP,
-		Street:       in.Street,
-		Number:       in.Number,
-		Complement:   in.Complement,
-		Neighborhood: in.Neighborhood,
-		City:         in.City,
-		State:        in.State,
+		Status:    in.Status,
+		Images:    adImages,
+		PublishAt: in.PublishAt,
+		ExpiresAt: in.ExpiresAt,
+	}
+	if ad.Status == "" {
+		ad.Status = domain.AdStatusActive
+	}
+	if ad.PublishAt != nil && ad.PublishAt.After(now) {
+		ad.Status = domain.AdStatusDraft
 	}
+	if ad.ExpiresAt == nil && s.defaultTTL > 0 {
+		exp := latest(now, ad.PublishAt).Add(s.defaultTTL)
+		ad.ExpiresAt = &exp
+	}
+	applyAdInput(&ad, in)
+	return ad
+}
 
-	return s.db.CreateAd(ctx, ad)
+// Replace overwrites every editable field of an ad (PUT semantics). The image
+// is kept as is.
+func (s *AdsService) Replace(ctx context.Context, id string, in usecase.CreateAdInput, ifMatch string) (domain.Ad, error) {
+	current, err := s.loadForUpdate(ctx, id, ifMatch)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if err := validation.ValidateCreateAdInput(&in); err != nil {
+		return domain.Ad{}, err
+	}
+	return s.update(ctx, current, in)
+}
+
+// Patch changes only the fields present in the input (PATCH semantics) and
+// validates the merged result with the same rules as Create.
+func (s *AdsService) Patch(ctx context.Context, id string, in usecase.PatchAdInput, ifMatch string) (domain.Ad, error) {
+	current, err := s.loadForUpdate(ctx, id, ifMatch)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	merged := mergeAdPatch(current, in)
+	if err := validation.ValidateCreateAdInput(&merged); err != nil {
+		return domain.Ad{}, err
+	}
+	return s.update(ctx, current, merged)
+}
+
+func (s *AdsService) loadForUpdate(ctx context.Context, id, ifMatch string) (domain.Ad, error) {
+	ad, err := s.getLive(ctx, id)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+
+	if strings.TrimSpace(ifMatch) == "" {
+		return domain.Ad{}, errors.New(http.StatusPreconditionRequired, "PRECONDITION_REQUIRED", "Envie o cabeçalho If-Match com o ETag atual do anúncio.", map[string]string{"etag": domain.AdETag(ad.UpdatedAt)})
+	}
+	if !domain.ETagMatches(ifMatch, domain.AdETag(ad.UpdatedAt)) {
+		return domain.Ad{}, adVersionConflict(id)
+	}
+	return ad, nil
+}
+
+// getLive loads an ad that is not archived, or fails with AD_NOT_FOUND.
+func (s *AdsService) getLive(ctx context.Context, id string) (domain.Ad, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.Ad{}, adNotFound(id)
+	}
+
+	ad, err := s.db.GetAd(ctx, id)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if ad == nil || ad.DeletedAt != nil {
+		return domain.Ad{}, adNotFound(id)
+	}
+	return *ad, nil
+}
+
+func (s *AdsService) update(ctx context.Context, current domain.Ad, in usecase.CreateAdInput) (domain.Ad, error) {
+	if err := checkStatusMatchesType(in.Type, current.Status); err != nil {
+		return domain.Ad{}, err
+	}
+
+	next := current
+	applyAdInput(&next, in)
+	if in.Latitude == nil && addressChanged(current, next) {
+		next.Location = s.geocode(ctx, next)
+	}
+
+	updated, err := s.db.UpdateAd(ctx, next, current.UpdatedAt)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if updated == nil {
+		return domain.Ad{}, adVersionConflict(current.ID)
+	}
+	return *updated, nil
+}
+
+// Delete archives an ad. It stays restorable for the configured retention
+// window and is purged afterwards.
+func (s *AdsService) Delete(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return adNotFound(id)
+	}
+	ok, err := s.db.SoftDeleteAd(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return adNotFound(id)
+	}
+	return nil
+}
+
+func (s *AdsService) Restore(ctx context.Context, id string) (domain.Ad, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.Ad{}, adNotFound(id)
+	}
+
+	ad, err := s.db.GetAd(ctx, id)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if ad == nil {
+		return domain.Ad{}, adNotFound(id)
+	}
+	if ad.DeletedAt == nil {
+		return domain.Ad{}, errors.New(http.StatusConflict, "AD_NOT_DELETED", "O anúncio não está arquivado.", map[string]string{"id": id})
+	}
+
+	deletedSince := time.Now().UTC().Add(-s.retention)
+	if ad.DeletedAt.Before(deletedSince) {
+		return domain.Ad{}, adRetentionExpired(id)
+	}
+
+	restored, err := s.db.RestoreAd(ctx, id, deletedSince)
+	if err != nil {
+		return domain.Ad{}, err
+	}
+	if restored == nil {
+		return domain.Ad{}, adRetentionExpired(id)
+	}
+	return *restored, nil
+}
+
+// PurgeDeleted removes ads archived for longer than the retention window,
+// together with their image files. It returns how many ads were purged.
+func (s *AdsService) PurgeDeleted(ctx context.Context) (int, error) {
+	purged, err := s.db.PurgeDeletedAds(ctx, time.Now().UTC().Add(-s.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, a := range purged {
+		for _, img := range a.Images {
+			if err := s.removeImageFiles(ctx, img); err != nil {
+				slog.Warn("purge_image_failed",
+					slog.String("ad_id", a.ID),
+					slog.String("image_path", img.Path),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	}
+	return len(purged), nil
+}
+
+// latest returns the later of t and an optional other time.
+func latest(t time.Time, other *time.Time) time.Time {
+	if other != nil && other.After(t) {
+		return *other
+	}
+	return t
+}
+
+func applyAdInput(ad *domain.Ad, in usecase.CreateAdInput) {
+	ad.Type = in.Type
+	ad.PriceBRL = in.PriceBRL
+	ad.Title = in.Title
+	ad.Description = in.Description
+	ad.CEP = in.CEP
+	ad.Street = in.Street
+	ad.Number = in.Number
+	ad.Complement = in.Complement
+	ad.Neighborhood = in.Neighborhood
+	ad.City = in.City
+	ad.State = in.State
+	if in.Latitude != nil && in.Longitude != nil {
+		ad.Location = &domain.GeoPoint{Lat: *in.Latitude, Lng: *in.Longitude}
+	}
+	ad.Bedrooms = in.Bedrooms
+	ad.Bathrooms = in.Bathrooms
+	ad.Parking = in.Parking
+	ad.AreaM2 = in.AreaM2
+	ad.CondoFeeBRL = in.CondoFeeBRL
+	ad.IPTUBRL = in.IPTUBRL
+}
+
+func mergeAdPatch(a domain.Ad, p usecase.PatchAdInput) usecase.CreateAdInput {
+	in := usecase.CreateAdInput{
+		Type:         a.Type,
+		PriceBRL:     a.PriceBRL,
+		Title:        a.Title,
+		Description:  a.Description,
+		CEP:          a.CEP,
+		Street:       a.Street,
+		Number:       a.Number,
+		Complement:   a.Complement,
+		Neighborhood: a.Neighborhood,
+		City:         a.City,
+		State:        a.State,
+		Bedrooms:     a.Bedrooms,
+		Bathrooms:    a.Bathrooms,
+		Parking:      a.Parking,
+		AreaM2:       a.AreaM2,
+		CondoFeeBRL:  a.CondoFeeBRL,
+		IPTUBRL:      a.IPTUBRL,
+	}
+	if p.Type != nil {
+		in.Type = *p.Type
+	}
+	if p.PriceBRL != nil {
+		in.PriceBRL = *p.PriceBRL
+	}
+	if p.Title != nil {
+		in.Title = *p.Title
+	}
+	if p.Description != nil {
+		in.Description = *p.Description
+	}
+	if p.CEP != nil {
+		in.CEP = *p.CEP
+	}
+	if p.Street != nil {
+		in.Street = *p.Street
+	}
+	if p.Number != nil {
+		in.Number = emptyToNil(*p.Number)
+	}
+	if p.Complement != nil {
+		in.Complement = emptyToNil(*p.Complement)
+	}
+	if p.Neighborhood != nil {
+		in.Neighborhood = *p.Neighborhood
+	}
+	if p.City != nil {
+		in.City = *p.City
+	}
+	if p.State != nil {
+		in.State = *p.State
+	}
+	in.Latitude, in.Longitude = p.Latitude, p.Longitude
+	if p.Bedrooms != nil {
+		in.Bedrooms = p.Bedrooms
+	}
+	if p.Bathrooms != nil {
+		in.Bathrooms = p.Bathrooms
+	}
+	if p.Parking != nil {
+		in.Parking = p.Parking
+	}
+	if p.AreaM2 != nil {
+		in.AreaM2 = p.AreaM2
+	}
+	if p.CondoFeeBRL != nil {
+		in.CondoFeeBRL = p.CondoFeeBRL
+	}
+	if p.IPTUBRL != nil {
+		in.IPTUBRL = p.IPTUBRL
+	}
+	return in
+}
+
+func emptyToNil(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
 }
 
 func (s *AdsService) List(ctx context.Context, in usecase.ListAdsInput) (domain.AdsListResponse, error) {
@@ -65,54 +387,253 @@ func (s *AdsService) List(ctx context.Context, in usecase.ListAdsInput) (domain.
 		return domain.AdsListResponse{}, err
 	}
 
+	f, quote, err := s.listFilter(ctx, in)
+	if err != nil {
+		return domain.AdsListResponse{}, err
+	}
+
+	resp := domain.AdsListResponse{
+		PageSize:  in.PageSize,
+		QuoteUsed: domain.ToQuoteUsed(quote),
+	}
+
+	var ads []domain.Ad
+	if in.Cursor != nil {
+		ads, resp.NextCursor, resp.Total, err = s.listAfter(ctx, f, *in.Cursor, in.PageSize, in.WithTotal)
+	} else {
+		var total int
+		ads, total, err = s.db.ListAds(ctx, f, in.Page, in.PageSize)
+		resp.Page, resp.Total = in.Page, &total
+	}
+	if err != nil {
+		return domain.AdsListResponse{}, err
+	}
+
+	resp.Items = make([]domain.AdItem, 0, len(ads))
+
+	for _, a := range ads {
+		item := domain.ToAdItemWithQuote(a, quote, s.images)
+		if f.Near != nil && a.Location != nil {
+			d := math.Round(domain.DistanceKM(*f.Near, *a.Location)*100) / 100
+			item.DistanceKM = &d
+		}
+		resp.Items = append(resp.Items, item)
+	}
+
+	return resp, nil
+}
+
+// listFilter turns validated listing input into the repository filter and
+// the current quote, which also fixes the BRL bounds of the USD filters.
+// Everything that lists ads by ListAdsInput goes through it.
+func (s *AdsService) listFilter(ctx context.Context, in usecase.ListAdsInput) (repo.AdsFilter, *domain.Quote, error) {
 	var f repo.AdsFilter
+	f.Query = in.Q
+	f.Sort = in.Sort
+	if f.Sort == "" {
+		f.Sort = domain.AdSortNewest
+		if in.Q != nil {
+			f.Sort = domain.AdSortRelevance
+		}
+	}
 	f.Type = in.Type
+	f.Status = in.Status
+	if f.Status == nil && !in.Archived {
+		active := domain.AdStatusActive
+		f.Status = &active
+	} else if f.Status != nil && *f.Status == validation.ListStatusAll {
+		f.Status = nil
+	}
+	// ACTIVE means live now, whether asked for or by default: the scheduler
+	// may not have flipped the ads due since its last run yet.
+	f.OnlyLive = f.Status != nil && *f.Status == domain.AdStatusActive
 	f.City = in.City
+	f.Neighborhood = in.Neighborhood
+	if in.CEPPrefix != nil {
+		from, to, _ := validation.CEPRange(*in.CEPPrefix)
+		f.CEPFrom, f.CEPTo = &from, &to
+	}
 	f.State = in.State
 	f.MinPrice = in.MinPrice
 	f.MaxPrice = in.MaxPrice
+	f.PriceReducedSince = in.PriceReducedSince
+	f.MinBedrooms, f.MaxBedrooms = in.MinBedrooms, in.MaxBedrooms
+	f.MinBathrooms, f.MaxBathrooms = in.MinBathrooms, in.MaxBathrooms
+	f.MinParking, f.MaxParking = in.MinParking, in.MaxParking
+	f.MinArea, f.MaxArea = in.MinArea, in.MaxArea
+	f.MinCondoFee, f.MaxCondoFee = in.MinCondoFee, in.MaxCondoFee
+	f.MinIPTU, f.MaxIPTU = in.MinIPTU, in.MaxIPTU
+	if in.Lat != nil && in.Lng != nil {
+		f.Near = &domain.GeoPoint{Lat: *in.Lat, Lng: *in.Lng}
+		f.RadiusKM = in.RadiusKM
+	}
+	if b := in.BBox; b != nil {
+		f.BBox = &domain.GeoBox{MinLng: b[0], MinLat: b[1], MaxLng: b[2], MaxLat: b[3]}
+	}
+	for _, v := range in.Polygon {
+		f.Polygon = append(f.Polygon, domain.GeoPoint{Lng: v[0], Lat: v[1]})
+	}
+	f.Archived = in.Archived
 
 	quote, err := s.db.GetCurrentQuote(ctx)
 	if err != nil {
-		return domain.AdsListResponse{}, err
+		return repo.AdsFilter{}, nil, err
+	}
+	if in.MinPriceUSD != nil || in.MaxPriceUSD != nil {
+		if quote == nil {
+			return repo.AdsFilter{}, nil, quoteNotFound()
+		}
+		if in.MinPriceUSD != nil {
+			f.MinPrice = maxBound(f.MinPrice, quote.MinBRL(*in.MinPriceUSD))
+		}
+		if in.MaxPriceUSD != nil {
+			f.MaxPrice = minBound(f.MaxPrice, quote.MaxBRL(*in.MaxPriceUSD))
+		}
+	}
+	return f, quote, nil
+}
+
+// listAfter serves a keyset page. cursor is empty for the first page and
+// must come from a listing with the same sort and, sorting by distance, the
+// same lat/lng.
+func (s *AdsService) listAfter(ctx context.Context, f repo.AdsFilter, cursor string, limit int, withTotal bool) ([]domain.Ad, *string, *int, error) {
+	var after *repo.AdsCursor
+	if cursor != "" {
+		c, err := repo.DecodeAdsCursor(cursor)
+		if err != nil || !c.Fits(f) {
+			return nil, nil, nil, invalidCursor()
+		}
+		after = c
 	}
 
-	ads, total, err := s.db.ListAds(ctx, f, in.Page, in.PageSize)
+	ads, next, err := s.db.ListAdsAfter(ctx, f, after, limit)
+	if stderrors.Is(err, repo.ErrInvalidCursor) {
+		return nil, nil, nil, invalidCursor()
+	}
 	if err != nil {
-		return domain.AdsListResponse{}, err
+		return nil, nil, nil, err
 	}
 
-	resp := domain.AdsListResponse{
-		Page:     in.Page,
-		PageSize: in.PageSize,
-		Total:    total,
-		Items:    make([]domain.AdItem, 0, len(ads)),
-	}
-
-	if quote != nil {
-		resp.QuoteUsed = &struct {
-			BrlToUsd    float64   `json:"brl_to_usd"`
-			EffectiveAt time.Time `json:"effective_at"`
-		}{
-			BrlToUsd:    quote.BrlToUsd,
-			EffectiveAt: quote.EffectiveAt,
+	var total *int
+	if withTotal {
+		n, err := s.db.CountAds(ctx, f)
+		if err != nil {
+			return nil, nil, nil, err
 		}
+		total = &n
 	}
 
-	for _, a := range ads {
-		resp.Items = append(resp.Items, domain.ToAdItemWithQuote(a, quote))
+	var nextCursor *string
+	if next != nil {
+		v := next.Encode()
+		nextCursor = &v
 	}
+	return ads, nextCursor, total, nil
+}
 
-	return resp, nil
+func (s *AdsService) Get(ctx context.Context, id string) (domain.AdDetailResponse, error) {
+	ad, err := s.getLive(ctx, id)
+	if err != nil {
+		return domain.AdDetailResponse{}, err
+	}
+
+	quote, err := s.db.GetCurrentQuote(ctx)
+	if err != nil {
+		return domain.AdDetailResponse{}, err
+	}
+
+	return domain.AdDetailResponse{
+		AdItem:    domain.ToAdItemWithQuote(ad, quote, s.images),
+		QuoteUsed: domain.ToQuoteUsed(quote),
+	}, nil
+}
+
+// maxBound and minBound merge a BRL bound derived from a USD filter with the
+// one sent in BRL, keeping the tighter.
+func maxBound(cur *float64, v float64) *float64 {
+	if cur != nil && *cur > v {
+		return cur
+	}
+	return &v
+}
+
+func minBound(cur *float64, v float64) *float64 {
+	if cur != nil && *cur < v {
+		return cur
+	}
+	return &v
+}
+
+// geocode locates the ad address. Failures are logged and leave the ad
+// without coordinates; they never block a write.
+func (s *AdsService) geocode(ctx context.Context, ad domain.Ad) *domain.GeoPoint {
+	if s.geocoder == nil {
+		return nil
+	}
+	p, err := s.geocoder.Geocode(ctx, domain.Address{
+		CEP:          ad.CEP,
+		Street:       ad.Street,
+		Neighborhood: ad.Neighborhood,
+		City:         ad.City,
+		State:        ad.State,
+	})
+	if err != nil {
+		slog.Warn("geocode_failed", slog.String("cep", ad.CEP), slog.String("error", err.Error()))
+		return nil
+	}
+	return &p
+}
+
+// addressChanged reports whether the fields used for geocoding differ.
+func addressChanged(a, b domain.Ad) bool {
+	return a.CEP != b.CEP || a.Street != b.Street || a.City != b.City || a.State != b.State
+}
+
+func quoteNotFound() error {
+	return errors.New(http.StatusUnprocessableEntity, "QUOTE_NOT_FOUND", "Nenhuma cotação cadastrada para converter os filtros em USD.", nil)
+}
+
+func adNotFound(id string) error {
+	return errors.New(http.StatusNotFound, "AD_NOT_FOUND", "Anúncio não encontrado.", map[string]string{"id": id})
+}
+
+func adVersionConflict(id string) error {
+	return errors.New(http.StatusPreconditionFailed, "AD_VERSION_CONFLICT", "O anúncio foi alterado por outra requisição. Recarregue e tente novamente.", map[string]string{"id": id})
+}
+
+func invalidCursor() error {
+	return errors.New(http.StatusBadRequest, "INVALID_CURSOR", "Cursor inválido. Recomece a listagem sem cursor ou com os mesmos filtros e ordenação.", nil)
+}
+
+func adRetentionExpired(id string) error {
+	return errors.New(http.StatusGone, "AD_RETENTION_EXPIRED", "O prazo para restaurar este anúncio expirou.", map[string]string{"id": id})
+}
+
+// checkImages decodes the header of each upload so files that are not really
+// images are rejected before anything is stored.
+func checkImages(files []*multipart.FileHeader) error {
+	for i, f := range files {
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = imaging.Check(src)
+		src.Close()
+		if err != nil {
+			return errors.New(http.StatusBadRequest, "INVALID_IMAGE", "Imagem inválida ou corrompida.", map[string]any{"index": i, "filename": f.Filename, "reason": err.Error()})
+		}
+	}
+	return nil
 }
 
-func (s *AdsService) saveImage(file *multipart.FileHeader) (string, error) {
+// stageImage copies an upload into the uploads storage, which is not
+// served, until the image worker renders its variants.
+func (s *AdsService) stageImage(ctx context.Context, file *multipart.FileHeader) (string, error) {
 	ext := strings.ToLower(filepath.Ext(file.Filename))
 	if ext == "" {
 		ext = ".bin"
 	}
 	name := uuid.NewString() + ext
-	dstPath := filepath.Join(s.imagesDir, name)
 
 	src, err := file.Open()
 	if err != nil {
@@ -120,14 +641,58 @@ func (s *AdsService) saveImage(file *multipart.FileHeader) (string, error) {
 	}
 	defer src.Close()
 
-	dst, err := os.Create(dstPath)
-	if err != nil {
+	if err := s.uploads.Put(ctx, name, src, file.Size, file.Header.Get("Content-Type")); err != nil {
 		return "", err
 	}
-	defer dst.Close()
+	return name, nil
+}
 
-	if _, err := io.Copy(dst, src); err != nil {
-		return "", err
+// stageImages stages every upload. The staged objects only become images
+// once a committed row points at them (the worker reads rows, not the
+// storage), so on any failure the caller discards them; objects left behind
+// by a crash are removed by CollectImageGarbage.
+func (s *AdsService) stageImages(ctx context.Context, files []*multipart.FileHeader) ([]string, error) {
+	keys := make([]string, 0, len(files))
+	for _, f := range files {
+		key, err := s.stageImage(ctx, f)
+		if err != nil {
+			s.discardStaged(ctx, keys)
+			return nil, err
+		}
+		keys = append(keys, key)
 	}
-	return name, nil
+	return keys, nil
+}
+
+func (s *AdsService) discardStaged(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.uploads.Delete(context.WithoutCancel(ctx), key); err != nil {
+			slog.Warn("discard_staged_image_failed",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// removeImageFiles deletes every stored object of an image: the staged upload
+// while it is not READY, the variants once it is.
+func (s *AdsService) removeImageFiles(ctx context.Context, img domain.AdImage) error {
+	if img.Status != "" && img.Status != domain.AdImageReady {
+		return s.uploads.Delete(ctx, img.Path)
+	}
+
+	errs := []error{s.images.Delete(ctx, img.Path)}
+	for _, p := range img.Variants {
+		if p != img.Path {
+			errs = append(errs, s.images.Delete(ctx, p))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// Item is the API representation of an ad, with image URLs from the images
+// storage.
+func (s *AdsService) Item(a domain.Ad) domain.AdItem {
+	return domain.ToAdItem(a, s.images)
 }
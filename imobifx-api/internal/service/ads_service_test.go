@@ -4,32 +4,70 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"image"
+	"image/jpeg"
+	"math"
 	"mime/multipart"
 	"net/http/httptest"
 	"net/textproto"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
 	"github.com/josinaldojr/imobifx-api/internal/repo"
 	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/storage"
 	"github.com/josinaldojr/imobifx-api/internal/usecase"
 )
 
+const (
+	maxImages = 3
+	retention = 30 * 24 * time.Hour
+	ttl       = 90 * 24 * time.Hour
+)
+
 type fakeAdsRepo struct {
 	lastCreated domain.Ad
+	lastUpdated domain.Ad
 
 	createCalled bool
+	getCalled    bool
+	updateCalled bool
 	listCalled   bool
 	quoteCalled  bool
 
 	createFn func(ctx context.Context, ad domain.Ad) (domain.Ad, error)
-	listFn   func(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error)
-	quoteFn  func(ctx context.Context) (*domain.Quote, error)
+	getFn    func(ctx context.Context, id string) (*domain.Ad, error)
+	updateFn func(ctx context.Context, ad domain.Ad, expectedUpdatedAt time.Time) (*domain.Ad, error)
+	statusFn func(ctx context.Context, id, from, to string) (*domain.Ad, error)
+	renewFn  func(ctx context.Context, id, from, to string, expiresAt time.Time) (*domain.Ad, error)
+	deleteFn func(ctx context.Context, id string) (bool, error)
+	imagesFn func(ctx context.Context, op, adID string, args []string) (*domain.Ad, error)
+
+	pendingImages []domain.AdImage
+	completed     map[string]map[string]string
+	failed        map[string]string
+	imageRefs     []domain.ImageRef
+
+	activatedAt time.Time
+	expiredAt   time.Time
+	restoreFn   func(ctx context.Context, id string, deletedSince time.Time) (*domain.Ad, error)
+	purgeFn     func(ctx context.Context, deletedBefore time.Time) ([]domain.Ad, error)
+	listFn      func(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error)
+	afterFn     func(ctx context.Context, f repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error)
+	countCalled bool
+	quoteFn     func(ctx context.Context) (*domain.Quote, error)
+	prices      []domain.AdPrice
+	bulkCreated []domain.Ad
+	openAds     []domain.Ad
+	checked     []domain.Ad
+	statsFn     func(ctx context.Context, f repo.AdsFilter) ([]domain.AdPriceStats, error)
 }
 
 func (f *fakeAdsRepo) CreateAd(ctx context.Context, ad domain.Ad) (domain.Ad, error) {
@@ -43,6 +81,155 @@ func (f *fakeAdsRepo) CreateAd(ctx context.Context, ad domain.Ad) (domain.Ad, er
 	return ad, nil
 }
 
+func (f *fakeAdsRepo) CreateAds(ctx context.Context, ads []domain.Ad, keep func(open []domain.Ad) ([]domain.Ad, error)) ([]domain.Ad, error) {
+	if keep != nil {
+		f.checked = append(f.checked, ads...)
+		var open []domain.Ad
+		for _, a := range f.openAds {
+			if slices.ContainsFunc(ads, func(ad domain.Ad) bool { return ad.CEP == a.CEP && ad.Type == a.Type }) {
+				open = append(open, a)
+			}
+		}
+		var err error
+		if ads, err = keep(open); err != nil {
+			return nil, err
+		}
+	}
+	if len(ads) == 0 {
+		return nil, nil
+	}
+	f.bulkCreated = ads
+	out := make([]domain.Ad, len(ads))
+	for i, ad := range ads {
+		created, err := f.CreateAd(ctx, ad)
+		if err != nil {
+			return nil, err
+		}
+		if f.createFn == nil {
+			created.ID = fmt.Sprintf("ad-%d", i+1)
+		}
+		out[i] = created
+	}
+	return out, nil
+}
+
+func (f *fakeAdsRepo) GetAd(ctx context.Context, id string) (*domain.Ad, error) {
+	f.getCalled = true
+	if f.getFn != nil {
+		return f.getFn(ctx, id)
+	}
+	return nil, nil
+}
+
+func (f *fakeAdsRepo) ListAdPriceHistory(ctx context.Context, adID string) ([]domain.AdPrice, error) {
+	return f.prices, nil
+}
+
+func (f *fakeAdsRepo) UpdateAd(ctx context.Context, ad domain.Ad, expectedUpdatedAt time.Time) (*domain.Ad, error) {
+	f.updateCalled = true
+	f.lastUpdated = ad
+	if f.updateFn != nil {
+		return f.updateFn(ctx, ad, expectedUpdatedAt)
+	}
+	ad.UpdatedAt = expectedUpdatedAt.Add(time.Second)
+	return &ad, nil
+}
+
+func (f *fakeAdsRepo) UpdateAdStatus(ctx context.Context, id, from, to string) (*domain.Ad, error) {
+	if f.statusFn != nil {
+		return f.statusFn(ctx, id, from, to)
+	}
+	return &domain.Ad{ID: id, Status: to}, nil
+}
+
+func (f *fakeAdsRepo) RenewAd(ctx context.Context, id, from, to string, expiresAt time.Time) (*domain.Ad, error) {
+	if f.renewFn != nil {
+		return f.renewFn(ctx, id, from, to, expiresAt)
+	}
+	return &domain.Ad{ID: id, Status: to, ExpiresAt: &expiresAt}, nil
+}
+
+func (f *fakeAdsRepo) ActivateScheduledAds(ctx context.Context, now time.Time) (int64, error) {
+	f.activatedAt = now
+	return 0, nil
+}
+
+func (f *fakeAdsRepo) ExpireAds(ctx context.Context, now time.Time) (int64, error) {
+	f.expiredAt = now
+	return 0, nil
+}
+
+func (f *fakeAdsRepo) imagesOp(ctx context.Context, op, adID string, args []string) (*domain.Ad, error) {
+	if f.imagesFn != nil {
+		return f.imagesFn(ctx, op, adID, args)
+	}
+	return &domain.Ad{ID: adID}, nil
+}
+
+func (f *fakeAdsRepo) AddAdImages(ctx context.Context, adID string, paths []string) (*domain.Ad, error) {
+	return f.imagesOp(ctx, "add", adID, paths)
+}
+
+func (f *fakeAdsRepo) DeleteAdImage(ctx context.Context, adID, imageID string) (*domain.Ad, error) {
+	return f.imagesOp(ctx, "delete", adID, []string{imageID})
+}
+
+func (f *fakeAdsRepo) ReorderAdImages(ctx context.Context, adID string, imageIDs []string) (*domain.Ad, error) {
+	return f.imagesOp(ctx, "reorder", adID, imageIDs)
+}
+
+func (f *fakeAdsRepo) SetAdCover(ctx context.Context, adID, imageID string) (*domain.Ad, error) {
+	return f.imagesOp(ctx, "cover", adID, []string{imageID})
+}
+
+func (f *fakeAdsRepo) ClaimPendingImages(ctx context.Context, limit int, staleBefore time.Time) ([]domain.AdImage, error) {
+	n := min(limit, len(f.pendingImages))
+	out := f.pendingImages[:n]
+	f.pendingImages = f.pendingImages[n:]
+	return out, nil
+}
+
+func (f *fakeAdsRepo) CompleteAdImage(ctx context.Context, imageID, path string, variants map[string]string) (bool, error) {
+	if f.completed == nil {
+		f.completed = map[string]map[string]string{}
+	}
+	f.completed[imageID] = variants
+	return true, nil
+}
+
+func (f *fakeAdsRepo) FailAdImage(ctx context.Context, imageID, reason string) (bool, error) {
+	if f.failed == nil {
+		f.failed = map[string]string{}
+	}
+	f.failed[imageID] = reason
+	return true, nil
+}
+
+func (f *fakeAdsRepo) ListImageRefs(ctx context.Context) ([]domain.ImageRef, error) {
+	return f.imageRefs, nil
+}
+
+func (f *fakeAdsRepo) SoftDeleteAd(ctx context.Context, id string) (bool, error) {
+	if f.deleteFn != nil {
+		return f.deleteFn(ctx, id)
+	}
+	return true, nil
+}
+
+func (f *fakeAdsRepo) RestoreAd(ctx context.Context, id string, deletedSince time.Time) (*domain.Ad, error) {
+	if f.restoreFn != nil {
+		return f.restoreFn(ctx, id, deletedSince)
+	}
+	return nil, nil
+}
+
+func (f *fakeAdsRepo) PurgeDeletedAds(ctx context.Context, deletedBefore time.Time) ([]domain.Ad, error) {
+	if f.purgeFn != nil {
+		return f.purgeFn(ctx, deletedBefore)
+	}
+	return nil, nil
+}
+
 func (f *fakeAdsRepo) ListAds(ctx context.Context, flt repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error) {
 	f.listCalled = true
 	if f.listFn != nil {
@@ -51,6 +238,30 @@ func (f *fakeAdsRepo) ListAds(ctx context.Context, flt repo.AdsFilter, page, pag
 	return nil, 0, nil
 }
 
+func (f *fakeAdsRepo) ListAdsAfter(ctx context.Context, flt repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error) {
+	f.listCalled = true
+	if f.afterFn != nil {
+		return f.afterFn(ctx, flt, after, limit)
+	}
+	return nil, nil, nil
+}
+
+func (f *fakeAdsRepo) CountAds(ctx context.Context, flt repo.AdsFilter) (int, error) {
+	f.countCalled = true
+	return 42, nil
+}
+
+func (f *fakeAdsRepo) ListOpenAdsSharingCEP(ctx context.Context) ([]domain.Ad, error) {
+	return f.openAds, nil
+}
+
+func (f *fakeAdsRepo) AdPriceStats(ctx context.Context, filter repo.AdsFilter) ([]domain.AdPriceStats, error) {
+	if f.statsFn != nil {
+		return f.statsFn(ctx, filter)
+	}
+	return []domain.AdPriceStats{}, nil
+}
+
 func (f *fakeAdsRepo) GetCurrentQuote(ctx context.Context) (*domain.Quote, error) {
 	f.quoteCalled = true
 	if f.quoteFn != nil {
@@ -63,7 +274,7 @@ func TestAdsService_Create_OK_NoImage_FormatsCEP_AndCallsRepo(t *testing.T) {
 	db := &fakeAdsRepo{}
 	tmp := t.TempDir()
 
-	svc := service.NewAdsService(db, tmp, 5*1024*1024)
+	svc := service.NewAdsService(db, localStore(t, tmp), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
 
 	in := usecase.CreateAdInput{
 		Type:         "SALE",
@@ -80,12 +291,13 @@ func TestAdsService_Create_OK_NoImage_FormatsCEP_AndCallsRepo(t *testing.T) {
 	require.True(t, db.createCalled)
 
 	require.Equal(t, "58000-000", db.lastCreated.CEP)
-	require.Nil(t, db.lastCreated.ImagePath)
+	require.Equal(t, domain.AdStatusActive, db.lastCreated.Status)
+	require.Empty(t, db.lastCreated.Images)
 }
 
 func TestAdsService_Create_InvalidInput_DoesNotCallRepo(t *testing.T) {
 	db := &fakeAdsRepo{}
-	svc := service.NewAdsService(db, t.TempDir(), 5*1024*1024)
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
 
 	in := usecase.CreateAdInput{
 		Type:         "X",
@@ -102,11 +314,11 @@ func TestAdsService_Create_InvalidInput_DoesNotCallRepo(t *testing.T) {
 	require.False(t, db.createCalled)
 }
 
-func TestAdsService_Create_WithImage_SavesFile_AndSetsImagePath(t *testing.T) {
+func TestAdsService_Create_WithImage_StagesFile(t *testing.T) {
 	db := &fakeAdsRepo{}
-	tmp := t.TempDir()
+	imagesDir, uploadsDir := t.TempDir(), t.TempDir()
 
-	svc := service.NewAdsService(db, tmp, 5*1024*1024)
+	svc := service.NewAdsService(db, localStore(t, imagesDir), localStore(t, uploadsDir), nil, 5*1024*1024, maxImages, retention, ttl)
 
 	in := usecase.CreateAdInput{
 		Type:         "RENT",
@@ -118,17 +330,51 @@ func TestAdsService_Create_WithImage_SavesFile_AndSetsImagePath(t *testing.T) {
 		State:        "PB",
 	}
 
-	fh := makeMultipartFileHeader(t, "image", "house.jpg", "image/jpeg", []byte("fake-jpeg-bytes"))
+	fh := makeMultipartFileHeader(t, "image", "house.jpg", "image/jpeg", testJPEG(t))
 
-	_, err := svc.Create(context.Background(), in, fh)
+	_, err := svc.Create(context.Background(), in, []*multipart.FileHeader{fh})
 	require.NoError(t, err)
 	require.True(t, db.createCalled)
 
-	require.NotNil(t, db.lastCreated.ImagePath)
-	require.NotEmpty(t, *db.lastCreated.ImagePath)
+	require.Len(t, db.lastCreated.Images, 1)
+	require.NotEmpty(t, db.lastCreated.Images[0].Path)
 
-	_, statErr := os.Stat(filepath.Join(tmp, *db.lastCreated.ImagePath))
+	_, statErr := os.Stat(filepath.Join(uploadsDir, db.lastCreated.Images[0].Path))
 	require.NoError(t, statErr)
+
+	entries, err := os.ReadDir(imagesDir)
+	require.NoError(t, err)
+	require.Empty(t, entries, "originals must not be written to the served directory")
+}
+
+func TestAdsService_Create_NotAnImage(t *testing.T) {
+	db := &fakeAdsRepo{}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	fh := makeMultipartFileHeader(t, "image", "house.jpg", "image/jpeg", []byte("fake-jpeg-bytes"))
+	_, err := svc.Create(context.Background(), scheduleInput(), []*multipart.FileHeader{fh})
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "INVALID_IMAGE", appErr.Code)
+	require.False(t, db.createCalled)
+}
+
+func TestAdsService_Create_TooManyImages(t *testing.T) {
+	db := &fakeAdsRepo{}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	files := make([]*multipart.FileHeader, maxImages+1)
+	for i := range files {
+		files[i] = makeMultipartFileHeader(t, "images", fmt.Sprintf("%d.jpg", i), "image/jpeg", []byte("x"))
+	}
+
+	_, err := svc.Create(context.Background(), scheduleInput(), files)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "TOO_MANY_IMAGES", appErr.Code)
+	require.False(t, db.createCalled)
 }
 
 func TestAdsService_List_SetsQuoteUsed_AndReturnsItems(t *testing.T) {
@@ -158,7 +404,7 @@ func TestAdsService_List_SetsQuoteUsed_AndReturnsItems(t *testing.T) {
 		},
 	}
 
-	svc := service.NewAdsService(db, t.TempDir(), 5*1024*1024)
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
 
 	typ := "SALE"
 	in := usecase.ListAdsInput{
@@ -175,7 +421,8 @@ func TestAdsService_List_SetsQuoteUsed_AndReturnsItems(t *testing.T) {
 
 	require.Equal(t, 1, resp.Page)
 	require.Equal(t, 10, resp.PageSize)
-	require.Equal(t, 1, resp.Total)
+	require.Equal(t, 1, *resp.Total)
+	require.Nil(t, resp.NextCursor)
 	require.Len(t, resp.Items, 1)
 
 	require.NotNil(t, resp.QuoteUsed)
@@ -183,6 +430,612 @@ func TestAdsService_List_SetsQuoteUsed_AndReturnsItems(t *testing.T) {
 	require.True(t, resp.QuoteUsed.EffectiveAt.Equal(time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)))
 }
 
+func TestAdsService_List_DefaultsToActiveStatus(t *testing.T) {
+	var got repo.AdsFilter
+	db := &fakeAdsRepo{
+		listFn: func(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error) {
+			got = f
+			return nil, 0, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.NotNil(t, got.Status)
+	require.Equal(t, domain.AdStatusActive, *got.Status)
+	require.True(t, got.OnlyLive)
+
+	active := domain.AdStatusActive
+	_, err = svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, Status: &active})
+	require.NoError(t, err)
+	require.True(t, got.OnlyLive)
+
+	paused := domain.AdStatusPaused
+	_, err = svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, Status: &paused})
+	require.NoError(t, err)
+	require.False(t, got.OnlyLive)
+
+	all := "ALL"
+	_, err = svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, Status: &all})
+	require.NoError(t, err)
+	require.Nil(t, got.Status)
+	require.False(t, got.OnlyLive)
+}
+
+func TestAdsService_List_PassesFilters(t *testing.T) {
+	var got repo.AdsFilter
+	db := &fakeAdsRepo{
+		listFn: func(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error) {
+			got = f
+			return nil, 0, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.List(context.Background(), usecase.ListAdsInput{
+		Page: 1, PageSize: 10,
+		Q:           ptr("varanda gourmet"),
+		CEPPrefix:   ptr("58038"),
+		MinBedrooms: ptr(2), MaxBedrooms: ptr(3),
+		MinArea: ptr(50.0), MaxIPTU: ptr(1200.0),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "varanda gourmet", *got.Query)
+	require.Equal(t, "58038-000", *got.CEPFrom)
+	require.Equal(t, "58038-999", *got.CEPTo)
+	require.Equal(t, 2, *got.MinBedrooms)
+	require.Equal(t, 3, *got.MaxBedrooms)
+	require.Equal(t, 50.0, *got.MinArea)
+	require.Nil(t, got.MaxArea)
+	require.Equal(t, 1200.0, *got.MaxIPTU)
+
+	_, err = svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, MinBedrooms: ptr(4), MaxBedrooms: ptr(1)})
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+}
+
+func TestAdsService_List_USDBoundsMatchDisplayedPrice(t *testing.T) {
+	quote := &domain.Quote{ID: "q1", BrlToUsd: 0.1873, EffectiveAt: time.Now().UTC()}
+	var got repo.AdsFilter
+	db := &fakeAdsRepo{
+		quoteFn: func(ctx context.Context) (*domain.Quote, error) { return quote, nil },
+		listFn: func(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error) {
+			got = f
+			return nil, 0, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	minUSD, maxUSD := 50000.0, 100000.0
+	resp, err := svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, MinPriceUSD: &minUSD, MaxPriceUSD: &maxUSD})
+	require.NoError(t, err)
+	require.Equal(t, 0.1873, resp.QuoteUsed.BrlToUsd)
+
+	// Every price within a few cents of each bound is kept by the BRL filter
+	// exactly when its displayed price_usd is within the USD filter.
+	urls := localStore(t, t.TempDir())
+	for _, bound := range []float64{*got.MinPrice, *got.MaxPrice} {
+		for c := -300.0; c <= 300; c++ {
+			price := math.Round(bound*100+c) / 100
+			item := domain.ToAdItemWithQuote(domain.Ad{PriceBRL: price}, quote, urls)
+			inBRL := price >= *got.MinPrice && price <= *got.MaxPrice
+			inUSD := *item.PriceUSD >= minUSD && *item.PriceUSD <= maxUSD
+			require.Equal(t, inUSD, inBRL, "price_brl=%.2f price_usd=%.2f", price, *item.PriceUSD)
+		}
+	}
+
+	brlMax := 100000.0
+	_, err = svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, MaxPrice: &brlMax, MaxPriceUSD: &maxUSD})
+	require.NoError(t, err)
+	require.Equal(t, brlMax, *got.MaxPrice, "the tighter bound wins")
+
+	quote = nil
+	_, err = svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, MinPriceUSD: &minUSD})
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "QUOTE_NOT_FOUND", appErr.Code)
+}
+
+func TestAdsService_List_USDBoundsAnyQuoteAndMagnitude(t *testing.T) {
+	var quote *domain.Quote
+	var got repo.AdsFilter
+	db := &fakeAdsRepo{
+		quoteFn: func(ctx context.Context) (*domain.Quote, error) { return quote, nil },
+		listFn: func(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error) {
+			got = f
+			return nil, 0, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	urls := localStore(t, t.TempDir())
+
+	for _, rate := range []float64{0.0001, 0.1873, 1, 5.4321} {
+		quote = &domain.Quote{ID: "q1", BrlToUsd: rate, EffectiveAt: time.Now().UTC()}
+		for _, usd := range []float64{0, 0.01, 500.1, 1234.567, 1e9} {
+			_, err := svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, MinPriceUSD: &usd, MaxPriceUSD: ptr(usd + 1000)})
+			require.NoError(t, err)
+
+			price := func(brl float64) float64 {
+				return *domain.ToAdItemWithQuote(domain.Ad{PriceBRL: brl}, quote, urls).PriceUSD
+			}
+			require.GreaterOrEqual(t, price(*got.MinPrice), usd, "rate=%v usd=%v", rate, usd)
+			if *got.MinPrice > 0 {
+				require.Less(t, price(*got.MinPrice-0.01), usd, "rate=%v usd=%v", rate, usd)
+			}
+			require.LessOrEqual(t, price(*got.MaxPrice), usd+1000, "rate=%v usd=%v", rate, usd)
+			require.Greater(t, price(*got.MaxPrice+0.01), usd+1000, "rate=%v usd=%v", rate, usd)
+		}
+	}
+}
+
+func TestAdsService_List_RejectsUnboundedUSDBounds(t *testing.T) {
+	quote := &domain.Quote{ID: "q1", BrlToUsd: 0.1873, EffectiveAt: time.Now().UTC()}
+	db := &fakeAdsRepo{quoteFn: func(ctx context.Context) (*domain.Quote, error) { return quote, nil }}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	// These used to walk cent by cent from the estimate and never return.
+	for _, usd := range []float64{1e17, math.Inf(1), math.NaN()} {
+		_, err := svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, MinPriceUSD: ptr(usd)})
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr, "usd=%v", usd)
+		require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+		require.Contains(t, appErr.Details, "min_price_usd")
+	}
+	require.False(t, db.listCalled)
+
+	// Saved searches stored before the limit reach the conversion without
+	// validation: it must still return.
+	require.Greater(t, quote.MinBRL(1e17), 5e17)
+	require.Greater(t, quote.MaxBRL(1e17), 5e17)
+}
+
+func TestAdsService_List_DefaultSort(t *testing.T) {
+	var got repo.AdsFilter
+	db := &fakeAdsRepo{
+		listFn: func(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error) {
+			got = f
+			return nil, 0, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, domain.AdSortNewest, got.Sort)
+
+	_, err = svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, Q: ptr("praia")})
+	require.NoError(t, err)
+	require.Equal(t, domain.AdSortRelevance, got.Sort)
+
+	_, err = svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 10, Q: ptr("praia"), Sort: domain.AdSortPriceAsc})
+	require.NoError(t, err)
+	require.Equal(t, domain.AdSortPriceAsc, got.Sort)
+}
+
+func TestAdsService_List_Cursor(t *testing.T) {
+	var gotAfter *repo.AdsCursor
+	db := &fakeAdsRepo{
+		afterFn: func(ctx context.Context, f repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error) {
+			gotAfter = after
+			require.Equal(t, 2, limit)
+			key := "250000.00"
+			return []domain.Ad{{ID: "a1"}, {ID: "a2"}}, &repo.AdsCursor{Sort: f.Sort, Keys: []*string{&key}, ID: "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	first, err := svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 2, Cursor: ptr(""), Sort: domain.AdSortPriceAsc})
+	require.NoError(t, err)
+	require.Nil(t, gotAfter)
+	require.Zero(t, first.Page)
+	require.Nil(t, first.Total)
+	require.False(t, db.countCalled)
+	require.Len(t, first.Items, 2)
+	require.NotNil(t, first.NextCursor)
+
+	second, err := svc.List(context.Background(), usecase.ListAdsInput{Page: 1, PageSize: 2, Cursor: first.NextCursor, Sort: domain.AdSortPriceAsc, WithTotal: true})
+	require.NoError(t, err)
+	require.NotNil(t, gotAfter)
+	require.Equal(t, "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11", gotAfter.ID)
+	require.Equal(t, "250000.00", *gotAfter.Keys[0])
+	require.Equal(t, 42, *second.Total)
+
+	for _, in := range []usecase.ListAdsInput{
+		{Page: 1, PageSize: 2, Cursor: ptr("not-a-cursor")},
+		{Page: 1, PageSize: 2, Cursor: first.NextCursor, Sort: domain.AdSortPriceDesc},
+	} {
+		_, err := svc.List(context.Background(), in)
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		require.Equal(t, "INVALID_CURSOR", appErr.Code)
+	}
+}
+
+func TestAdsService_List_DistanceCursorKeepsThePoint(t *testing.T) {
+	db := &fakeAdsRepo{
+		afterFn: func(ctx context.Context, f repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error) {
+			key := "1.25"
+			return []domain.Ad{{ID: "a1"}}, &repo.AdsCursor{Sort: f.Sort, Keys: []*string{&key}, ID: "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11", Near: f.Near}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	near := func(lat, lng float64, cursor *string) usecase.ListAdsInput {
+		return usecase.ListAdsInput{Page: 1, PageSize: 1, Cursor: cursor, Sort: domain.AdSortDistance, Lat: ptr(lat), Lng: ptr(lng)}
+	}
+	first, err := svc.List(context.Background(), near(-7.1195, -34.8229, ptr("")))
+	require.NoError(t, err)
+	require.NotNil(t, first.NextCursor)
+
+	_, err = svc.List(context.Background(), near(-7.1195, -34.8229, first.NextCursor))
+	require.NoError(t, err)
+
+	// Distances from another point do not continue the same order.
+	_, err = svc.List(context.Background(), near(-8.0476, -34.877, first.NextCursor))
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "INVALID_CURSOR", appErr.Code)
+
+	key := "1.25"
+	noPoint := repo.AdsCursor{Sort: domain.AdSortDistance, Keys: []*string{&key}, ID: "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"}.Encode()
+	_, err = svc.List(context.Background(), near(-7.1195, -34.8229, &noPoint))
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "INVALID_CURSOR", appErr.Code)
+}
+
+func TestAdsService_Get_OK_ConvertsPriceWithQuote(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	db := &fakeAdsRepo{
+		quoteFn: func(ctx context.Context) (*domain.Quote, error) {
+			return &domain.Quote{ID: "q1", BrlToUsd: 0.2, EffectiveAt: time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)}, nil
+		},
+		getFn: func(ctx context.Context, got string) (*domain.Ad, error) {
+			require.Equal(t, id, got)
+			return &domain.Ad{ID: id, Type: "SALE", PriceBRL: 1000, CEP: "58000-000", City: "João Pessoa", State: "PB"}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	resp, err := svc.Get(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, id, resp.ID)
+	require.NotNil(t, resp.PriceUSD)
+	require.Equal(t, 200.0, *resp.PriceUSD)
+	require.NotNil(t, resp.QuoteUsed)
+	require.Equal(t, 0.2, resp.QuoteUsed.BrlToUsd)
+}
+
+func TestAdsService_Get_PricePerM2(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	ad := domain.Ad{ID: id, Type: "SALE", PriceBRL: 350000, CEP: "58000-000", City: "João Pessoa", State: "PB", AreaM2: ptr(72.5), Bedrooms: ptr(2)}
+	db := &fakeAdsRepo{
+		getFn: func(ctx context.Context, got string) (*domain.Ad, error) {
+			return &ad, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	resp, err := svc.Get(context.Background(), id)
+	require.NoError(t, err)
+	require.NotNil(t, resp.PricePerM2)
+	require.Equal(t, 4827.59, *resp.PricePerM2)
+	require.Equal(t, 2, *resp.Bedrooms)
+
+	ad.AreaM2 = nil
+	resp, err = svc.Get(context.Background(), id)
+	require.NoError(t, err)
+	require.Nil(t, resp.PricePerM2)
+}
+
+func TestAdsService_Get_NotFound(t *testing.T) {
+	db := &fakeAdsRepo{}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Get(context.Background(), "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11")
+	require.Error(t, err)
+	require.True(t, db.getCalled)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 404, appErr.Status)
+	require.Equal(t, "AD_NOT_FOUND", appErr.Code)
+}
+
+func TestAdsService_Get_MalformedID_DoesNotCallRepo(t *testing.T) {
+	db := &fakeAdsRepo{}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Get(context.Background(), "not-a-uuid")
+	require.Error(t, err)
+	require.False(t, db.getCalled)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "AD_NOT_FOUND", appErr.Code)
+}
+
+func existingAdRepo(id string, updatedAt time.Time) *fakeAdsRepo {
+	return &fakeAdsRepo{
+		getFn: func(ctx context.Context, got string) (*domain.Ad, error) {
+			return &domain.Ad{
+				ID:           id,
+				Type:         "SALE",
+				PriceBRL:     250000,
+				CEP:          "58000-000",
+				Street:       "Rua A",
+				Number:       ptr("10"),
+				Neighborhood: "Centro",
+				City:         "João Pessoa",
+				State:        "PB",
+				Bedrooms:     ptr(3),
+				AreaM2:       ptr(80.0),
+				UpdatedAt:    updatedAt,
+			}, nil
+		},
+	}
+}
+
+func TestAdsService_Patch_OK_MergesAndValidates(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	updatedAt := time.Date(2026, 2, 16, 10, 0, 0, 123000, time.UTC)
+	db := existingAdRepo(id, updatedAt)
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	price := 240000.0
+	cep := "58000001"
+	number := ""
+	got, err := svc.Patch(context.Background(), id, usecase.PatchAdInput{PriceBRL: &price, CEP: &cep, Number: &number}, domain.AdETag(updatedAt))
+	require.NoError(t, err)
+	require.True(t, db.updateCalled)
+
+	require.Equal(t, 240000.0, db.lastUpdated.PriceBRL)
+	require.Equal(t, "58000-001", db.lastUpdated.CEP)
+	require.Equal(t, "Rua A", db.lastUpdated.Street)
+	require.Nil(t, db.lastUpdated.Number)
+	require.True(t, got.UpdatedAt.After(updatedAt))
+}
+
+func TestAdsService_Patch_Attributes(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	updatedAt := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+	db := existingAdRepo(id, updatedAt)
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Patch(context.Background(), id, usecase.PatchAdInput{Bedrooms: ptr(4), CondoFeeBRL: ptr(550.0), Title: ptr("Apartamento com varanda")}, domain.AdETag(updatedAt))
+	require.NoError(t, err)
+	require.Equal(t, "Apartamento com varanda", db.lastUpdated.Title)
+	require.Equal(t, 4, *db.lastUpdated.Bedrooms)
+	require.Equal(t, 550.0, *db.lastUpdated.CondoFeeBRL)
+	require.Equal(t, 80.0, *db.lastUpdated.AreaM2)
+
+	_, err = svc.Patch(context.Background(), id, usecase.PatchAdInput{AreaM2: ptr(0.0)}, domain.AdETag(updatedAt))
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+}
+
+func TestAdsService_Patch_InvalidMerge_ReturnsValidationError(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	updatedAt := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+	db := existingAdRepo(id, updatedAt)
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	typ := "SWAP"
+	_, err := svc.Patch(context.Background(), id, usecase.PatchAdInput{Type: &typ}, domain.AdETag(updatedAt))
+	require.Error(t, err)
+	require.False(t, db.updateCalled)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+}
+
+func TestAdsService_Patch_MissingIfMatch_PreconditionRequired(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	db := existingAdRepo(id, time.Now().UTC())
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Patch(context.Background(), id, usecase.PatchAdInput{}, "")
+	require.Error(t, err)
+	require.False(t, db.updateCalled)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 428, appErr.Status)
+}
+
+func TestAdsService_Patch_StaleETag_PreconditionFailed(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	updatedAt := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+	db := existingAdRepo(id, updatedAt)
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Patch(context.Background(), id, usecase.PatchAdInput{}, domain.AdETag(updatedAt.Add(-time.Minute)))
+	require.Error(t, err)
+	require.False(t, db.updateCalled)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 412, appErr.Status)
+	require.Equal(t, "AD_VERSION_CONFLICT", appErr.Code)
+}
+
+func TestAdsService_Patch_WeakETag_PreconditionFailed(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	updatedAt := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+	db := existingAdRepo(id, updatedAt)
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	// If-Match compares strongly: a weak tag never matches, even the current one.
+	_, err := svc.Patch(context.Background(), id, usecase.PatchAdInput{}, "W/"+domain.AdETag(updatedAt))
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "AD_VERSION_CONFLICT", appErr.Code)
+	require.False(t, db.updateCalled)
+
+	_, err = svc.Patch(context.Background(), id, usecase.PatchAdInput{}, `"stale", `+domain.AdETag(updatedAt))
+	require.NoError(t, err)
+}
+
+func TestAdsService_Replace_ConcurrentWrite_PreconditionFailed(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	updatedAt := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+	db := existingAdRepo(id, updatedAt)
+	db.updateFn = func(ctx context.Context, ad domain.Ad, expected time.Time) (*domain.Ad, error) {
+		return nil, nil
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	in := usecase.CreateAdInput{
+		Type:         "RENT",
+		PriceBRL:     1500,
+		CEP:          "58000000",
+		Street:       "Rua B",
+		Neighborhood: "Bairro",
+		City:         "João Pessoa",
+		State:        "PB",
+	}
+	_, err := svc.Replace(context.Background(), id, in, domain.AdETag(updatedAt))
+	require.Error(t, err)
+	require.True(t, db.updateCalled)
+	require.Equal(t, "RENT", db.lastUpdated.Type)
+	require.Equal(t, "58000-000", db.lastUpdated.CEP)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 412, appErr.Status)
+}
+
+func TestAdsService_Get_Deleted_NotFound(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	deletedAt := time.Now().UTC()
+	db := &fakeAdsRepo{
+		getFn: func(ctx context.Context, got string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: "SALE", DeletedAt: &deletedAt}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Get(context.Background(), id)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "AD_NOT_FOUND", appErr.Code)
+}
+
+func TestAdsService_Delete_AlreadyDeleted_NotFound(t *testing.T) {
+	db := &fakeAdsRepo{
+		deleteFn: func(ctx context.Context, id string) (bool, error) { return false, nil },
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	err := svc.Delete(context.Background(), "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11")
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 404, appErr.Status)
+}
+
+func TestAdsService_Restore_WithinRetention(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	deletedAt := time.Now().UTC().Add(-24 * time.Hour)
+	var since time.Time
+	db := &fakeAdsRepo{
+		getFn: func(ctx context.Context, got string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: "SALE", DeletedAt: &deletedAt}, nil
+		},
+		restoreFn: func(ctx context.Context, got string, deletedSince time.Time) (*domain.Ad, error) {
+			since = deletedSince
+			return &domain.Ad{ID: id, Type: "SALE"}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	restored, err := svc.Restore(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, id, restored.ID)
+	require.Nil(t, restored.DeletedAt)
+	require.WithinDuration(t, time.Now().UTC().Add(-retention), since, time.Minute)
+}
+
+func TestAdsService_Restore_RetentionExpired(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	deletedAt := time.Now().UTC().Add(-retention - time.Hour)
+	db := &fakeAdsRepo{
+		getFn: func(ctx context.Context, got string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: "SALE", DeletedAt: &deletedAt}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Restore(context.Background(), id)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 410, appErr.Status)
+	require.Equal(t, "AD_RETENTION_EXPIRED", appErr.Code)
+}
+
+func TestAdsService_Restore_NotDeleted_Conflict(t *testing.T) {
+	id := "0b4c3f7e-1a52-4f0e-9a43-6a7b0c4d2e11"
+	db := &fakeAdsRepo{
+		getFn: func(ctx context.Context, got string) (*domain.Ad, error) {
+			return &domain.Ad{ID: id, Type: "SALE"}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	_, err := svc.Restore(context.Background(), id)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, 409, appErr.Status)
+	require.Equal(t, "AD_NOT_DELETED", appErr.Code)
+}
+
+func TestAdsService_PurgeDeleted_RemovesImageFiles(t *testing.T) {
+	tmp := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "a.jpg"), []byte("x"), 0o644))
+
+	var cutoff time.Time
+	db := &fakeAdsRepo{
+		purgeFn: func(ctx context.Context, deletedBefore time.Time) ([]domain.Ad, error) {
+			cutoff = deletedBefore
+			return []domain.Ad{
+				{ID: "ad-1", Images: []domain.AdImage{{Path: "a.jpg"}, {Path: "missing.jpg"}}},
+				{ID: "ad-2"},
+			}, nil
+		},
+	}
+	svc := service.NewAdsService(db, localStore(t, tmp), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	n, err := svc.PurgeDeleted(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.WithinDuration(t, time.Now().UTC().Add(-retention), cutoff, time.Minute)
+
+	_, statErr := os.Stat(filepath.Join(tmp, "a.jpg"))
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func localStore(t *testing.T, dir string) storage.Storage {
+	t.Helper()
+	l, err := storage.NewLocal(dir, "/static/images")
+	require.NoError(t, err)
+	return l
+}
+
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	require.NoError(t, jpeg.Encode(&b, image.NewRGBA(image.Rect(0, 0, 64, 48)), nil))
+	return b.Bytes()
+}
+
 func makeMultipartFileHeader(t *testing.T, field, filename, contentType string, data []byte) *multipart.FileHeader {
 	t.Helper()
 
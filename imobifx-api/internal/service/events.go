@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+const (
+	// eventBatch is how many events are read at once, live or replayed.
+	eventBatch = 500
+
+	// eventSubscriptionBuffer is how many events a subscriber may lag behind
+	// before it is dropped. A dropped client reconnects with Last-Event-ID
+	// and catches up from the outbox.
+	eventSubscriptionBuffer = 256
+)
+
+// EventHub streams the outbox events to the subscribers of this instance as
+// they are committed, on any instance: it listens for the database
+// notifications sent with every event. The stream follows the events' Seq,
+// their commit order, so clients that were away replay what they missed
+// from the outbox itself without skipping late commits.
+type EventHub struct {
+	db EventsRepository
+
+	mu      sync.Mutex
+	subs    map[*EventSubscription]struct{}
+	lastSeq int64
+	closed  bool
+}
+
+func NewEventHub(db EventsRepository) *EventHub {
+	return &EventHub{db: db, subs: map[*EventSubscription]struct{}{}}
+}
+
+// EventSubscription receives the live events matching its filter on C,
+// which is closed when the subscriber falls too far behind or the hub
+// closes.
+type EventSubscription struct {
+	C <-chan domain.Event
+
+	hub    *EventHub
+	ch     chan domain.Event
+	filter domain.EventFilter
+
+	// after is the Seq the client resumes after, when it does.
+	after *int64
+	// from is the Seq of the latest event published before subscribing;
+	// replayed events after it may also arrive live.
+	from     int64
+	replayed map[int64]struct{}
+}
+
+// Subscribe starts receiving the events matching the filters of in. The
+// caller must Close the subscription.
+func (h *EventHub) Subscribe(in usecase.EventsInput) (*EventSubscription, error) {
+	if err := validation.ValidateEventsInput(in); err != nil {
+		return nil, err
+	}
+	ch := make(chan domain.Event, eventSubscriptionBuffer)
+	s := &EventSubscription{
+		C:        ch,
+		hub:      h,
+		ch:       ch,
+		filter:   domain.EventFilter{Type: in.Type, City: in.City, State: in.State},
+		after:    in.LastEventID,
+		replayed: map[int64]struct{}{},
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return s, nil
+	}
+	s.from = h.lastSeq
+	h.subs[s] = struct{}{}
+	return s, nil
+}
+
+func (s *EventSubscription) Close() {
+	s.hub.drop(s)
+}
+
+// Replay calls fn for every matching event committed after the one the
+// client resumes from, in stream order, and does nothing for a new client.
+// Events older than the outbox retention are gone.
+func (s *EventSubscription) Replay(ctx context.Context, fn func(domain.Event) error) error {
+	if s.after == nil {
+		return nil
+	}
+	afterSeq := *s.after
+	for {
+		events, err := s.hub.db.ListEventsAfter(ctx, afterSeq, eventBatch)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if e.Seq > s.from {
+				s.replayed[e.Seq] = struct{}{}
+			}
+			if !s.filter.Matches(e) {
+				continue
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		if len(events) < eventBatch {
+			return nil
+		}
+		afterSeq = events[len(events)-1].Seq
+	}
+}
+
+// Replayed reports whether a live event was already sent by Replay.
+func (s *EventSubscription) Replayed(e domain.Event) bool {
+	_, ok := s.replayed[e.Seq]
+	return ok
+}
+
+// Run publishes the events committed on any instance until ctx is done or
+// the listening connection fails. It is meant to be restarted by the jobs
+// runner. Each wake-up, the first one as soon as the listener is up,
+// sequences the new events and publishes those after the last one seen, so
+// what was committed while nobody listened is caught up right away.
+func (h *EventHub) Run(ctx context.Context) error {
+	h.mu.Lock()
+	lastSeq := h.lastSeq
+	h.mu.Unlock()
+	if lastSeq == 0 {
+		seq, err := h.db.LastEventSeq(ctx)
+		if err != nil {
+			return err
+		}
+		h.setLastSeq(seq)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wake := make(chan struct{}, 1)
+	errc := make(chan error, 1)
+	go func() { errc <- h.db.ListenEvents(ctx, wake) }()
+
+	for {
+		select {
+		case err := <-errc:
+			return err
+		case <-wake:
+		}
+		if _, err := h.db.SequenceEvents(ctx); err != nil {
+			return err
+		}
+		if err := h.catchUp(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// catchUp publishes the events sequenced after the last one published.
+func (h *EventHub) catchUp(ctx context.Context) error {
+	h.mu.Lock()
+	afterSeq := h.lastSeq
+	h.mu.Unlock()
+
+	for {
+		events, err := h.db.ListEventsAfter(ctx, afterSeq, eventBatch)
+		if err != nil {
+			return err
+		}
+		h.publish(events)
+		if len(events) < eventBatch {
+			return nil
+		}
+		afterSeq = events[len(events)-1].Seq
+	}
+}
+
+// Close ends every subscription and refuses new ones, so open streams
+// finish before the server shuts down.
+func (h *EventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for s := range h.subs {
+		delete(h.subs, s)
+		close(s.ch)
+	}
+}
+
+// publish hands the events to the matching subscribers. A subscriber whose
+// buffer is full is dropped rather than slowing everyone down.
+func (h *EventHub) publish(events []domain.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, e := range events {
+		h.lastSeq = max(h.lastSeq, e.Seq)
+		for s := range h.subs {
+			if !s.filter.Matches(e) {
+				continue
+			}
+			select {
+			case s.ch <- e:
+			default:
+				delete(h.subs, s)
+				close(s.ch)
+			}
+		}
+	}
+}
+
+func (h *EventHub) setLastSeq(seq int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastSeq = max(h.lastSeq, seq)
+}
+
+func (h *EventHub) drop(s *EventSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[s]; ok {
+		delete(h.subs, s)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,294 @@
+package service_test
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// fakeEventsRepo keeps the outbox in memory. Events are committed either at
+// once (add) or later (begin, then commit), and each commit is announced to
+// the hub once it listens, as the database notifications would be.
+type fakeEventsRepo struct {
+	mu        sync.Mutex
+	events    []domain.Event
+	committed map[int64]bool
+	seq       int64
+	listening bool
+	notify    chan struct{}
+	listened  chan struct{}
+}
+
+func newFakeEventsRepo() *fakeEventsRepo {
+	return &fakeEventsRepo{committed: map[int64]bool{}, notify: make(chan struct{}, 100), listened: make(chan struct{}, 10)}
+}
+
+// begin records an event whose transaction has not committed yet.
+func (f *fakeEventsRepo) begin(typ string, data any) domain.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	payload, _ := json.Marshal(data)
+	e := domain.Event{ID: int64(len(f.events) + 1), Type: typ, CreatedAt: time.Now(), Data: payload}
+	f.events = append(f.events, e)
+	return e
+}
+
+// commit makes an event begun earlier visible and announces it.
+func (f *fakeEventsRepo) commit(e domain.Event) {
+	f.mu.Lock()
+	f.committed[e.ID] = true
+	listening := f.listening
+	f.mu.Unlock()
+	if listening {
+		f.notify <- struct{}{}
+	}
+}
+
+// add records an event and commits it.
+func (f *fakeEventsRepo) add(typ string, data any) domain.Event {
+	e := f.begin(typ, data)
+	f.commit(e)
+	return e
+}
+
+func (f *fakeEventsRepo) SequenceEvents(ctx context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var n int64
+	for i, e := range f.events {
+		if e.Seq == 0 && f.committed[e.ID] {
+			f.seq++
+			f.events[i].Seq = f.seq
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeEventsRepo) ListEventsAfter(ctx context.Context, afterSeq int64, limit int) ([]domain.Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.Event
+	for _, e := range f.events {
+		if e.Seq > afterSeq {
+			out = append(out, e)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Event) int { return cmp.Compare(a.Seq, b.Seq) })
+	return out[:min(len(out), limit)], nil
+}
+
+func (f *fakeEventsRepo) LastEventSeq(ctx context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.seq, nil
+}
+
+func (f *fakeEventsRepo) ListenEvents(ctx context.Context, wake chan<- struct{}) error {
+	f.mu.Lock()
+	f.listening = true
+	f.mu.Unlock()
+	wake <- struct{}{}
+	f.listened <- struct{}{}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-f.notify:
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func runEventHub(t *testing.T, db *fakeEventsRepo) *service.EventHub {
+	t.Helper()
+	hub := service.NewEventHub(db)
+	startEventHub(t, hub, db)
+	return hub
+}
+
+func startEventHub(t *testing.T, hub *service.EventHub, db *fakeEventsRepo) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = hub.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		hub.Close()
+	})
+	<-db.listened
+}
+
+func subscribe(t *testing.T, hub *service.EventHub, in usecase.EventsInput) *service.EventSubscription {
+	t.Helper()
+	sub, err := hub.Subscribe(in)
+	require.NoError(t, err)
+	return sub
+}
+
+func adEvent(typ, adType, city, state string) (string, map[string]string) {
+	return typ, map[string]string{"type": adType, "city": city, "state": state}
+}
+
+func receive(t *testing.T, sub *service.EventSubscription) domain.Event {
+	t.Helper()
+	select {
+	case e, ok := <-sub.C:
+		require.True(t, ok, "subscription closed")
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+		return domain.Event{}
+	}
+}
+
+func TestEventHub_PublishesMatchingEvents(t *testing.T) {
+	db := newFakeEventsRepo()
+	hub := runEventHub(t, db)
+
+	all := subscribe(t, hub, usecase.EventsInput{})
+	defer all.Close()
+	jp := subscribe(t, hub, usecase.EventsInput{Type: ptr("RENT"), City: ptr(" joao pessoa"), State: ptr("PB")})
+	defer jp.Close()
+
+	db.add(adEvent(domain.EventAdCreated, "SALE", "João Pessoa", "PB"))
+	db.add(adEvent(domain.EventAdUpdated, "RENT", "Recife", "PE"))
+	rent := db.add(adEvent(domain.EventAdUpdated, "RENT", "João Pessoa", "PB"))
+	quote := db.add(domain.EventQuoteCreated, map[string]any{"brl_to_usd": 0.2})
+
+	for i := int64(1); i <= 4; i++ {
+		require.Equal(t, i, receive(t, all).ID)
+	}
+	// Quotes reprice every ad and reach filtered subscribers too.
+	require.Equal(t, rent.ID, receive(t, jp).ID)
+	require.Equal(t, quote.ID, receive(t, jp).ID)
+}
+
+func TestEventHub_ReplaysMissedEvents(t *testing.T) {
+	db := newFakeEventsRepo()
+	db.add(adEvent(domain.EventAdCreated, "SALE", "Recife", "PE"))
+	db.add(adEvent(domain.EventAdCreated, "RENT", "Recife", "PE"))
+	db.add(adEvent(domain.EventAdDeleted, "SALE", "Natal", "RN"))
+	// Sequenced by a hub that ran before.
+	_, _ = db.SequenceEvents(context.Background())
+	hub := runEventHub(t, db)
+
+	sub := subscribe(t, hub, usecase.EventsInput{State: ptr("PE"), LastEventID: ptr(int64(1))})
+	defer sub.Close()
+	// Committed after subscribing: both replayed and published live.
+	db.add(adEvent(domain.EventAdUpdated, "RENT", "Recife", "PE"))
+	live := receive(t, sub)
+
+	var replayed []int64
+	require.NoError(t, sub.Replay(context.Background(), func(e domain.Event) error {
+		replayed = append(replayed, e.ID)
+		return nil
+	}))
+	require.Equal(t, []int64{2, 4}, replayed)
+	require.True(t, sub.Replayed(live))
+}
+
+func TestEventHub_StreamsInCommitOrder(t *testing.T) {
+	db := newFakeEventsRepo()
+	hub := runEventHub(t, db)
+	sub := subscribe(t, hub, usecase.EventsInput{})
+	defer sub.Close()
+
+	// The first event's transaction commits after the second one's.
+	late := db.begin(adEvent(domain.EventAdCreated, "SALE", "Recife", "PE"))
+	early := db.add(adEvent(domain.EventAdCreated, "RENT", "Recife", "PE"))
+	seen := receive(t, sub)
+	require.Equal(t, early.ID, seen.ID)
+	db.commit(late)
+	got := receive(t, sub)
+	require.Equal(t, late.ID, got.ID)
+	require.Greater(t, got.Seq, seen.Seq)
+
+	// A client that stopped at the second event still gets the first one.
+	again := subscribe(t, hub, usecase.EventsInput{LastEventID: &seen.Seq})
+	defer again.Close()
+	var replayed []int64
+	require.NoError(t, again.Replay(context.Background(), func(e domain.Event) error {
+		replayed = append(replayed, e.ID)
+		return nil
+	}))
+	require.Equal(t, []int64{late.ID}, replayed)
+}
+
+func TestEventHub_CatchesUpOnceListening(t *testing.T) {
+	db := newFakeEventsRepo()
+	hub := service.NewEventHub(db)
+	sub := subscribe(t, hub, usecase.EventsInput{})
+	defer sub.Close()
+
+	// Committed while no hub listened: no notification will come for it.
+	missed := db.add(adEvent(domain.EventAdCreated, "SALE", "Natal", "RN"))
+	startEventHub(t, hub, db)
+	require.Equal(t, missed.ID, receive(t, sub).ID)
+}
+
+func TestEventHub_DropsSlowSubscribers(t *testing.T) {
+	db := newFakeEventsRepo()
+	hub := runEventHub(t, db)
+
+	slow := subscribe(t, hub, usecase.EventsInput{})
+	fast := subscribe(t, hub, usecase.EventsInput{})
+	defer fast.Close()
+
+	for round, n := range []int{200, 100} {
+		for i := 0; i < n; i++ {
+			db.add(adEvent(domain.EventAdUpdated, "SALE", fmt.Sprint("Cidade ", i), "PB"))
+		}
+		for i := 0; i < n; i++ {
+			require.Equal(t, int64(round*200+i+1), receive(t, fast).ID)
+		}
+	}
+
+	// The slow subscriber got what fit in its buffer, then was closed.
+	n := 0
+	for range slow.C {
+		n++
+	}
+	require.Equal(t, 256, n)
+	slow.Close()
+}
+
+func TestEventHub_CloseEndsSubscriptions(t *testing.T) {
+	db := newFakeEventsRepo()
+	hub := runEventHub(t, db)
+
+	sub := subscribe(t, hub, usecase.EventsInput{})
+	hub.Close()
+	_, ok := <-sub.C
+	require.False(t, ok)
+
+	_, ok = <-subscribe(t, hub, usecase.EventsInput{}).C
+	require.False(t, ok)
+}
+
+func TestEventHub_Subscribe_ValidatesFilters(t *testing.T) {
+	hub := service.NewEventHub(newFakeEventsRepo())
+	_, err := hub.Subscribe(usecase.EventsInput{Type: ptr("LEASE")})
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+}
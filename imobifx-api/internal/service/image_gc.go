@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/storage"
+)
+
+// Storage names used in ImageGCReport.
+const (
+	StoreImages  = "images"
+	StoreUploads = "uploads"
+)
+
+// ImageGCObject is an object found in one of the storages.
+type ImageGCObject struct {
+	Store string `json:"store"`
+	Key   string `json:"key"`
+	Size  int64  `json:"size"`
+}
+
+// ImageGCReport is the outcome of one CollectImageGarbage pass. Orphans are
+// objects no row points at (deleted unless dry run); Missing are rows that
+// point at objects that do not exist.
+type ImageGCReport struct {
+	Scanned    int               `json:"scanned"`
+	Orphans    []ImageGCObject   `json:"orphans"`
+	FreedBytes int64             `json:"freed_bytes"`
+	Missing    []domain.ImageRef `json:"missing"`
+	DryRun     bool              `json:"dry_run"`
+}
+
+// CollectImageGarbage reconciles both storages with the database. Objects
+// and rows younger than grace are left alone so uploads and renders in
+// flight are never mistaken for garbage.
+func (s *AdsService) CollectImageGarbage(ctx context.Context, grace time.Duration, dryRun bool) (ImageGCReport, error) {
+	report := ImageGCReport{DryRun: dryRun, Orphans: []ImageGCObject{}, Missing: []domain.ImageRef{}}
+	cutoff := time.Now().Add(-grace)
+
+	// List the storages before loading the references: an object written
+	// after the listing cannot be reported as orphan, and one referenced by
+	// a row committed after the listing is still found in refs.
+	stores := []struct {
+		name string
+		st   storage.Storage
+	}{{StoreImages, s.images}, {StoreUploads, s.uploads}}
+
+	listed := make(map[string][]storage.Object, len(stores))
+	for _, store := range stores {
+		err := store.st.List(ctx, func(o storage.Object) error {
+			listed[store.name] = append(listed[store.name], o)
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+		report.Scanned += len(listed[store.name])
+	}
+
+	refs, err := s.db.ListImageRefs(ctx)
+	if err != nil {
+		return report, err
+	}
+	referenced := map[string]map[string]bool{StoreImages: {}, StoreUploads: {}}
+	for _, ref := range refs {
+		referenced[refStore(ref)][ref.Key] = true
+	}
+
+	for _, store := range stores {
+		for _, o := range listed[store.name] {
+			if referenced[store.name][o.Key] || o.ModTime.After(cutoff) {
+				continue
+			}
+			if !dryRun {
+				if err := store.st.Delete(ctx, o.Key); err != nil {
+					slog.Warn("image_gc_delete_failed",
+						slog.String("store", store.name),
+						slog.String("key", o.Key),
+						slog.String("error", err.Error()),
+					)
+					continue
+				}
+			}
+			report.Orphans = append(report.Orphans, ImageGCObject{Store: store.name, Key: o.Key, Size: o.Size})
+			report.FreedBytes += o.Size
+		}
+	}
+
+	present := map[string]map[string]bool{StoreImages: {}, StoreUploads: {}}
+	for name, objs := range listed {
+		for _, o := range objs {
+			present[name][o.Key] = true
+		}
+	}
+	for _, ref := range refs {
+		if !present[refStore(ref)][ref.Key] && ref.CreatedAt.Before(cutoff) {
+			report.Missing = append(report.Missing, ref)
+		}
+	}
+	return report, nil
+}
+
+func refStore(ref domain.ImageRef) string {
+	if ref.Staged {
+		return StoreUploads
+	}
+	return StoreImages
+}
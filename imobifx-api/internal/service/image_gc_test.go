@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	stderrors "errors"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+)
+
+func TestAdsService_Create_RepoFailure_DiscardsStagedUpload(t *testing.T) {
+	uploadsDir := t.TempDir()
+	db := &fakeAdsRepo{createFn: func(ctx context.Context, ad domain.Ad) (domain.Ad, error) {
+		return domain.Ad{}, stderrors.New("insert failed")
+	}}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, uploadsDir), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	fh := makeMultipartFileHeader(t, "images", "a.jpg", "image/jpeg", testJPEG(t))
+	_, err := svc.Create(context.Background(), scheduleInput(), []*multipart.FileHeader{fh})
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(uploadsDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestAdsService_AddImages_AdGone_DiscardsStagedUpload(t *testing.T) {
+	uploadsDir := t.TempDir()
+	db := adWithImages("a.jpg")
+	db.imagesFn = func(ctx context.Context, op, adID string, args []string) (*domain.Ad, error) {
+		return nil, nil
+	}
+	svc := service.NewAdsService(db, localStore(t, t.TempDir()), localStore(t, uploadsDir), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	fh := makeMultipartFileHeader(t, "images", "b.jpg", "image/jpeg", testJPEG(t))
+	_, err := svc.AddImages(context.Background(), statusAdID, []*multipart.FileHeader{fh})
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(uploadsDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestAdsService_CollectImageGarbage(t *testing.T) {
+	imagesDir, uploadsDir := t.TempDir(), t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+
+	write := func(dir, name string, mtime time.Time) {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte("data"), 0o644))
+		require.NoError(t, os.Chtimes(p, mtime, mtime))
+	}
+	write(imagesDir, "kept_large.jpg", old)
+	write(imagesDir, "orphan_large.jpg", old)
+	write(imagesDir, "fresh_large.jpg", time.Now())
+	write(uploadsDir, "staged.jpg", old)
+	write(uploadsDir, "abandoned.jpg", old)
+
+	db := &fakeAdsRepo{imageRefs: []domain.ImageRef{
+		{AdID: "ad-1", ImageID: "img-1", Key: "kept_large.jpg", CreatedAt: old},
+		{AdID: "ad-1", ImageID: "img-2", Key: "staged.jpg", Staged: true, CreatedAt: old},
+		{AdID: "ad-2", ImageID: "img-3", Key: "gone_large.jpg", CreatedAt: old},
+		{AdID: "ad-3", ImageID: "img-4", Key: "racing.jpg", Staged: true, CreatedAt: time.Now()},
+	}}
+	svc := service.NewAdsService(db, localStore(t, imagesDir), localStore(t, uploadsDir), nil, 5*1024*1024, maxImages, retention, ttl)
+
+	report, err := svc.CollectImageGarbage(context.Background(), 24*time.Hour, true)
+	require.NoError(t, err)
+	require.Equal(t, 5, report.Scanned)
+	require.ElementsMatch(t, []service.ImageGCObject{
+		{Store: service.StoreImages, Key: "orphan_large.jpg", Size: 4},
+		{Store: service.StoreUploads, Key: "abandoned.jpg", Size: 4},
+	}, report.Orphans)
+	require.Len(t, report.Missing, 1)
+	require.Equal(t, "gone_large.jpg", report.Missing[0].Key)
+
+	_, err = os.Stat(filepath.Join(imagesDir, "orphan_large.jpg"))
+	require.NoError(t, err, "dry run must not delete")
+
+	report, err = svc.CollectImageGarbage(context.Background(), 24*time.Hour, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), report.FreedBytes)
+
+	for _, p := range []string{filepath.Join(imagesDir, "orphan_large.jpg"), filepath.Join(uploadsDir, "abandoned.jpg")} {
+		_, err = os.Stat(p)
+		require.True(t, os.IsNotExist(err), p)
+	}
+	for _, p := range []string{filepath.Join(imagesDir, "kept_large.jpg"), filepath.Join(imagesDir, "fresh_large.jpg"), filepath.Join(uploadsDir, "staged.jpg")} {
+		_, err = os.Stat(p)
+		require.NoError(t, err, p)
+	}
+}
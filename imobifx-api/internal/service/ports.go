@@ -10,15 +10,108 @@ import (
 
 type AdsRepository interface {
 	CreateAd(ctx context.Context, ad domain.Ad) (domain.Ad, error)
+	CreateAds(ctx context.Context, ads []domain.Ad, keep func(open []domain.Ad) ([]domain.Ad, error)) ([]domain.Ad, error)
+	GetAd(ctx context.Context, id string) (*domain.Ad, error)
+	ListAdPriceHistory(ctx context.Context, adID string) ([]domain.AdPrice, error)
+	UpdateAd(ctx context.Context, ad domain.Ad, expectedUpdatedAt time.Time) (*domain.Ad, error)
+	UpdateAdStatus(ctx context.Context, id, from, to string) (*domain.Ad, error)
+	RenewAd(ctx context.Context, id, from, to string, expiresAt time.Time) (*domain.Ad, error)
+	ActivateScheduledAds(ctx context.Context, now time.Time) (int64, error)
+	ExpireAds(ctx context.Context, now time.Time) (int64, error)
+	AddAdImages(ctx context.Context, adID string, paths []string) (*domain.Ad, error)
+	DeleteAdImage(ctx context.Context, adID, imageID string) (*domain.Ad, error)
+	ReorderAdImages(ctx context.Context, adID string, imageIDs []string) (*domain.Ad, error)
+	SetAdCover(ctx context.Context, adID, imageID string) (*domain.Ad, error)
+	ClaimPendingImages(ctx context.Context, limit int, staleBefore time.Time) ([]domain.AdImage, error)
+	CompleteAdImage(ctx context.Context, imageID, path string, variants map[string]string) (bool, error)
+	FailAdImage(ctx context.Context, imageID, reason string) (bool, error)
+	ListImageRefs(ctx context.Context) ([]domain.ImageRef, error)
+	SoftDeleteAd(ctx context.Context, id string) (bool, error)
+	RestoreAd(ctx context.Context, id string, deletedSince time.Time) (*domain.Ad, error)
+	PurgeDeletedAds(ctx context.Context, deletedBefore time.Time) ([]domain.Ad, error)
 	ListAds(ctx context.Context, f repo.AdsFilter, page, pageSize int) ([]domain.Ad, int, error)
+	ListAdsAfter(ctx context.Context, f repo.AdsFilter, after *repo.AdsCursor, limit int) ([]domain.Ad, *repo.AdsCursor, error)
+	CountAds(ctx context.Context, f repo.AdsFilter) (int, error)
+	AdPriceStats(ctx context.Context, f repo.AdsFilter) ([]domain.AdPriceStats, error)
+	ListOpenAdsSharingCEP(ctx context.Context) ([]domain.Ad, error)
 	GetCurrentQuote(ctx context.Context) (*domain.Quote, error)
 }
 
+type ExportsRepository interface {
+	CreateAdExport(ctx context.Context, format string, filters []byte) (domain.AdExport, error)
+	GetAdExport(ctx context.Context, id string) (*domain.AdExport, error)
+	ClaimAdExport(ctx context.Context, staleBefore time.Time) (*domain.AdExport, error)
+	CompleteAdExport(ctx context.Context, id string, claimedAt time.Time, fileKey string, rows int, size int64, quote *domain.Quote, expiresAt time.Time) (bool, error)
+	FailAdExport(ctx context.Context, id string, claimedAt time.Time, reason string, expiresAt time.Time) (bool, error)
+	DeleteExpiredAdExports(ctx context.Context, now time.Time) ([]domain.AdExport, error)
+}
+
+type SavedSearchesRepository interface {
+	CreateSavedSearch(ctx context.Context, s domain.SavedSearch) (domain.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id string) (*domain.SavedSearch, error)
+	ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, s domain.SavedSearch) (*domain.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id string) (bool, error)
+	ClaimUnmatchedAds(ctx context.Context, limit int) ([]string, error)
+	ReleaseUnmatchedAds(ctx context.Context, ids []string) error
+	MatchAds(ctx context.Context, adIDs []string, filters []repo.AdsFilter) ([][]string, error)
+	RecordSavedSearchMatches(ctx context.Context, searchID string, adIDs []string) error
+	ListSavedSearchMatches(ctx context.Context, searchID string, page, pageSize int) ([]domain.SavedSearchMatch, int, error)
+	ListPendingSavedSearchMatches(ctx context.Context, limit int) ([]domain.SavedSearchMatch, error)
+	MarkSavedSearchMatchesNotified(ctx context.Context, searchID string, adIDs []string) error
+}
+
+type WebhooksRepository interface {
+	CreateWebhook(ctx context.Context, w domain.Webhook) (domain.Webhook, error)
+	GetWebhook(ctx context.Context, id string) (*domain.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, w domain.Webhook) (*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) (bool, error)
+	DispatchEvents(ctx context.Context, limit int) (int64, error)
+	ClaimWebhookDelivery(ctx context.Context, leaseUntil time.Time) (*domain.WebhookDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, id string, attempt, statusCode int) error
+	FailWebhookDelivery(ctx context.Context, id string, attempt int, statusCode *int, reason string, nextAttemptAt *time.Time) error
+	RedeliverWebhookDelivery(ctx context.Context, webhookID, id string) (*domain.WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID string, status *string, page, pageSize int) ([]domain.WebhookDelivery, int, error)
+	DeleteOldEvents(ctx context.Context, createdBefore time.Time) (int64, error)
+}
+
+type EventsRepository interface {
+	SequenceEvents(ctx context.Context) (int64, error)
+	ListEventsAfter(ctx context.Context, afterSeq int64, limit int) ([]domain.Event, error)
+	LastEventSeq(ctx context.Context) (int64, error)
+	ListenEvents(ctx context.Context, wake chan<- struct{}) error
+}
+
 type QuotesRepository interface {
 	CreateQuote(ctx context.Context, brlToUsd float64, effectiveAt time.Time) (domain.Quote, error)
 	GetCurrentQuote(ctx context.Context) (*domain.Quote, error)
 }
 
+// AddressLookup resolves a CEP to its address; AddressService implements it.
+type AddressLookup interface {
+	Lookup(ctx context.Context, rawCEP string) (domain.Address, error)
+}
+
+// Geocoder resolves an address to coordinates. Implementations live in
+// integrations/geocode.
+type Geocoder interface {
+	Geocode(ctx context.Context, addr domain.Address) (domain.GeoPoint, error)
+}
+
+// SavedSearchNotifier tells the owner of a saved search about ads that
+// newly match it. Implementations live in integrations/notify.
+type SavedSearchNotifier interface {
+	Notify(ctx context.Context, search domain.SavedSearch, ads []domain.Ad) error
+}
+
+// WebhookSender posts an event to a webhook endpoint, signed with its
+// secret. It returns the HTTP status whenever a response was received.
+// Implementations live in integrations/webhook.
+type WebhookSender interface {
+	Send(ctx context.Context, d domain.WebhookDelivery) (int, error)
+}
+
 type ViaCEPClient interface {
 	Lookup(ctx context.Context, cep8digits string) (domain.Address, error)
 }
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+const (
+	// savedSearchMatchBatch is how many new ads are matched per round.
+	savedSearchMatchBatch = 200
+
+	// savedSearchNotifyBatch is how many pending matches are delivered per
+	// round.
+	savedSearchNotifyBatch = 500
+)
+
+// SavedSearchService keeps listing filters and alerts on the ads that match
+// them as they go live. Matching uses the listing filters themselves, so a
+// saved search finds exactly what GET /api/ads would.
+type SavedSearchService struct {
+	db       SavedSearchesRepository
+	ads      *AdsService
+	notifier SavedSearchNotifier
+}
+
+// NewSavedSearchService builds the service. notifier may be nil: matches are
+// still recorded and listed, but nobody is told about them.
+func NewSavedSearchService(db SavedSearchesRepository, ads *AdsService, notifier SavedSearchNotifier) *SavedSearchService {
+	return &SavedSearchService{db: db, ads: ads, notifier: notifier}
+}
+
+func (s *SavedSearchService) Create(ctx context.Context, in usecase.SavedSearchInput) (domain.SavedSearchResponse, error) {
+	search, err := s.savedSearch(ctx, in)
+	if err != nil {
+		return domain.SavedSearchResponse{}, err
+	}
+	created, err := s.db.CreateSavedSearch(ctx, search)
+	if err != nil {
+		return domain.SavedSearchResponse{}, err
+	}
+	return domain.ToSavedSearchResponse(created), nil
+}
+
+func (s *SavedSearchService) Get(ctx context.Context, id string) (domain.SavedSearchResponse, error) {
+	search, err := s.get(ctx, id)
+	if err != nil {
+		return domain.SavedSearchResponse{}, err
+	}
+	return domain.ToSavedSearchResponse(search), nil
+}
+
+func (s *SavedSearchService) List(ctx context.Context) (domain.SavedSearchListResponse, error) {
+	searches, err := s.db.ListSavedSearches(ctx)
+	if err != nil {
+		return domain.SavedSearchListResponse{}, err
+	}
+	resp := domain.SavedSearchListResponse{Items: make([]domain.SavedSearchResponse, 0, len(searches))}
+	for _, search := range searches {
+		resp.Items = append(resp.Items, domain.ToSavedSearchResponse(search))
+	}
+	return resp, nil
+}
+
+// Replace overwrites the name, contact and filters of a saved search. Ads
+// already matched stay listed; only ads going live from now on are matched
+// against the new filters.
+func (s *SavedSearchService) Replace(ctx context.Context, id string, in usecase.SavedSearchInput) (domain.SavedSearchResponse, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.SavedSearchResponse{}, savedSearchNotFound(id)
+	}
+	search, err := s.savedSearch(ctx, in)
+	if err != nil {
+		return domain.SavedSearchResponse{}, err
+	}
+	search.ID = id
+	updated, err := s.db.UpdateSavedSearch(ctx, search)
+	if err != nil {
+		return domain.SavedSearchResponse{}, err
+	}
+	if updated == nil {
+		return domain.SavedSearchResponse{}, savedSearchNotFound(id)
+	}
+	return domain.ToSavedSearchResponse(*updated), nil
+}
+
+func (s *SavedSearchService) Delete(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return savedSearchNotFound(id)
+	}
+	ok, err := s.db.DeleteSavedSearch(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return savedSearchNotFound(id)
+	}
+	return nil
+}
+
+// Matches lists the ads a saved search matched, newest first, priced at the
+// current quote.
+func (s *SavedSearchService) Matches(ctx context.Context, id string, page, pageSize int) (domain.SavedSearchMatchesResponse, error) {
+	details := map[string]string{}
+	if page < 1 {
+		details["page"] = "must be >= 1"
+	}
+	if pageSize < 1 || pageSize > 50 {
+		details["page_size"] = "must be between 1 and 50"
+	}
+	if len(details) > 0 {
+		return domain.SavedSearchMatchesResponse{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", details)
+	}
+
+	if _, err := s.get(ctx, id); err != nil {
+		return domain.SavedSearchMatchesResponse{}, err
+	}
+	matches, total, err := s.db.ListSavedSearchMatches(ctx, id, page, pageSize)
+	if err != nil {
+		return domain.SavedSearchMatchesResponse{}, err
+	}
+	quote, err := s.ads.db.GetCurrentQuote(ctx)
+	if err != nil {
+		return domain.SavedSearchMatchesResponse{}, err
+	}
+
+	resp := domain.SavedSearchMatchesResponse{
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     total,
+		QuoteUsed: domain.ToQuoteUsed(quote),
+		Items:     make([]domain.SavedSearchMatchItem, 0, len(matches)),
+	}
+	for _, m := range matches {
+		resp.Items = append(resp.Items, domain.SavedSearchMatchItem{
+			MatchedAt:  m.MatchedAt,
+			NotifiedAt: m.NotifiedAt,
+			Ad:         domain.ToAdItemWithQuote(m.Ad, quote, s.ads.images),
+		})
+	}
+	return resp, nil
+}
+
+// ProcessNewAds matches the ads that went live since the last run against
+// every saved search, then hands the pending matches to the notifier. It is
+// run periodically by the saved searches worker.
+func (s *SavedSearchService) ProcessNewAds(ctx context.Context) error {
+	if err := s.matchNewAds(ctx); err != nil {
+		return err
+	}
+	return s.notifyMatches(ctx)
+}
+
+func (s *SavedSearchService) matchNewAds(ctx context.Context) error {
+	searches, err := s.db.ListSavedSearches(ctx)
+	if err != nil {
+		return err
+	}
+	searches, filters, err := s.filters(ctx, searches)
+	if err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		ids, err := s.db.ClaimUnmatchedAds(ctx, savedSearchMatchBatch)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := s.match(ctx, searches, filters, ids); err != nil {
+			// Hand the ads back so the next run matches them again; matches
+			// already recorded are not duplicated.
+			if rerr := s.db.ReleaseUnmatchedAds(context.WithoutCancel(ctx), ids); rerr != nil {
+				err = stderrors.Join(err, rerr)
+			}
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// filters turns the saved searches into listing filters at the current
+// quote. Searches the listing would now reject (a USD bound with no quote)
+// are left out of this run.
+func (s *SavedSearchService) filters(ctx context.Context, searches []domain.SavedSearch) ([]domain.SavedSearch, []repo.AdsFilter, error) {
+	kept := make([]domain.SavedSearch, 0, len(searches))
+	filters := make([]repo.AdsFilter, 0, len(searches))
+	skip := func(search domain.SavedSearch, err error) {
+		slog.Warn("saved_search_skipped",
+			slog.String("saved_search_id", search.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+	for _, search := range searches {
+		var in usecase.ListAdsInput
+		if err := json.Unmarshal(search.Filters, &in); err != nil {
+			skip(search, err)
+			continue
+		}
+		f, _, err := s.ads.listFilter(ctx, in)
+		var appErr *errors.AppError
+		if stderrors.As(err, &appErr) {
+			skip(search, err)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		kept = append(kept, search)
+		filters = append(filters, f)
+	}
+	return kept, filters, nil
+}
+
+// match records which of the ads each saved search finds.
+func (s *SavedSearchService) match(ctx context.Context, searches []domain.SavedSearch, filters []repo.AdsFilter, adIDs []string) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	matched, err := s.db.MatchAds(ctx, adIDs, filters)
+	if err != nil {
+		return err
+	}
+	for i, ids := range matched {
+		if len(ids) == 0 {
+			continue
+		}
+		if err := s.db.RecordSavedSearchMatches(ctx, searches[i].ID, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyMatches delivers the pending matches, one call per saved search. A
+// failed delivery is logged and retried on the next run.
+func (s *SavedSearchService) notifyMatches(ctx context.Context) error {
+	if s.notifier == nil {
+		return nil
+	}
+	pending, err := s.db.ListPendingSavedSearchMatches(ctx, savedSearchNotifyBatch)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for start := 0; start < len(pending); {
+		end := start + 1
+		for end < len(pending) && pending[end].SearchID == pending[start].SearchID {
+			end++
+		}
+		if err := s.notify(ctx, pending[start:end]); err != nil {
+			errs = append(errs, err)
+		}
+		start = end
+	}
+	return stderrors.Join(errs...)
+}
+
+func (s *SavedSearchService) notify(ctx context.Context, matches []domain.SavedSearchMatch) error {
+	searchID := matches[0].SearchID
+	search, err := s.db.GetSavedSearch(ctx, searchID)
+	if err != nil || search == nil {
+		// Deleted meanwhile: its matches went with it.
+		return err
+	}
+
+	ads := make([]domain.Ad, len(matches))
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ads[i], ids[i] = m.Ad, m.Ad.ID
+	}
+	if err := s.notifier.Notify(ctx, *search, ads); err != nil {
+		slog.Warn("saved_search_notify_failed",
+			slog.String("saved_search_id", searchID),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return s.db.MarkSavedSearchMatchesNotified(ctx, searchID, ids)
+}
+
+// savedSearch validates in and turns it into the stored saved search.
+// Pagination and sort do not apply to alerts and are dropped.
+func (s *SavedSearchService) savedSearch(ctx context.Context, in usecase.SavedSearchInput) (domain.SavedSearch, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Email != nil {
+		in.Email = emptyToNil(strings.TrimSpace(*in.Email))
+	}
+	if err := validation.ValidateSavedSearchInput(in); err != nil {
+		return domain.SavedSearch{}, err
+	}
+	f := in.Filters
+	f.Page, f.PageSize, f.Cursor, f.WithTotal, f.Sort = 0, 0, nil, false, ""
+
+	// Fail now rather than in the matcher when the filters cannot be applied.
+	if _, _, err := s.ads.listFilter(ctx, f); err != nil {
+		return domain.SavedSearch{}, err
+	}
+
+	filters, err := json.Marshal(f)
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+	return domain.SavedSearch{Name: in.Name, Email: in.Email, Filters: filters, Query: in.Query}, nil
+}
+
+func (s *SavedSearchService) get(ctx context.Context, id string) (domain.SavedSearch, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.SavedSearch{}, savedSearchNotFound(id)
+	}
+	search, err := s.db.GetSavedSearch(ctx, id)
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+	if search == nil {
+		return domain.SavedSearch{}, savedSearchNotFound(id)
+	}
+	return *search, nil
+}
+
+func savedSearchNotFound(id string) error {
+	return errors.New(http.StatusNotFound, "SAVED_SEARCH_NOT_FOUND", "Busca salva não encontrada.", map[string]string{"id": id})
+}
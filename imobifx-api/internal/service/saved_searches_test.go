@@ -0,0 +1,265 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/repo"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// fakeSavedSearchesRepo keeps saved searches and matches in memory. New ads
+// wait in unmatched until claimed; matchFn decides what each filter finds.
+type fakeSavedSearchesRepo struct {
+	searches  []domain.SavedSearch
+	unmatched []string
+	released  []string
+	matches   []domain.SavedSearchMatch
+	filters   []repo.AdsFilter
+
+	matchFn func(adIDs []string, f repo.AdsFilter) ([]string, error)
+}
+
+func (f *fakeSavedSearchesRepo) CreateSavedSearch(ctx context.Context, s domain.SavedSearch) (domain.SavedSearch, error) {
+	s.ID = "00000000-0000-0000-0000-00000000000" + string(rune('1'+len(f.searches)))
+	f.searches = append(f.searches, s)
+	return s, nil
+}
+
+func (f *fakeSavedSearchesRepo) GetSavedSearch(ctx context.Context, id string) (*domain.SavedSearch, error) {
+	for _, s := range f.searches {
+		if s.ID == id {
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeSavedSearchesRepo) ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	return f.searches, nil
+}
+
+func (f *fakeSavedSearchesRepo) UpdateSavedSearch(ctx context.Context, s domain.SavedSearch) (*domain.SavedSearch, error) {
+	for i := range f.searches {
+		if f.searches[i].ID == s.ID {
+			f.searches[i] = s
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeSavedSearchesRepo) DeleteSavedSearch(ctx context.Context, id string) (bool, error) {
+	for i := range f.searches {
+		if f.searches[i].ID == id {
+			f.searches = append(f.searches[:i], f.searches[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeSavedSearchesRepo) ClaimUnmatchedAds(ctx context.Context, limit int) ([]string, error) {
+	n := min(limit, len(f.unmatched))
+	ids := f.unmatched[:n]
+	f.unmatched = f.unmatched[n:]
+	return ids, nil
+}
+
+func (f *fakeSavedSearchesRepo) ReleaseUnmatchedAds(ctx context.Context, ids []string) error {
+	f.released = append(f.released, ids...)
+	return nil
+}
+
+func (f *fakeSavedSearchesRepo) MatchAds(ctx context.Context, adIDs []string, filters []repo.AdsFilter) ([][]string, error) {
+	f.filters = append(f.filters, filters...)
+	out := make([][]string, len(filters))
+	for i, flt := range filters {
+		ids, err := f.matchFn(adIDs, flt)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = ids
+	}
+	return out, nil
+}
+
+func (f *fakeSavedSearchesRepo) RecordSavedSearchMatches(ctx context.Context, searchID string, adIDs []string) error {
+	for _, id := range adIDs {
+		f.matches = append(f.matches, domain.SavedSearchMatch{SearchID: searchID, Ad: domain.Ad{ID: id}})
+	}
+	return nil
+}
+
+func (f *fakeSavedSearchesRepo) ListSavedSearchMatches(ctx context.Context, searchID string, page, pageSize int) ([]domain.SavedSearchMatch, int, error) {
+	var out []domain.SavedSearchMatch
+	for _, m := range f.matches {
+		if m.SearchID == searchID {
+			out = append(out, m)
+		}
+	}
+	return out, len(out), nil
+}
+
+func (f *fakeSavedSearchesRepo) ListPendingSavedSearchMatches(ctx context.Context, limit int) ([]domain.SavedSearchMatch, error) {
+	var out []domain.SavedSearchMatch
+	for _, m := range f.matches {
+		if m.NotifiedAt == nil {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSavedSearchesRepo) MarkSavedSearchMatchesNotified(ctx context.Context, searchID string, adIDs []string) error {
+	now := time.Now()
+	for i, m := range f.matches {
+		if m.SearchID == searchID && slices.Contains(adIDs, m.Ad.ID) {
+			f.matches[i].NotifiedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeNotifier struct {
+	sent map[string][]string
+	fail string
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, search domain.SavedSearch, ads []domain.Ad) error {
+	if search.ID == n.fail {
+		return stderrors.New("mailer down")
+	}
+	if n.sent == nil {
+		n.sent = map[string][]string{}
+	}
+	for _, a := range ads {
+		n.sent[search.ID] = append(n.sent[search.ID], a.ID)
+	}
+	return nil
+}
+
+func newSavedSearchService(t *testing.T, db *fakeSavedSearchesRepo, notifier service.SavedSearchNotifier) *service.SavedSearchService {
+	t.Helper()
+	ads := service.NewAdsService(&fakeAdsRepo{}, localStore(t, t.TempDir()), localStore(t, t.TempDir()), nil, 5*1024*1024, maxImages, retention, ttl)
+	return service.NewSavedSearchService(db, ads, notifier)
+}
+
+func TestSavedSearchService_Create_KeepsFiltersWithoutPagination(t *testing.T) {
+	db := &fakeSavedSearchesRepo{}
+	svc := newSavedSearchService(t, db, nil)
+
+	resp, err := svc.Create(context.Background(), usecase.SavedSearchInput{
+		Name:    "  Aluguel em JP ",
+		Filters: usecase.ListAdsInput{Page: 2, PageSize: 20, Sort: domain.AdSortPriceAsc, Type: ptr("RENT"), City: ptr("João Pessoa"), MaxPrice: ptr(3000.0)},
+		Query:   "type=RENT&city=Jo%C3%A3o+Pessoa&max_price=3000",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Aluguel em JP", resp.Name)
+	require.Equal(t, "type=RENT&city=Jo%C3%A3o+Pessoa&max_price=3000", resp.Query)
+
+	var stored usecase.ListAdsInput
+	require.NoError(t, json.Unmarshal(db.searches[0].Filters, &stored))
+	require.Zero(t, stored.Page)
+	require.Empty(t, stored.Sort)
+	require.Equal(t, "RENT", *stored.Type)
+	require.Equal(t, 3000.0, *stored.MaxPrice)
+}
+
+func TestSavedSearchService_Create_RejectsFiltersThatCannotApply(t *testing.T) {
+	db := &fakeSavedSearchesRepo{}
+	svc := newSavedSearchService(t, db, nil)
+
+	// Status makes no sense for alerts on ads going live.
+	_, err := svc.Create(context.Background(), usecase.SavedSearchInput{Name: "Pausados", Filters: usecase.ListAdsInput{Page: 1, PageSize: 10, Status: ptr("PAUSED")}})
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+
+	// USD bounds need a quote.
+	_, err = svc.Create(context.Background(), usecase.SavedSearchInput{Name: "Em dolar", Filters: usecase.ListAdsInput{Page: 1, PageSize: 10, MaxPriceUSD: ptr(600.0)}})
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "QUOTE_NOT_FOUND", appErr.Code)
+	require.Empty(t, db.searches)
+}
+
+func TestSavedSearchService_ProcessNewAds_MatchesAndNotifies(t *testing.T) {
+	db := &fakeSavedSearchesRepo{
+		unmatched: []string{"a1", "a2", "a3"},
+		matchFn: func(adIDs []string, f repo.AdsFilter) ([]string, error) {
+			if f.City != nil {
+				return []string{"a2"}, nil
+			}
+			return adIDs, nil
+		},
+	}
+	notifier := &fakeNotifier{}
+	svc := newSavedSearchService(t, db, notifier)
+
+	jp, err := svc.Create(context.Background(), usecase.SavedSearchInput{Name: "JP", Filters: usecase.ListAdsInput{Page: 1, PageSize: 10, City: ptr("João Pessoa")}})
+	require.NoError(t, err)
+	all, err := svc.Create(context.Background(), usecase.SavedSearchInput{Name: "Tudo", Filters: usecase.ListAdsInput{Page: 1, PageSize: 10}})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.ProcessNewAds(context.Background()))
+
+	// The saved filters apply as the listing applies them: live ACTIVE ads.
+	require.Len(t, db.filters, 2)
+	require.Equal(t, domain.AdStatusActive, *db.filters[0].Status)
+	require.True(t, db.filters[0].OnlyLive)
+	require.Equal(t, "João Pessoa", *db.filters[0].City)
+
+	require.Equal(t, []string{"a2"}, notifier.sent[jp.ID])
+	require.Equal(t, []string{"a1", "a2", "a3"}, notifier.sent[all.ID])
+
+	matches, err := svc.Matches(context.Background(), jp.ID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, matches.Total)
+	require.Equal(t, "a2", matches.Items[0].Ad.ID)
+	require.NotNil(t, matches.Items[0].NotifiedAt)
+
+	// Nothing new: no second alert.
+	require.NoError(t, svc.ProcessNewAds(context.Background()))
+	require.Len(t, notifier.sent[jp.ID], 1)
+}
+
+func TestSavedSearchService_ProcessNewAds_RetriesFailures(t *testing.T) {
+	matchErr := stderrors.New("connection reset")
+	db := &fakeSavedSearchesRepo{
+		unmatched: []string{"a1"},
+		matchFn: func(adIDs []string, f repo.AdsFilter) ([]string, error) {
+			return nil, matchErr
+		},
+	}
+	notifier := &fakeNotifier{}
+	svc := newSavedSearchService(t, db, notifier)
+	s1, err := svc.Create(context.Background(), usecase.SavedSearchInput{Name: "Tudo", Filters: usecase.ListAdsInput{Page: 1, PageSize: 10}})
+	require.NoError(t, err)
+	s2, err := svc.Create(context.Background(), usecase.SavedSearchInput{Name: "Tudo 2", Filters: usecase.ListAdsInput{Page: 1, PageSize: 10}})
+	require.NoError(t, err)
+
+	// A failed match hands the claimed ads back.
+	require.ErrorIs(t, svc.ProcessNewAds(context.Background()), matchErr)
+	require.Equal(t, []string{"a1"}, db.released)
+
+	// A failed delivery leaves the matches pending; other searches still get
+	// theirs.
+	db.unmatched = db.released
+	db.matchFn = func(adIDs []string, f repo.AdsFilter) ([]string, error) { return adIDs, nil }
+	notifier.fail = s1.ID
+	require.Error(t, svc.ProcessNewAds(context.Background()))
+	require.Equal(t, []string{"a1"}, notifier.sent[s2.ID])
+
+	notifier.fail = ""
+	require.NoError(t, svc.ProcessNewAds(context.Background()))
+	require.Equal(t, []string{"a1"}, notifier.sent[s1.ID])
+}
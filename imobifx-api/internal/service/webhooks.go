@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+const (
+	// webhookDispatchBatch is how many outbox events are fanned out to the
+	// webhooks per round.
+	webhookDispatchBatch = 500
+
+	// webhookClaimTimeout is how long an attempt may take before the delivery
+	// is due again. It must exceed the sender timeout.
+	webhookClaimTimeout = 5 * time.Minute
+
+	// webhookWorkers is how many deliveries are attempted at once, so a slow
+	// endpoint holds up one worker instead of every webhook. Claims skip
+	// locked rows, so workers never take the same delivery.
+	webhookWorkers = 8
+
+	webhookSecretBytes = 32
+)
+
+// WebhookService manages webhook subscriptions and delivers the outbox
+// events to them. Deliveries that fail are retried with exponential backoff,
+// from retryBase up to retryMax between attempts, and left DEAD after
+// maxAttempts.
+type WebhookService struct {
+	db          WebhooksRepository
+	sender      WebhookSender
+	maxAttempts int
+	retryBase   time.Duration
+	retryMax    time.Duration
+	retention   time.Duration
+}
+
+func NewWebhookService(db WebhooksRepository, sender WebhookSender, maxAttempts int, retryBase, retryMax, retention time.Duration) *WebhookService {
+	return &WebhookService{
+		db:          db,
+		sender:      sender,
+		maxAttempts: maxAttempts,
+		retryBase:   retryBase,
+		retryMax:    retryMax,
+		retention:   retention,
+	}
+}
+
+// Create registers a webhook. The response is the only one carrying its
+// secret, generated unless given.
+func (s *WebhookService) Create(ctx context.Context, in usecase.WebhookInput) (domain.WebhookResponse, error) {
+	w, err := webhook(in)
+	if err != nil {
+		return domain.WebhookResponse{}, err
+	}
+	if w.Secret == "" {
+		if w.Secret, err = newWebhookSecret(); err != nil {
+			return domain.WebhookResponse{}, err
+		}
+	}
+	created, err := s.db.CreateWebhook(ctx, w)
+	if err != nil {
+		return domain.WebhookResponse{}, err
+	}
+	resp := domain.ToWebhookResponse(created)
+	resp.Secret = &created.Secret
+	return resp, nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id string) (domain.WebhookResponse, error) {
+	w, err := s.get(ctx, id)
+	if err != nil {
+		return domain.WebhookResponse{}, err
+	}
+	return domain.ToWebhookResponse(w), nil
+}
+
+func (s *WebhookService) List(ctx context.Context) (domain.WebhookListResponse, error) {
+	hooks, err := s.db.ListWebhooks(ctx)
+	if err != nil {
+		return domain.WebhookListResponse{}, err
+	}
+	resp := domain.WebhookListResponse{Items: make([]domain.WebhookResponse, 0, len(hooks))}
+	for _, w := range hooks {
+		resp.Items = append(resp.Items, domain.ToWebhookResponse(w))
+	}
+	return resp, nil
+}
+
+// Replace overwrites the URL, events and state of a webhook. The secret is
+// rotated only when a new one is given. Events already queued keep being
+// delivered, to the new URL.
+func (s *WebhookService) Replace(ctx context.Context, id string, in usecase.WebhookInput) (domain.WebhookResponse, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.WebhookResponse{}, webhookNotFound(id)
+	}
+	w, err := webhook(in)
+	if err != nil {
+		return domain.WebhookResponse{}, err
+	}
+	w.ID = id
+	updated, err := s.db.UpdateWebhook(ctx, w)
+	if err != nil {
+		return domain.WebhookResponse{}, err
+	}
+	if updated == nil {
+		return domain.WebhookResponse{}, webhookNotFound(id)
+	}
+	return domain.ToWebhookResponse(*updated), nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return webhookNotFound(id)
+	}
+	ok, err := s.db.DeleteWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return webhookNotFound(id)
+	}
+	return nil
+}
+
+// Deliveries lists the delivery log of a webhook, newest first, optionally
+// only the deliveries in status.
+func (s *WebhookService) Deliveries(ctx context.Context, id, status string, page, pageSize int) (domain.WebhookDeliveriesResponse, error) {
+	details := map[string]string{}
+	if status != "" && !slices.Contains(domain.WebhookDeliveryStatuses, status) {
+		details["status"] = "must be one of " + strings.Join(domain.WebhookDeliveryStatuses, ", ")
+	}
+	if page < 1 {
+		details["page"] = "must be >= 1"
+	}
+	if pageSize < 1 || pageSize > 50 {
+		details["page_size"] = "must be between 1 and 50"
+	}
+	if len(details) > 0 {
+		return domain.WebhookDeliveriesResponse{}, errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", details)
+	}
+
+	if _, err := s.get(ctx, id); err != nil {
+		return domain.WebhookDeliveriesResponse{}, err
+	}
+	deliveries, total, err := s.db.ListWebhookDeliveries(ctx, id, emptyToNil(status), page, pageSize)
+	if err != nil {
+		return domain.WebhookDeliveriesResponse{}, err
+	}
+
+	resp := domain.WebhookDeliveriesResponse{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+		Items:    make([]domain.WebhookDeliveryItem, 0, len(deliveries)),
+	}
+	for _, d := range deliveries {
+		resp.Items = append(resp.Items, domain.ToWebhookDeliveryItem(d))
+	}
+	return resp, nil
+}
+
+// Redeliver queues a delivery again with a fresh count of attempts, typically
+// a DEAD one once the endpoint is fixed.
+func (s *WebhookService) Redeliver(ctx context.Context, id, deliveryID string) (domain.WebhookDeliveryItem, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.WebhookDeliveryItem{}, webhookNotFound(id)
+	}
+	if _, err := uuid.Parse(deliveryID); err != nil {
+		return domain.WebhookDeliveryItem{}, webhookDeliveryNotFound(deliveryID)
+	}
+	d, err := s.db.RedeliverWebhookDelivery(ctx, id, deliveryID)
+	if err != nil {
+		return domain.WebhookDeliveryItem{}, err
+	}
+	if d == nil {
+		return domain.WebhookDeliveryItem{}, webhookDeliveryNotFound(deliveryID)
+	}
+	return domain.ToWebhookDeliveryItem(*d), nil
+}
+
+// ProcessOutbox fans the new outbox events out to the subscribed webhooks,
+// then makes every due delivery attempt, webhookWorkers at a time. It is run
+// periodically by the webhooks worker. Failed attempts are recorded, not
+// returned.
+func (s *WebhookService) ProcessOutbox(ctx context.Context) error {
+	for {
+		n, err := s.db.DispatchEvents(ctx, webhookDispatchBatch)
+		if err != nil {
+			return err
+		}
+		if n < webhookDispatchBatch {
+			break
+		}
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for range webhookWorkers {
+		g.Go(func() error { return s.deliverDue(ctx) })
+	}
+	return g.Wait()
+}
+
+// deliverDue makes delivery attempts until none is due.
+func (s *WebhookService) deliverDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		d, err := s.db.ClaimWebhookDelivery(ctx, time.Now().UTC().Add(webhookClaimTimeout))
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return nil
+		}
+		if err := s.deliver(ctx, *d); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// PurgeEvents removes the outbox events older than the retention whose
+// deliveries are over, with their delivery log.
+func (s *WebhookService) PurgeEvents(ctx context.Context) (int64, error) {
+	return s.db.DeleteOldEvents(ctx, time.Now().UTC().Add(-s.retention))
+}
+
+func (s *WebhookService) deliver(ctx context.Context, d domain.WebhookDelivery) error {
+	status, err := s.sender.Send(ctx, d)
+	if err == nil {
+		return s.db.CompleteWebhookDelivery(ctx, d.ID, d.Attempts, status)
+	}
+	if ctx.Err() != nil {
+		// Shutting down: the delivery is due again once the claim expires.
+		return ctx.Err()
+	}
+
+	var code *int
+	if status > 0 {
+		code = &status
+	}
+	var next *time.Time
+	if d.Attempts < s.maxAttempts {
+		t := time.Now().UTC().Add(s.backoff(d.Attempts))
+		next = &t
+	}
+	slog.Warn("webhook_delivery_failed",
+		slog.String("webhook_id", d.WebhookID),
+		slog.String("delivery_id", d.ID),
+		slog.Int("attempt", d.Attempts),
+		slog.Bool("dead", next == nil),
+		slog.String("error", err.Error()),
+	)
+	return s.db.FailWebhookDelivery(ctx, d.ID, d.Attempts, code, err.Error(), next)
+}
+
+// backoff is the wait after the given failed attempt: retryBase doubled for
+// each earlier attempt, capped at retryMax.
+func (s *WebhookService) backoff(attempt int) time.Duration {
+	wait := s.retryBase
+	for i := 1; i < attempt && wait < s.retryMax; i++ {
+		wait *= 2
+	}
+	return min(wait, s.retryMax)
+}
+
+func (s *WebhookService) get(ctx context.Context, id string) (domain.Webhook, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.Webhook{}, webhookNotFound(id)
+	}
+	w, err := s.db.GetWebhook(ctx, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if w == nil {
+		return domain.Webhook{}, webhookNotFound(id)
+	}
+	return *w, nil
+}
+
+// webhook validates in and turns it into the stored webhook. Repeated event
+// types are dropped; an empty secret means none was given.
+func webhook(in usecase.WebhookInput) (domain.Webhook, error) {
+	in.URL = strings.TrimSpace(in.URL)
+	if err := validation.ValidateWebhookInput(in); err != nil {
+		return domain.Webhook{}, err
+	}
+	w := domain.Webhook{URL: in.URL, Active: true}
+	for _, e := range in.Events {
+		if !slices.Contains(w.Events, e) {
+			w.Events = append(w.Events, e)
+		}
+	}
+	if in.Secret != nil {
+		w.Secret = *in.Secret
+	}
+	if in.Active != nil {
+		w.Active = *in.Active
+	}
+	return w, nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func webhookNotFound(id string) error {
+	return errors.New(http.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook não encontrado.", map[string]string{"id": id})
+}
+
+func webhookDeliveryNotFound(id string) error {
+	return errors.New(http.StatusNotFound, "WEBHOOK_DELIVERY_NOT_FOUND", "Entrega de webhook não encontrada.", map[string]string{"id": id})
+}
@@ -0,0 +1,281 @@
+package service_test
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/service"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// fakeWebhooksRepo keeps webhooks and deliveries in memory. Claims and
+// attempt results follow the repository contract: a claim counts the attempt
+// and leases the delivery.
+type fakeWebhooksRepo struct {
+	mu         sync.Mutex
+	hooks      []domain.Webhook
+	deliveries []domain.WebhookDelivery
+	dispatched int
+}
+
+func (f *fakeWebhooksRepo) CreateWebhook(ctx context.Context, w domain.Webhook) (domain.Webhook, error) {
+	w.ID = "00000000-0000-0000-0000-00000000000" + string(rune('1'+len(f.hooks)))
+	f.hooks = append(f.hooks, w)
+	return w, nil
+}
+
+func (f *fakeWebhooksRepo) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	for _, w := range f.hooks {
+		if w.ID == id {
+			return &w, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeWebhooksRepo) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return f.hooks, nil
+}
+
+func (f *fakeWebhooksRepo) UpdateWebhook(ctx context.Context, w domain.Webhook) (*domain.Webhook, error) {
+	for i := range f.hooks {
+		if f.hooks[i].ID == w.ID {
+			if w.Secret == "" {
+				w.Secret = f.hooks[i].Secret
+			}
+			f.hooks[i] = w
+			return &w, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeWebhooksRepo) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeWebhooksRepo) DispatchEvents(ctx context.Context, limit int) (int64, error) {
+	f.dispatched++
+	return 0, nil
+}
+
+func (f *fakeWebhooksRepo) ClaimWebhookDelivery(ctx context.Context, leaseUntil time.Time) (*domain.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, d := range f.deliveries {
+		if d.Status == domain.WebhookDeliveryPending && !d.NextAttemptAt.After(time.Now()) {
+			f.deliveries[i].Attempts++
+			f.deliveries[i].NextAttemptAt = &leaseUntil
+			out := f.deliveries[i]
+			return &out, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeWebhooksRepo) delivery(id string, attempt int) *domain.WebhookDelivery {
+	for i := range f.deliveries {
+		if f.deliveries[i].ID == id && f.deliveries[i].Attempts == attempt {
+			return &f.deliveries[i]
+		}
+	}
+	return nil
+}
+
+func (f *fakeWebhooksRepo) CompleteWebhookDelivery(ctx context.Context, id string, attempt, statusCode int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d := f.delivery(id, attempt); d != nil {
+		d.Status, d.LastStatusCode, d.NextAttemptAt = domain.WebhookDeliveryDelivered, &statusCode, nil
+	}
+	return nil
+}
+
+func (f *fakeWebhooksRepo) FailWebhookDelivery(ctx context.Context, id string, attempt int, statusCode *int, reason string, nextAttemptAt *time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d := f.delivery(id, attempt); d != nil {
+		d.LastStatusCode, d.LastError, d.NextAttemptAt = statusCode, &reason, nextAttemptAt
+		if nextAttemptAt == nil {
+			d.Status = domain.WebhookDeliveryDead
+		}
+	}
+	return nil
+}
+
+func (f *fakeWebhooksRepo) RedeliverWebhookDelivery(ctx context.Context, webhookID, id string) (*domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (f *fakeWebhooksRepo) ListWebhookDeliveries(ctx context.Context, webhookID string, status *string, page, pageSize int) ([]domain.WebhookDelivery, int, error) {
+	return f.deliveries, len(f.deliveries), nil
+}
+
+func (f *fakeWebhooksRepo) DeleteOldEvents(ctx context.Context, createdBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+// due makes every pending delivery due now, as if its backoff elapsed.
+func (f *fakeWebhooksRepo) due() {
+	now := time.Now().Add(-time.Second)
+	for i := range f.deliveries {
+		if f.deliveries[i].Status == domain.WebhookDeliveryPending {
+			f.deliveries[i].NextAttemptAt = &now
+		}
+	}
+}
+
+type fakeSender struct {
+	status int
+	block  func(domain.WebhookDelivery)
+
+	mu   sync.Mutex
+	sent []string
+}
+
+func (s *fakeSender) Send(ctx context.Context, d domain.WebhookDelivery) (int, error) {
+	if s.block != nil {
+		s.block(d)
+	}
+	s.mu.Lock()
+	s.sent = append(s.sent, d.ID)
+	s.mu.Unlock()
+	if s.status >= 300 {
+		return s.status, stderrors.New("unexpected status")
+	}
+	return s.status, nil
+}
+
+func TestWebhookService_Create_ReturnsSecretOnce(t *testing.T) {
+	db := &fakeWebhooksRepo{}
+	svc := service.NewWebhookService(db, &fakeSender{}, 3, time.Minute, time.Hour, time.Hour)
+
+	created, err := svc.Create(context.Background(), usecase.WebhookInput{
+		URL:    " https://crm.example.com/hooks ",
+		Events: []string{"ad.created", "ad.updated", "ad.created"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://crm.example.com/hooks", created.URL)
+	require.Equal(t, []string{"ad.created", "ad.updated"}, created.Events)
+	require.True(t, created.Active)
+	require.NotNil(t, created.Secret)
+	require.Len(t, *created.Secret, 64)
+
+	got, err := svc.Get(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Nil(t, got.Secret)
+
+	// Replacing without a secret keeps the current one.
+	off := false
+	_, err = svc.Replace(context.Background(), created.ID, usecase.WebhookInput{URL: "https://crm.example.com/v2", Events: []string{"quote.created"}, Active: &off})
+	require.NoError(t, err)
+	require.Equal(t, *created.Secret, db.hooks[0].Secret)
+	require.False(t, db.hooks[0].Active)
+
+	_, err = svc.Get(context.Background(), "not-a-uuid")
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "WEBHOOK_NOT_FOUND", appErr.Code)
+}
+
+func TestWebhookService_ProcessOutbox_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	db := &fakeWebhooksRepo{deliveries: []domain.WebhookDelivery{
+		{ID: "d1", WebhookID: "w1", Status: domain.WebhookDeliveryPending, NextAttemptAt: &past},
+	}}
+	sender := &fakeSender{status: http.StatusBadGateway}
+	svc := service.NewWebhookService(db, sender, 3, time.Minute, 90*time.Second, time.Hour)
+
+	// First failure waits retryBase.
+	start := time.Now()
+	require.NoError(t, svc.ProcessOutbox(context.Background()))
+	require.Equal(t, 1, db.dispatched)
+	d := db.deliveries[0]
+	require.Equal(t, domain.WebhookDeliveryPending, d.Status)
+	require.Equal(t, http.StatusBadGateway, *d.LastStatusCode)
+	require.WithinDuration(t, start.Add(time.Minute), *d.NextAttemptAt, 5*time.Second)
+
+	// Not due yet: nothing is sent.
+	require.NoError(t, svc.ProcessOutbox(context.Background()))
+	require.Len(t, sender.sent, 1)
+
+	// The second wait doubles, capped at retryMax.
+	db.due()
+	require.NoError(t, svc.ProcessOutbox(context.Background()))
+	require.WithinDuration(t, time.Now().Add(90*time.Second), *db.deliveries[0].NextAttemptAt, 5*time.Second)
+
+	// The last attempt dead-letters it.
+	db.due()
+	require.NoError(t, svc.ProcessOutbox(context.Background()))
+	d = db.deliveries[0]
+	require.Equal(t, domain.WebhookDeliveryDead, d.Status)
+	require.Equal(t, 3, d.Attempts)
+	require.Nil(t, d.NextAttemptAt)
+	require.Len(t, sender.sent, 3)
+}
+
+func TestWebhookService_ProcessOutbox_Delivers(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	db := &fakeWebhooksRepo{deliveries: []domain.WebhookDelivery{
+		{ID: "d1", WebhookID: "w1", Status: domain.WebhookDeliveryPending, NextAttemptAt: &past},
+		{ID: "d2", WebhookID: "w1", Status: domain.WebhookDeliveryPending, NextAttemptAt: &past},
+	}}
+	sender := &fakeSender{status: http.StatusOK}
+	svc := service.NewWebhookService(db, sender, 3, time.Minute, time.Hour, time.Hour)
+
+	require.NoError(t, svc.ProcessOutbox(context.Background()))
+	require.ElementsMatch(t, []string{"d1", "d2"}, sender.sent)
+	for _, d := range db.deliveries {
+		require.Equal(t, domain.WebhookDeliveryDelivered, d.Status)
+		require.Equal(t, 1, d.Attempts)
+	}
+}
+
+func TestWebhookService_ProcessOutbox_SlowEndpointDoesNotHoldUpOthers(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	db := &fakeWebhooksRepo{deliveries: []domain.WebhookDelivery{
+		{ID: "slow", WebhookID: "w1", Status: domain.WebhookDeliveryPending, NextAttemptAt: &past},
+		{ID: "d2", WebhookID: "w2", Status: domain.WebhookDeliveryPending, NextAttemptAt: &past},
+		{ID: "d3", WebhookID: "w2", Status: domain.WebhookDeliveryPending, NextAttemptAt: &past},
+	}}
+	others := make(chan struct{}, 2)
+	sender := &fakeSender{status: http.StatusOK, block: func(d domain.WebhookDelivery) {
+		if d.ID != "slow" {
+			others <- struct{}{}
+			return
+		}
+		// The slow endpoint answers only after the others were sent.
+		for range 2 {
+			select {
+			case <-others:
+			case <-time.After(5 * time.Second):
+				t.Error("deliveries waited for the slow endpoint")
+				return
+			}
+		}
+	}}
+	svc := service.NewWebhookService(db, sender, 3, time.Minute, time.Hour, time.Hour)
+
+	require.NoError(t, svc.ProcessOutbox(context.Background()))
+	require.ElementsMatch(t, []string{"slow", "d2", "d3"}, sender.sent)
+	for _, d := range db.deliveries {
+		require.Equal(t, domain.WebhookDeliveryDelivered, d.Status)
+	}
+}
+
+func TestWebhookService_Deliveries_ValidatesStatus(t *testing.T) {
+	svc := service.NewWebhookService(&fakeWebhooksRepo{}, &fakeSender{}, 3, time.Minute, time.Hour, time.Hour)
+
+	_, err := svc.Deliveries(context.Background(), "00000000-0000-0000-0000-000000000001", "FAILED", 1, 10)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "VALIDATION_ERROR", appErr.Code)
+}
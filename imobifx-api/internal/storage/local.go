@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local keeps objects as files in a directory. baseURL is the prefix the
+// directory is served under; it may be empty for directories that are never
+// served.
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocal(dir, baseURL string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+// Dir is the directory holding the files, for serving them statically.
+func (l *Local) Dir() string { return l.dir }
+
+// Put writes to a temporary file and renames it, so readers never see a
+// partial object.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	tmp, err := os.CreateTemp(l.dir, ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), l.path(key))
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(l.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	err := os.Remove(l.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (l *Local) URL(key string) string {
+	return l.baseURL + "/" + key
+}
+
+func (l *Local) List(ctx context.Context, fn func(Object) error) error {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(Object{Key: e.Name(), Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// path confines key to the storage directory.
+func (l *Local) path(key string) string {
+	return filepath.Join(l.dir, filepath.Base(key))
+}
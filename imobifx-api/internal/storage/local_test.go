@@ -0,0 +1,66 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/storage"
+)
+
+func TestLocal_PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	l, err := storage.NewLocal(dir, "/static/images/")
+	require.NoError(t, err)
+
+	require.NoError(t, l.Put(ctx, "a.jpg", strings.NewReader("hello"), 5, "image/jpeg"))
+
+	rc, err := l.Get(ctx, "a.jpg")
+	require.NoError(t, err)
+	b, err := io.ReadAll(rc)
+	require.NoError(t, rc.Close())
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(b))
+
+	require.Equal(t, "/static/images/a.jpg", l.URL("a.jpg"))
+
+	var listed []storage.Object
+	require.NoError(t, l.List(ctx, func(o storage.Object) error {
+		listed = append(listed, o)
+		return nil
+	}))
+	require.Len(t, listed, 1)
+	require.Equal(t, "a.jpg", listed[0].Key)
+	require.Equal(t, int64(5), listed[0].Size)
+
+	require.NoError(t, l.Delete(ctx, "a.jpg"))
+	require.NoError(t, l.Delete(ctx, "a.jpg"))
+
+	_, err = l.Get(ctx, "a.jpg")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries, "no temporary files left behind")
+}
+
+func TestLocal_KeysStayInsideDir(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	dir := filepath.Join(root, "images")
+	l, err := storage.NewLocal(dir, "")
+	require.NoError(t, err)
+
+	require.NoError(t, l.Put(ctx, "../escape.jpg", strings.NewReader("x"), 1, "image/jpeg"))
+
+	_, err = os.Stat(filepath.Join(root, "escape.jpg"))
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "escape.jpg"))
+	require.NoError(t, err)
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	Bucket    string
+	// PublicURL is the prefix objects are downloaded from (a CDN or the
+	// bucket website). Empty means path-style URLs on Endpoint, which only
+	// work if the bucket allows anonymous reads.
+	PublicURL string
+}
+
+// S3 stores objects in an S3-compatible bucket (AWS S3, MinIO, ...).
+type S3 struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3 connects to the bucket, creating it when it does not exist yet.
+func NewS3(ctx context.Context, cfg S3Config) (*S3, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("s3 bucket %q: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("s3 create bucket %q: %w", cfg.Bucket, err)
+		}
+	}
+
+	publicURL := strings.TrimRight(cfg.PublicURL, "/")
+	if publicURL == "" {
+		publicURL = client.EndpointURL().String() + "/" + cfg.Bucket
+	}
+	return &S3{client: client, bucket: cfg.Bucket, publicURL: publicURL}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy; Stat surfaces a missing key right away.
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if isNoSuchKey(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+	if isNoSuchKey(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *S3) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+func (s *S3) List(ctx context.Context, fn func(Object) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+		if err := fn(Object{Key: obj.Key, Size: obj.Size, ModTime: obj.LastModified}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isNoSuchKey(err error) bool {
+	return err != nil && minio.ToErrorResponse(err).Code == minio.NoSuchKey
+}
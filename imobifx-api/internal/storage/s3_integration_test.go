@@ -0,0 +1,68 @@
+//go:build integration
+
+package storage_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/storage"
+)
+
+// Runs against a local MinIO, e.g. `docker compose --profile s3 up minio`
+// with S3_TEST_ENDPOINT=localhost:9000.
+func TestS3_PutGetDelete(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+
+	ctx := context.Background()
+	s, err := storage.NewS3(ctx, storage.S3Config{
+		Endpoint:  endpoint,
+		Region:    "us-east-1",
+		AccessKey: getenvDefault("S3_TEST_ACCESS_KEY", "minioadmin"),
+		SecretKey: getenvDefault("S3_TEST_SECRET_KEY", "minioadmin"),
+		Bucket:    "imobifx-test-" + uuid.NewString()[:8],
+	})
+	require.NoError(t, err)
+
+	key := uuid.NewString() + ".jpg"
+	require.NoError(t, s.Put(ctx, key, strings.NewReader("hello"), 5, "image/jpeg"))
+
+	rc, err := s.Get(ctx, key)
+	require.NoError(t, err)
+	b, err := io.ReadAll(rc)
+	require.NoError(t, rc.Close())
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(b))
+
+	var keys []string
+	require.NoError(t, s.List(ctx, func(o storage.Object) error {
+		keys = append(keys, o.Key)
+		return nil
+	}))
+	require.Equal(t, []string{key}, keys)
+
+	require.True(t, strings.HasSuffix(s.URL(key), "/"+key))
+	require.True(t, strings.HasPrefix(s.URL(key), "http"))
+
+	require.NoError(t, s.Delete(ctx, key))
+	require.NoError(t, s.Delete(ctx, key))
+
+	_, err = s.Get(ctx, key)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func getenvDefault(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,32 @@
+// Package storage abstracts where image files live so every API replica sees
+// the same files. Keys are flat file names such as "<uuid>_large.jpg".
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+// Object describes a stored object as returned by List.
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+type Storage interface {
+	// Put stores r under key, replacing any previous object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object; it returns ErrNotFound when the key is missing.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object. Missing keys are not an error.
+	Delete(ctx context.Context, key string) error
+	// URL is the public URL clients use to fetch the object.
+	URL(key string) string
+	// List calls fn for every stored object, stopping at the first error.
+	List(ctx context.Context, fn func(Object) error) error
+}
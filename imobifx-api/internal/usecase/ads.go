@@ -1,8 +1,13 @@
 package usecase
 
+import "time"
+
 type CreateAdInput struct {
 	Type         string
+	Status       string
 	PriceBRL     float64
+	Title        string
+	Description  string
 	CEP          string
 	Street       string
 	Number       *string
@@ -10,15 +15,134 @@ type CreateAdInput struct {
 	Neighborhood string
 	City         string
 	State        string
+	// Latitude and Longitude are optional; without them the address is
+	// geocoded.
+	Latitude    *float64
+	Longitude   *float64
+	Bedrooms    *int
+	Bathrooms   *int
+	Parking     *int
+	AreaM2      *float64
+	CondoFeeBRL *float64
+	IPTUBRL     *float64
+	PublishAt   *time.Time
+	ExpiresAt   *time.Time
+	// Force creates the ad even when it looks like a duplicate of an
+	// existing one. Only Create reads it.
+	Force bool
 }
 
 type ListAdsInput struct {
 	Page     int
 	PageSize int
 
-	Type     *string
-	City     *string
-	State    *string
-	MinPrice *float64
-	MaxPrice *float64
-}
\ No newline at end of file
+	// Cursor switches to keyset pagination and Page is ignored: empty for
+	// the first page, then the next_cursor of the previous one. WithTotal
+	// adds the (costly) total count to cursor pages.
+	Cursor    *string
+	WithTotal bool
+
+	// Q is a free-text query over title, description and location.
+	Q *string
+
+	// Sort is one of domain.AdSorts; empty means relevance with Q and
+	// newest otherwise.
+	Sort string
+
+	Type         *string
+	City         *string
+	Neighborhood *string
+	CEPPrefix    *string
+	State        *string
+	MinPrice     *float64
+	MaxPrice     *float64
+	Status       *string
+
+	// MinPriceUSD and MaxPriceUSD bound price_usd; they become BRL bounds at
+	// the quote reported in quote_used.
+	MinPriceUSD *float64
+	MaxPriceUSD *float64
+
+	// PriceReducedSince keeps ads whose price was last changed, downwards,
+	// at or after this time.
+	PriceReducedSince *time.Time
+
+	MinBedrooms  *int
+	MaxBedrooms  *int
+	MinBathrooms *int
+	MaxBathrooms *int
+	MinParking   *int
+	MaxParking   *int
+	MinArea      *float64
+	MaxArea      *float64
+	MinCondoFee  *float64
+	MaxCondoFee  *float64
+	MinIPTU      *float64
+	MaxIPTU      *float64
+
+	// Lat/Lng with RadiusKM select ads within that distance; Lat/Lng alone
+	// only enable the distance sort and distance_km. BBox is west, south,
+	// east, north and Polygon lists lng/lat vertices.
+	Lat      *float64
+	Lng      *float64
+	RadiusKM *float64
+	BBox     *[4]float64
+	Polygon  [][2]float64
+
+	Archived bool
+}
+
+type PatchAdInput struct {
+	Type         *string  `json:"type" form:"type"`
+	PriceBRL     *float64 `json:"price_brl" form:"price_brl"`
+	Title        *string  `json:"title" form:"title"`
+	Description  *string  `json:"description" form:"description"`
+	CEP          *string  `json:"cep" form:"cep"`
+	Street       *string  `json:"street" form:"street"`
+	Number       *string  `json:"number" form:"number"`
+	Complement   *string  `json:"complement" form:"complement"`
+	Neighborhood *string  `json:"neighborhood" form:"neighborhood"`
+	City         *string  `json:"city" form:"city"`
+	State        *string  `json:"state" form:"state"`
+	Latitude     *float64 `json:"latitude" form:"latitude"`
+	Longitude    *float64 `json:"longitude" form:"longitude"`
+	Bedrooms     *int     `json:"bedrooms" form:"bedrooms"`
+	Bathrooms    *int     `json:"bathrooms" form:"bathrooms"`
+	Parking      *int     `json:"parking_spaces" form:"parking_spaces"`
+	AreaM2       *float64 `json:"area_m2" form:"area_m2"`
+	CondoFeeBRL  *float64 `json:"condo_fee_brl" form:"condo_fee_brl"`
+	IPTUBRL      *float64 `json:"iptu_brl" form:"iptu_brl"`
+}
+
+type TransitionAdInput struct {
+	Status string `json:"status" form:"status"`
+}
+
+type RenewAdInput struct {
+	ExpiresAt string `json:"expires_at" form:"expires_at"`
+}
+
+type ReorderAdImagesInput struct {
+	ImageIDs []string `json:"image_ids"`
+}
+
+// ImportAdRow is one parsed row of a bulk import. Line is the 1-based line
+// of the row in the source file; Err holds a parse error, in which case
+// Input is empty.
+type ImportAdRow struct {
+	Line  int
+	Input CreateAdInput
+	Err   error
+}
+
+type ImportAdsOptions struct {
+	// AllOrNothing imports no row at all when any row fails.
+	AllOrNothing bool
+	// EnrichCEP fills empty address fields from the CEP lookup.
+	EnrichCEP bool
+	// Geocode locates rows sent without coordinates, as Create does.
+	Geocode bool
+	// Force imports rows that look like duplicates of open ads, or of
+	// earlier rows, instead of reporting them.
+	Force bool
+}
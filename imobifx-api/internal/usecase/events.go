@@ -0,0 +1,10 @@
+package usecase
+
+// EventsInput subscribes to the event stream. LastEventID resumes after the
+// last event the client received, by its stream position (the SSE id).
+type EventsInput struct {
+	Type        *string
+	City        *string
+	State       *string
+	LastEventID *int64
+}
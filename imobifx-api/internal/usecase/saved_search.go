@@ -0,0 +1,10 @@
+package usecase
+
+// SavedSearchInput creates or replaces a saved search. Filters come from the
+// same query string as GET /api/ads, kept verbatim in Query.
+type SavedSearchInput struct {
+	Name    string       `json:"name"`
+	Email   *string      `json:"email"`
+	Filters ListAdsInput `json:"-"`
+	Query   string       `json:"-"`
+}
@@ -0,0 +1,10 @@
+package usecase
+
+// WebhookInput creates or replaces a webhook. Secret is generated on create
+// and kept on replace when omitted; Active defaults to true.
+type WebhookInput struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret *string  `json:"secret"`
+	Active *bool    `json:"active"`
+}
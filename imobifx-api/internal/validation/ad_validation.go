@@ -4,15 +4,29 @@ import (
 	"mime/multipart"
 	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/domain"
 	"github.com/josinaldojr/imobifx-api/internal/errors"
 	"github.com/josinaldojr/imobifx-api/internal/usecase"
 )
 
 var cepRe = regexp.MustCompile(`^\d{8}$`)
 
+// MaxImportRows caps the rows of a single bulk import.
+const MaxImportRows = 5000
+
+// Length limits for the free-text fields, in characters.
+const (
+	MaxTitleLen       = 120
+	MaxDescriptionLen = 5000
+)
+
 func NormalizeCEP(raw string) (string, bool) {
 	d := strings.NewReplacer("-", "", ".", "", " ", "").Replace(raw)
 	return d, cepRe.MatchString(d)
@@ -20,15 +34,37 @@ func NormalizeCEP(raw string) (string, bool) {
 
 func FormatCEP(cep8 string) string { return cep8[:5] + "-" + cep8[5:] }
 
+var cepPrefixRe = regexp.MustCompile(`^\d{1,8}$`)
+
+// CEPRange turns a CEP prefix ("58", "58000", "58000-1") into the first and
+// last formatted CEPs it covers, so a prefix search is a plain range scan.
+func CEPRange(prefix string) (from, to string, ok bool) {
+	d := strings.NewReplacer("-", "", ".", "", " ", "").Replace(prefix)
+	if !cepPrefixRe.MatchString(d) {
+		return "", "", false
+	}
+	pad := 8 - len(d)
+	return FormatCEP(d + strings.Repeat("0", pad)), FormatCEP(d + strings.Repeat("9", pad)), true
+}
+
 func ValidateCreateAdInput(in *usecase.CreateAdInput) error {
 	details := fiber.Map{}
 
 	if in.Type != "SALE" && in.Type != "RENT" {
 		details["type"] = "must be SALE or RENT"
 	}
+	if in.Status != "" && in.Status != domain.AdStatusDraft && in.Status != domain.AdStatusActive {
+		details["status"] = "must be DRAFT or ACTIVE"
+	}
 	if in.PriceBRL < 0 {
 		details["price_brl"] = "must be >= 0"
 	}
+	if utf8.RuneCountInString(in.Title) > MaxTitleLen {
+		details["title"] = "must have at most " + strconv.Itoa(MaxTitleLen) + " characters"
+	}
+	if utf8.RuneCountInString(in.Description) > MaxDescriptionLen {
+		details["description"] = "must have at most " + strconv.Itoa(MaxDescriptionLen) + " characters"
+	}
 
 	cep8, ok := NormalizeCEP(in.CEP)
 	if !ok {
@@ -49,6 +85,36 @@ func ValidateCreateAdInput(in *usecase.CreateAdInput) error {
 	if len(strings.TrimSpace(in.State)) != 2 {
 		details["state"] = "must have 2 letters (UF)"
 	}
+	if (in.Latitude == nil) != (in.Longitude == nil) {
+		details["location"] = "latitude and longitude must be sent together"
+	} else if in.Latitude != nil {
+		if !validLat(*in.Latitude) {
+			details["latitude"] = "must be between -90 and 90"
+		}
+		if !validLng(*in.Longitude) {
+			details["longitude"] = "must be between -180 and 180"
+		}
+	}
+	for field, v := range map[string]*int{"bedrooms": in.Bedrooms, "bathrooms": in.Bathrooms, "parking_spaces": in.Parking} {
+		if v != nil && *v < 0 {
+			details[field] = "must be >= 0"
+		}
+	}
+	if in.AreaM2 != nil && (!finite(*in.AreaM2) || *in.AreaM2 <= 0) {
+		details["area_m2"] = "must be a number > 0"
+	}
+	for field, v := range map[string]*float64{"condo_fee_brl": in.CondoFeeBRL, "iptu_brl": in.IPTUBRL} {
+		if v != nil && (!finite(*v) || *v < 0) {
+			details[field] = "must be a number >= 0"
+		}
+	}
+	if in.ExpiresAt != nil {
+		if !in.ExpiresAt.After(time.Now()) {
+			details["expires_at"] = "must be in the future"
+		} else if in.PublishAt != nil && !in.ExpiresAt.After(*in.PublishAt) {
+			details["expires_at"] = "must be after publish_at"
+		}
+	}
 
 	if len(details) > 0 {
 		return errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", details)
@@ -71,3 +137,27 @@ func ValidateImage(file *multipart.FileHeader, maxBytes int64) error {
 		return errors.New(http.StatusBadRequest, "UNSUPPORTED_IMAGE_TYPE", "Tipo de imagem não suportado (jpeg/png/webp).", fiber.Map{"content_type": ct})
 	}
 }
+
+func ValidateAdStatus(status string) error {
+	if slices.Contains(domain.AdStatuses, status) {
+		return nil
+	}
+	return errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", fiber.Map{"status": "must be one of " + strings.Join(domain.AdStatuses, ", ")})
+}
+
+// ValidateImages checks each file with ValidateImage and caps how many images
+// an ad may hold, counting the ones it already has.
+func ValidateImages(files []*multipart.FileHeader, existing, maxCount int, maxBytes int64) error {
+	if existing+len(files) > maxCount {
+		return errors.New(http.StatusBadRequest, "TOO_MANY_IMAGES", "Quantidade de imagens excede o máximo permitido.", fiber.Map{"max_images": maxCount, "existing": existing, "received": len(files)})
+	}
+	for i, f := range files {
+		if err := ValidateImage(f, maxBytes); err != nil {
+			if appErr, ok := err.(*errors.AppError); ok {
+				appErr.Details = fiber.Map{"index": i, "filename": f.Filename, "reason": appErr.Details}
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package validation_test
 
 import (
+	"math"
 	"mime/multipart"
 	"net/textproto"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -42,6 +44,28 @@ func TestFormatCEP(t *testing.T) {
 	require.Equal(t, "58000-000", validation.FormatCEP("58000000"))
 }
 
+func TestCEPRange(t *testing.T) {
+	from, to, ok := validation.CEPRange("58000")
+	require.True(t, ok)
+	require.Equal(t, "58000-000", from)
+	require.Equal(t, "58000-999", to)
+
+	from, to, ok = validation.CEPRange("58000-1")
+	require.True(t, ok)
+	require.Equal(t, "58000-100", from)
+	require.Equal(t, "58000-199", to)
+
+	from, to, ok = validation.CEPRange("5")
+	require.True(t, ok)
+	require.Equal(t, "50000-000", from)
+	require.Equal(t, "59999-999", to)
+
+	for _, bad := range []string{"", "58a", "580000001"} {
+		_, _, ok := validation.CEPRange(bad)
+		require.False(t, ok, bad)
+	}
+}
+
 func TestValidateCreateAdInput_OK_FormatsCEP(t *testing.T) {
 	in := &usecase.CreateAdInput{
 		Type:         "SALE",
@@ -90,6 +114,27 @@ func TestValidateCreateAdInput_Invalid_ReturnsAppErrorWithDetails(t *testing.T)
 	require.Contains(t, details, "state")
 }
 
+func TestValidateCreateAdInput_Status(t *testing.T) {
+	in := &usecase.CreateAdInput{
+		Type:         "SALE",
+		Status:       "SOLD",
+		PriceBRL:     1,
+		CEP:          "58000000",
+		Street:       "Rua A",
+		Neighborhood: "Centro",
+		City:         "João Pessoa",
+		State:        "PB",
+	}
+
+	err := validation.ValidateCreateAdInput(in)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Contains(t, appErr.Details.(fiber.Map), "status")
+
+	in.Status = "DRAFT"
+	require.NoError(t, validation.ValidateCreateAdInput(in))
+}
+
 func TestValidateImage_Nil_OK(t *testing.T) {
 	require.NoError(t, validation.ValidateImage(nil, 5*1024*1024))
 }
@@ -124,6 +169,27 @@ func TestValidateImage_SupportedTypes(t *testing.T) {
 	}
 }
 
+func TestValidateImages_TooMany(t *testing.T) {
+	files := []*multipart.FileHeader{fileHeader("image/png", 100), fileHeader("image/png", 100)}
+	err := validation.ValidateImages(files, 2, 3, 5*1024*1024)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "TOO_MANY_IMAGES", appErr.Code)
+
+	require.NoError(t, validation.ValidateImages(files, 1, 3, 5*1024*1024))
+}
+
+func TestValidateImages_ReportsIndex(t *testing.T) {
+	files := []*multipart.FileHeader{fileHeader("image/png", 100), fileHeader("application/pdf", 100)}
+	err := validation.ValidateImages(files, 0, 10, 5*1024*1024)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "UNSUPPORTED_IMAGE_TYPE", appErr.Code)
+	require.Equal(t, 1, appErr.Details.(fiber.Map)["index"])
+}
+
 func fileHeader(contentType string, size int64) *multipart.FileHeader {
 	h := make(textproto.MIMEHeader)
 	h.Set("Content-Type", contentType)
@@ -134,3 +200,83 @@ func fileHeader(contentType string, size int64) *multipart.FileHeader {
 		Size:     size,
 	}
 }
+
+func TestValidateCreateAdInput_Attributes(t *testing.T) {
+	valid := func() *usecase.CreateAdInput {
+		return &usecase.CreateAdInput{
+			Type: "SALE", PriceBRL: 250000, CEP: "58000000", Street: "Rua A",
+			Neighborhood: "Centro", City: "João Pessoa", State: "PB",
+		}
+	}
+	n := func(v int) *int { return &v }
+	f := func(v float64) *float64 { return &v }
+
+	in := valid()
+	in.Bedrooms, in.Bathrooms, in.Parking = n(3), n(2), n(0)
+	in.AreaM2, in.CondoFeeBRL, in.IPTUBRL = f(85.5), f(0), f(1200)
+	require.NoError(t, validation.ValidateCreateAdInput(in))
+
+	in = valid()
+	in.Bedrooms, in.Parking = n(-1), n(-2)
+	in.AreaM2, in.IPTUBRL = f(0), f(-10)
+	err := validation.ValidateCreateAdInput(in)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	details := appErr.Details.(fiber.Map)
+	require.Contains(t, details, "bedrooms")
+	require.Contains(t, details, "parking_spaces")
+	require.Contains(t, details, "area_m2")
+	require.Contains(t, details, "iptu_brl")
+	require.NotContains(t, details, "bathrooms")
+
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		in = valid()
+		in.AreaM2, in.CondoFeeBRL, in.IPTUBRL = f(v), f(v), f(v)
+		require.ErrorAs(t, validation.ValidateCreateAdInput(in), &appErr, v)
+		details = appErr.Details.(fiber.Map)
+		require.Contains(t, details, "area_m2", v)
+		require.Contains(t, details, "condo_fee_brl", v)
+		require.Contains(t, details, "iptu_brl", v)
+	}
+}
+
+func TestValidateCreateAdInput_TextLimits(t *testing.T) {
+	in := &usecase.CreateAdInput{
+		Type: "SALE", PriceBRL: 250000, CEP: "58000000", Street: "Rua A",
+		Neighborhood: "Centro", City: "João Pessoa", State: "PB",
+		Title:       strings.Repeat("ã", validation.MaxTitleLen),
+		Description: "Varanda gourmet, perto da praia.",
+	}
+	require.NoError(t, validation.ValidateCreateAdInput(in))
+
+	in.Title += "a"
+	in.Description = strings.Repeat("a", validation.MaxDescriptionLen+1)
+	err := validation.ValidateCreateAdInput(in)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Contains(t, appErr.Details.(fiber.Map), "title")
+	require.Contains(t, appErr.Details.(fiber.Map), "description")
+}
+
+func TestValidateCreateAdInput_Coordinates(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	in := &usecase.CreateAdInput{
+		Type: "SALE", PriceBRL: 250000, CEP: "58000000", Street: "Rua A",
+		Neighborhood: "Centro", City: "João Pessoa", State: "PB",
+		Latitude: f(-7.1195), Longitude: f(-34.8229),
+	}
+	require.NoError(t, validation.ValidateCreateAdInput(in))
+
+	in.Longitude = nil
+	var appErr *errors.AppError
+	require.ErrorAs(t, validation.ValidateCreateAdInput(in), &appErr)
+	require.Contains(t, appErr.Details.(fiber.Map), "location")
+
+	in.Latitude, in.Longitude = f(91), f(-181)
+	require.ErrorAs(t, validation.ValidateCreateAdInput(in), &appErr)
+	details := appErr.Details.(fiber.Map)
+	require.Contains(t, details, "latitude")
+	require.Contains(t, details, "longitude")
+}
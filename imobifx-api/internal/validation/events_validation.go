@@ -0,0 +1,29 @@
+package validation
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// ValidateEventsInput checks the stream filters, as the listing does.
+func ValidateEventsInput(in usecase.EventsInput) error {
+	details := fiber.Map{}
+
+	if in.Type != nil && *in.Type != "SALE" && *in.Type != "RENT" {
+		details["type"] = "must be SALE or RENT"
+	}
+	if in.State != nil && len(*in.State) != 2 {
+		details["state"] = "must have 2 letters (UF)"
+	}
+	if in.LastEventID != nil && *in.LastEventID < 0 {
+		details["last_event_id"] = "must be >= 0"
+	}
+
+	if len(details) > 0 {
+		return errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", details)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+func TestValidateEventsInput_OK(t *testing.T) {
+	typ, city, state, last := "RENT", "João Pessoa", "PB", int64(42)
+	require.NoError(t, validation.ValidateEventsInput(usecase.EventsInput{}))
+	require.NoError(t, validation.ValidateEventsInput(usecase.EventsInput{Type: &typ, City: &city, State: &state, LastEventID: &last}))
+}
+
+func TestValidateEventsInput_Invalid(t *testing.T) {
+	typ, state, last := "LEASE", "PBA", int64(-1)
+	err := validation.ValidateEventsInput(usecase.EventsInput{Type: &typ, State: &state, LastEventID: &last})
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	details := appErr.Details.(fiber.Map)
+	require.Contains(t, details, "type")
+	require.Contains(t, details, "state")
+	require.Contains(t, details, "last_event_id")
+}
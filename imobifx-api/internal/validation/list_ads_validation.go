@@ -1,13 +1,35 @@
 package validation
 
 import (
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/domain"
 	"github.com/josinaldojr/imobifx-api/internal/errors"
 	"github.com/josinaldojr/imobifx-api/internal/usecase"
 )
 
+// ListStatusAll disables the status filter, which otherwise defaults to ACTIVE.
+const ListStatusAll = "ALL"
+
+// MaxQueryLen caps the free-text query, in characters.
+const MaxQueryLen = 200
+
+// MaxPriceFilter caps the price bounds, in BRL or USD. Larger values match
+// nothing a real listing has and only cost work converting between currencies.
+const MaxPriceFilter = 1e12
+
+// Limits for the geographic filters.
+const (
+	MaxRadiusKM        = 500
+	MaxPolygonVertices = 100
+)
+
 func ValidateListAdsInput(in usecase.ListAdsInput) error {
 	details := fiber.Map{}
 
@@ -17,21 +39,49 @@ func ValidateListAdsInput(in usecase.ListAdsInput) error {
 	if in.PageSize < 1 || in.PageSize > 50 {
 		details["page_size"] = "must be between 1 and 50"
 	}
+	if in.Q != nil && utf8.RuneCountInString(*in.Q) > MaxQueryLen {
+		details["q"] = "must have at most " + strconv.Itoa(MaxQueryLen) + " characters"
+	}
+	if in.Sort != "" && !slices.Contains(domain.AdSorts, in.Sort) {
+		details["sort"] = "must be one of " + strings.Join(domain.AdSorts, ", ")
+	} else if in.Sort == domain.AdSortRelevance && in.Q == nil {
+		details["sort"] = "relevance requires q"
+	} else if in.Sort == domain.AdSortDistance && in.Lat == nil {
+		details["sort"] = "distance requires lat and lng"
+	}
 	if in.Type != nil && *in.Type != "SALE" && *in.Type != "RENT" {
 		details["type"] = "must be SALE or RENT"
 	}
-	if in.State != nil && len(*in.State) != 2 {
-		details["state"] = "must have 2 letters (UF)"
+	if in.Status != nil && *in.Status != ListStatusAll && !slices.Contains(domain.AdStatuses, *in.Status) {
+		details["status"] = "must be one of " + strings.Join(domain.AdStatuses, ", ") + " or " + ListStatusAll
 	}
-	if in.MinPrice != nil && *in.MinPrice < 0 {
-		details["min_price"] = "must be >= 0"
+	if in.CEPPrefix != nil {
+		if _, _, ok := CEPRange(*in.CEPPrefix); !ok {
+			details["cep_prefix"] = "must have 1 to 8 digits"
+		}
 	}
-	if in.MaxPrice != nil && *in.MaxPrice < 0 {
-		details["max_price"] = "must be >= 0"
+	if in.State != nil && len(*in.State) != 2 {
+		details["state"] = "must have 2 letters (UF)"
 	}
-	if in.MinPrice != nil && in.MaxPrice != nil && *in.MinPrice > *in.MaxPrice {
-		details["price_range"] = "min_price must be <= max_price"
+	checkRange(details, "price", in.MinPrice, in.MaxPrice)
+	checkRange(details, "price_usd", in.MinPriceUSD, in.MaxPriceUSD)
+	for name, v := range map[string]*float64{
+		"min_price":     in.MinPrice,
+		"max_price":     in.MaxPrice,
+		"min_price_usd": in.MinPriceUSD,
+		"max_price_usd": in.MaxPriceUSD,
+	} {
+		if v != nil && (math.IsNaN(*v) || math.IsInf(*v, 0) || math.Abs(*v) > MaxPriceFilter) {
+			details[name] = "must be a number up to " + strconv.FormatFloat(MaxPriceFilter, 'f', -1, 64)
+		}
 	}
+	checkRange(details, "bedrooms", in.MinBedrooms, in.MaxBedrooms)
+	checkRange(details, "bathrooms", in.MinBathrooms, in.MaxBathrooms)
+	checkRange(details, "parking", in.MinParking, in.MaxParking)
+	checkRange(details, "area", in.MinArea, in.MaxArea)
+	checkRange(details, "condo_fee", in.MinCondoFee, in.MaxCondoFee)
+	checkRange(details, "iptu", in.MinIPTU, in.MaxIPTU)
+	checkGeo(details, in)
 
 	if len(details) > 0 {
 		return errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", details)
@@ -39,3 +89,64 @@ func ValidateListAdsInput(in usecase.ListAdsInput) error {
 
 	return nil
 }
+
+// checkGeo validates the point, radius, bounding box and polygon filters.
+func checkGeo(details fiber.Map, in usecase.ListAdsInput) {
+	if (in.Lat == nil) != (in.Lng == nil) {
+		details["lat"] = "lat and lng must be sent together"
+	} else if in.Lat != nil {
+		if !validLat(*in.Lat) {
+			details["lat"] = "must be between -90 and 90"
+		}
+		if !validLng(*in.Lng) {
+			details["lng"] = "must be between -180 and 180"
+		}
+	}
+	if in.RadiusKM != nil {
+		if in.Lat == nil {
+			details["radius_km"] = "requires lat and lng"
+		} else if !finite(*in.RadiusKM) || *in.RadiusKM <= 0 || *in.RadiusKM > MaxRadiusKM {
+			details["radius_km"] = "must be > 0 and <= " + strconv.Itoa(MaxRadiusKM)
+		}
+	}
+	if b := in.BBox; b != nil {
+		if !validLng(b[0]) || !validLat(b[1]) || !validLng(b[2]) || !validLat(b[3]) {
+			details["bbox"] = "coordinates out of range"
+		} else if b[0] >= b[2] || b[1] >= b[3] {
+			details["bbox"] = "min_lng must be < max_lng and min_lat < max_lat"
+		}
+	}
+	if in.Polygon != nil {
+		if len(in.Polygon) < 3 || len(in.Polygon) > MaxPolygonVertices {
+			details["polygon"] = "must have between 3 and " + strconv.Itoa(MaxPolygonVertices) + " vertices"
+		}
+		for _, v := range in.Polygon {
+			if !validLng(v[0]) || !validLat(v[1]) {
+				details["polygon"] = "coordinates out of range"
+				break
+			}
+		}
+	}
+}
+
+func validLat(v float64) bool { return v >= -90 && v <= 90 }
+
+func validLng(v float64) bool { return v >= -180 && v <= 180 }
+
+// finite reports whether v is neither NaN nor infinite. NaN fails every
+// comparison, so range checks alone let it through.
+func finite(v float64) bool { return !math.IsNaN(v) && !math.IsInf(v, 0) }
+
+// checkRange validates a min_<name>/max_<name> filter pair: both bounds are
+// finite and non-negative and min does not exceed max.
+func checkRange[T int | float64](details fiber.Map, name string, min, max *T) {
+	if min != nil && (!finite(float64(*min)) || *min < 0) {
+		details["min_"+name] = "must be a number >= 0"
+	}
+	if max != nil && (!finite(float64(*max)) || *max < 0) {
+		details["max_"+name] = "must be a number >= 0"
+	}
+	if min != nil && max != nil && *min > *max {
+		details[name+"_range"] = "min_" + name + " must be <= max_" + name
+	}
+}
@@ -1,10 +1,14 @@
 package validation_test
 
 import (
+	"math"
+	"strings"
 	"testing"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/require"
 
+	"github.com/josinaldojr/imobifx-api/internal/errors"
 	"github.com/josinaldojr/imobifx-api/internal/usecase"
 	"github.com/josinaldojr/imobifx-api/internal/validation"
 )
@@ -36,3 +40,131 @@ func TestValidateListAdsInput_InvalidPriceRange(t *testing.T) {
 	in := usecase.ListAdsInput{Page: 1, PageSize: 10, MinPrice: &min, MaxPrice: &max}
 	require.Error(t, validation.ValidateListAdsInput(in))
 }
+
+func TestValidateListAdsInput_Status(t *testing.T) {
+	for _, st := range []string{"ACTIVE", "SOLD", "ALL"} {
+		in := usecase.ListAdsInput{Page: 1, PageSize: 10, Status: &st}
+		require.NoError(t, validation.ValidateListAdsInput(in), "status=%s", st)
+	}
+
+	bad := "GONE"
+	in := usecase.ListAdsInput{Page: 1, PageSize: 10, Status: &bad}
+	require.Error(t, validation.ValidateListAdsInput(in))
+}
+
+func TestValidateListAdsInput_AttributeRanges(t *testing.T) {
+	two, three := 2, 3
+	small, large := 40.0, 120.0
+	in := usecase.ListAdsInput{Page: 1, PageSize: 10, MinBedrooms: &two, MaxBedrooms: &three, MinArea: &small, MaxArea: &large}
+	require.NoError(t, validation.ValidateListAdsInput(in))
+
+	in = usecase.ListAdsInput{Page: 1, PageSize: 10, MinBedrooms: &three, MaxBedrooms: &two}
+	require.Error(t, validation.ValidateListAdsInput(in))
+
+	in = usecase.ListAdsInput{Page: 1, PageSize: 10, MinArea: &large, MaxArea: &small}
+	require.Error(t, validation.ValidateListAdsInput(in))
+
+	neg := -1.0
+	in = usecase.ListAdsInput{Page: 1, PageSize: 10, MaxCondoFee: &neg}
+	require.Error(t, validation.ValidateListAdsInput(in))
+
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		in = usecase.ListAdsInput{Page: 1, PageSize: 10, MinArea: &v, MaxCondoFee: &v, MinIPTU: &v}
+		var appErr *errors.AppError
+		require.ErrorAs(t, validation.ValidateListAdsInput(in), &appErr, v)
+		details := appErr.Details.(fiber.Map)
+		require.Contains(t, details, "min_area", v)
+		require.Contains(t, details, "max_condo_fee", v)
+		require.Contains(t, details, "min_iptu", v)
+	}
+}
+
+func TestValidateListAdsInput_QueryLength(t *testing.T) {
+	q := "varanda gourmet"
+	in := usecase.ListAdsInput{Page: 1, PageSize: 10, Q: &q}
+	require.NoError(t, validation.ValidateListAdsInput(in))
+
+	long := strings.Repeat("a", validation.MaxQueryLen+1)
+	in.Q = &long
+	require.Error(t, validation.ValidateListAdsInput(in))
+}
+
+func TestValidateListAdsInput_Sort(t *testing.T) {
+	for _, sort := range []string{"", "newest", "oldest", "price_asc", "price_desc", "price_usd_asc", "price_usd_desc", "price_per_m2_asc", "price_per_m2_desc"} {
+		in := usecase.ListAdsInput{Page: 1, PageSize: 10, Sort: sort}
+		require.NoError(t, validation.ValidateListAdsInput(in), "sort=%s", sort)
+	}
+
+	in := usecase.ListAdsInput{Page: 1, PageSize: 10, Sort: "created_at; DROP TABLE ads"}
+	require.Error(t, validation.ValidateListAdsInput(in))
+
+	in = usecase.ListAdsInput{Page: 1, PageSize: 10, Sort: "relevance"}
+	require.Error(t, validation.ValidateListAdsInput(in))
+
+	q := "praia"
+	in.Q = &q
+	require.NoError(t, validation.ValidateListAdsInput(in))
+}
+
+func TestValidateListAdsInput_CEPPrefix(t *testing.T) {
+	prefix := "58000-"
+	in := usecase.ListAdsInput{Page: 1, PageSize: 10, CEPPrefix: &prefix}
+	require.NoError(t, validation.ValidateListAdsInput(in))
+
+	bad := "580x"
+	in.CEPPrefix = &bad
+	require.Error(t, validation.ValidateListAdsInput(in))
+}
+
+func TestValidateListAdsInput_Geo(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	valid := func() usecase.ListAdsInput {
+		return usecase.ListAdsInput{
+			Page: 1, PageSize: 10,
+			Lat: f(-7.1153), Lng: f(-34.8641), RadiusKM: f(5),
+			Sort:    "distance",
+			BBox:    &[4]float64{-35, -7.2, -34.7, -7},
+			Polygon: [][2]float64{{-35, -7.2}, {-34.7, -7.2}, {-34.8, -7}},
+		}
+	}
+	require.NoError(t, validation.ValidateListAdsInput(valid()))
+
+	cases := map[string]func(in *usecase.ListAdsInput){
+		"lat":       func(in *usecase.ListAdsInput) { in.Lng = nil; in.RadiusKM = nil; in.Sort = "" },
+		"radius_km": func(in *usecase.ListAdsInput) { in.RadiusKM = f(501) },
+		"sort":      func(in *usecase.ListAdsInput) { in.Lat, in.Lng, in.RadiusKM = nil, nil, nil },
+		"bbox":      func(in *usecase.ListAdsInput) { in.BBox = &[4]float64{-34.7, -7.2, -35, -7} },
+		"polygon":   func(in *usecase.ListAdsInput) { in.Polygon = in.Polygon[:2] },
+	}
+	for field, mutate := range cases {
+		in := valid()
+		mutate(&in)
+		var appErr *errors.AppError
+		require.ErrorAs(t, validation.ValidateListAdsInput(in), &appErr, field)
+		require.Contains(t, appErr.Details.(fiber.Map), field)
+	}
+
+	for _, v := range []float64{math.NaN(), math.Inf(1)} {
+		in := valid()
+		in.RadiusKM = f(v)
+		var appErr *errors.AppError
+		require.ErrorAs(t, validation.ValidateListAdsInput(in), &appErr, v)
+		require.Contains(t, appErr.Details.(fiber.Map), "radius_km", v)
+	}
+}
+
+func TestValidateListAdsInput_PriceBounds(t *testing.T) {
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1e17} {
+		in := usecase.ListAdsInput{Page: 1, PageSize: 10, MinPrice: &v, MaxPriceUSD: &v}
+		err := validation.ValidateListAdsInput(in)
+
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr, v)
+		details := appErr.Details.(fiber.Map)
+		require.Contains(t, details, "min_price", v)
+		require.Contains(t, details, "max_price_usd", v)
+	}
+
+	max := validation.MaxPriceFilter
+	require.NoError(t, validation.ValidateListAdsInput(usecase.ListAdsInput{Page: 1, PageSize: 10, MaxPriceUSD: &max}))
+}
@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+// MaxSavedSearchNameLen caps the name of a saved search, in characters.
+const MaxSavedSearchNameLen = 120
+
+// ValidateSavedSearchInput checks the name and contact of a saved search and
+// its filters, as the listing would. Saved searches alert on ads as they go
+// live, so status and archived make no sense there.
+func ValidateSavedSearchInput(in usecase.SavedSearchInput) error {
+	details := fiber.Map{}
+
+	if n := utf8.RuneCountInString(strings.TrimSpace(in.Name)); n == 0 || n > MaxSavedSearchNameLen {
+		details["name"] = "must have between 1 and " + strconv.Itoa(MaxSavedSearchNameLen) + " characters"
+	}
+	if in.Email != nil {
+		if a, err := mail.ParseAddress(*in.Email); err != nil || a.Address != *in.Email {
+			details["email"] = "must be a valid e-mail address"
+		}
+	}
+	if in.Filters.Status != nil {
+		details["status"] = "not allowed in saved searches"
+	}
+	if in.Filters.Archived {
+		details["archived"] = "not allowed in saved searches"
+	}
+
+	if len(details) > 0 {
+		return errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", details)
+	}
+	return ValidateListAdsInput(in.Filters)
+}
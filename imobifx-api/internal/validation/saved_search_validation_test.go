@@ -0,0 +1,47 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+func TestValidateSavedSearchInput_OK(t *testing.T) {
+	email := "ana@example.com"
+	rent, city, max := "RENT", "João Pessoa", 3000.0
+	in := usecase.SavedSearchInput{
+		Name:    "Aluguel em JP",
+		Email:   &email,
+		Filters: usecase.ListAdsInput{Page: 1, PageSize: 10, Type: &rent, City: &city, MaxPrice: &max},
+	}
+	require.NoError(t, validation.ValidateSavedSearchInput(in))
+}
+
+func TestValidateSavedSearchInput_Invalid(t *testing.T) {
+	email, status := "Ana <ana@example.com>", "PAUSED"
+	in := usecase.SavedSearchInput{
+		Name:    "  ",
+		Email:   &email,
+		Filters: usecase.ListAdsInput{Page: 1, PageSize: 10, Status: &status, Archived: true},
+	}
+	err := validation.ValidateSavedSearchInput(in)
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	details := appErr.Details.(fiber.Map)
+	require.Contains(t, details, "name")
+	require.Contains(t, details, "email")
+	require.Contains(t, details, "status")
+	require.Contains(t, details, "archived")
+}
+
+func TestValidateSavedSearchInput_ChecksFilters(t *testing.T) {
+	min, max := 3000.0, 1000.0
+	in := usecase.SavedSearchInput{Name: "Casas", Filters: usecase.ListAdsInput{Page: 1, PageSize: 10, MinPrice: &min, MaxPrice: &max}}
+	require.Error(t, validation.ValidateSavedSearchInput(in))
+}
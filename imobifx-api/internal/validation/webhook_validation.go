@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/josinaldojr/imobifx-api/internal/domain"
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+)
+
+const (
+	MaxWebhookURLLen    = 2048
+	MinWebhookSecretLen = 16
+	MaxWebhookSecretLen = 256
+)
+
+// ValidateWebhookInput checks the endpoint, the subscribed event types and
+// the secret, when given.
+func ValidateWebhookInput(in usecase.WebhookInput) error {
+	details := fiber.Map{}
+
+	u, err := url.Parse(in.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(in.URL) > MaxWebhookURLLen {
+		details["url"] = "must be an absolute http(s) URL of at most " + strconv.Itoa(MaxWebhookURLLen) + " characters"
+	}
+	if len(in.Events) == 0 {
+		details["events"] = "must not be empty"
+	}
+	for _, e := range in.Events {
+		if !slices.Contains(domain.EventTypes, e) {
+			details["events"] = "must be among " + strings.Join(domain.EventTypes, ", ")
+			break
+		}
+	}
+	if in.Secret != nil && (len(*in.Secret) < MinWebhookSecretLen || len(*in.Secret) > MaxWebhookSecretLen) {
+		details["secret"] = "must have between " + strconv.Itoa(MinWebhookSecretLen) + " and " + strconv.Itoa(MaxWebhookSecretLen) + " characters"
+	}
+
+	if len(details) > 0 {
+		return errors.New(http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos.", details)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josinaldojr/imobifx-api/internal/errors"
+	"github.com/josinaldojr/imobifx-api/internal/usecase"
+	"github.com/josinaldojr/imobifx-api/internal/validation"
+)
+
+func TestValidateWebhookInput_OK(t *testing.T) {
+	secret := "0123456789abcdef"
+	in := usecase.WebhookInput{
+		URL:    "https://crm.example.com/hooks/imobifx",
+		Events: []string{"ad.created", "quote.created"},
+		Secret: &secret,
+	}
+	require.NoError(t, validation.ValidateWebhookInput(in))
+}
+
+func TestValidateWebhookInput_Invalid(t *testing.T) {
+	secret := "short"
+	for _, u := range []string{"", "crm.example.com/hooks", "ftp://crm.example.com", "https:///hooks"} {
+		in := usecase.WebhookInput{URL: u, Events: []string{"ad.sold"}, Secret: &secret}
+		err := validation.ValidateWebhookInput(in)
+
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr, u)
+		details := appErr.Details.(fiber.Map)
+		require.Contains(t, details, "url", u)
+		require.Contains(t, details, "events", u)
+		require.Contains(t, details, "secret", u)
+	}
+}
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ads_deleted_at;
+ALTER TABLE ads DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ads_deleted_at
+  ON ads (deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMIT;
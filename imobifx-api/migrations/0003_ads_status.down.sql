@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ads_status;
+ALTER TABLE ads DROP COLUMN IF EXISTS status;
+
+COMMIT;
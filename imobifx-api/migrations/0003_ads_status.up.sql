@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'ACTIVE'
+  CHECK (status IN ('DRAFT', 'ACTIVE', 'PAUSED', 'SOLD', 'RENTED', 'EXPIRED'));
+
+CREATE INDEX IF NOT EXISTS idx_ads_status ON ads (status);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ads_expires_at;
+DROP INDEX IF EXISTS idx_ads_publish_at;
+ALTER TABLE ads DROP CONSTRAINT IF EXISTS ads_expires_after_publish;
+ALTER TABLE ads DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE ads DROP COLUMN IF EXISTS publish_at;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ NULL;
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ NULL;
+
+ALTER TABLE ads ADD CONSTRAINT ads_expires_after_publish
+  CHECK (publish_at IS NULL OR expires_at IS NULL OR expires_at > publish_at);
+
+CREATE INDEX IF NOT EXISTS idx_ads_publish_at
+  ON ads (publish_at) WHERE status = 'DRAFT' AND publish_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ads_expires_at
+  ON ads (expires_at) WHERE expires_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS ad_images (
+  id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  ad_id       UUID NOT NULL REFERENCES ads (id) ON DELETE CASCADE,
+  path        TEXT NOT NULL,
+  position    INT  NOT NULL CHECK (position >= 0),
+  is_cover    BOOLEAN NOT NULL DEFAULT false,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ad_images_ad_position ON ad_images (ad_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_ad_images_cover ON ad_images (ad_id) WHERE is_cover;
+
+INSERT INTO ad_images (ad_id, path, position, is_cover)
+SELECT id, image_path, 0, true
+FROM ads
+WHERE image_path IS NOT NULL AND image_path <> '';
+
+COMMIT;
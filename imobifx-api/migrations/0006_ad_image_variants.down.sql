@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ad_images_pending;
+
+ALTER TABLE ad_images DROP CONSTRAINT IF EXISTS chk_ad_images_status;
+
+ALTER TABLE ad_images
+  DROP COLUMN IF EXISTS legacy,
+  DROP COLUMN IF EXISTS claimed_at,
+  DROP COLUMN IF EXISTS error,
+  DROP COLUMN IF EXISTS variants,
+  DROP COLUMN IF EXISTS status;
+
+COMMIT;
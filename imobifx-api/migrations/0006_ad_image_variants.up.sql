@@ -0,0 +1,23 @@
+BEGIN;
+
+ALTER TABLE ad_images
+  ADD COLUMN IF NOT EXISTS status     TEXT  NOT NULL DEFAULT 'READY',
+  ADD COLUMN IF NOT EXISTS variants   JSONB NOT NULL DEFAULT '{}'::jsonb,
+  ADD COLUMN IF NOT EXISTS error      TEXT  NULL,
+  ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ NULL,
+  ADD COLUMN IF NOT EXISTS legacy     BOOLEAN NOT NULL DEFAULT false;
+
+-- Images stored before the pipeline were never resized nor stripped of their
+-- metadata: queue them for the worker. Their originals are in the images
+-- storage, not in uploads.
+UPDATE ad_images SET status = 'PENDING', legacy = true;
+
+ALTER TABLE ad_images
+  ADD CONSTRAINT chk_ad_images_status
+  CHECK (status IN ('PENDING', 'PROCESSING', 'READY', 'FAILED'));
+
+CREATE INDEX IF NOT EXISTS idx_ad_images_pending
+  ON ad_images (created_at)
+  WHERE status IN ('PENDING', 'PROCESSING');
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ads_area_m2;
+DROP INDEX IF EXISTS idx_ads_bedrooms;
+ALTER TABLE ads DROP CONSTRAINT IF EXISTS ads_iptu_brl_check;
+ALTER TABLE ads DROP CONSTRAINT IF EXISTS ads_condo_fee_brl_check;
+ALTER TABLE ads DROP CONSTRAINT IF EXISTS ads_area_m2_check;
+ALTER TABLE ads DROP CONSTRAINT IF EXISTS ads_parking_spaces_check;
+ALTER TABLE ads DROP CONSTRAINT IF EXISTS ads_bathrooms_check;
+ALTER TABLE ads DROP CONSTRAINT IF EXISTS ads_bedrooms_check;
+ALTER TABLE ads DROP COLUMN IF EXISTS iptu_brl;
+ALTER TABLE ads DROP COLUMN IF EXISTS condo_fee_brl;
+ALTER TABLE ads DROP COLUMN IF EXISTS area_m2;
+ALTER TABLE ads DROP COLUMN IF EXISTS parking_spaces;
+ALTER TABLE ads DROP COLUMN IF EXISTS bathrooms;
+ALTER TABLE ads DROP COLUMN IF EXISTS bedrooms;
+
+COMMIT;
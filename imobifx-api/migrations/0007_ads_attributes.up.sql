@@ -0,0 +1,20 @@
+BEGIN;
+
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS bedrooms INT NULL;
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS bathrooms INT NULL;
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS parking_spaces INT NULL;
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS area_m2 NUMERIC(12,2) NULL;
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS condo_fee_brl NUMERIC(14,2) NULL;
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS iptu_brl NUMERIC(14,2) NULL;
+
+ALTER TABLE ads ADD CONSTRAINT ads_bedrooms_check CHECK (bedrooms IS NULL OR bedrooms >= 0);
+ALTER TABLE ads ADD CONSTRAINT ads_bathrooms_check CHECK (bathrooms IS NULL OR bathrooms >= 0);
+ALTER TABLE ads ADD CONSTRAINT ads_parking_spaces_check CHECK (parking_spaces IS NULL OR parking_spaces >= 0);
+ALTER TABLE ads ADD CONSTRAINT ads_area_m2_check CHECK (area_m2 IS NULL OR area_m2 > 0);
+ALTER TABLE ads ADD CONSTRAINT ads_condo_fee_brl_check CHECK (condo_fee_brl IS NULL OR condo_fee_brl >= 0);
+ALTER TABLE ads ADD CONSTRAINT ads_iptu_brl_check CHECK (iptu_brl IS NULL OR iptu_brl >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_ads_bedrooms ON ads (bedrooms) WHERE bedrooms IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ads_area_m2 ON ads (area_m2) WHERE area_m2 IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ads_search_tsv;
+ALTER TABLE ads DROP COLUMN IF EXISTS search_tsv;
+ALTER TABLE ads DROP COLUMN IF EXISTS description;
+ALTER TABLE ads DROP COLUMN IF EXISTS title;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS pt_unaccent;
+DROP EXTENSION IF EXISTS unaccent;
+
+COMMIT;
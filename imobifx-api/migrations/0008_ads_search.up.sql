@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- Portuguese stemming on accent-free words, so "condomínio" matches "condominio" and
+-- "varandas" matches "varanda". Passing the configuration explicitly keeps
+-- to_tsvector immutable, which the generated column requires.
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'pt_unaccent') THEN
+    CREATE TEXT SEARCH CONFIGURATION pt_unaccent (COPY = portuguese);
+    ALTER TEXT SEARCH CONFIGURATION pt_unaccent
+      ALTER MAPPING FOR hword, hword_part, word WITH unaccent, portuguese_stem;
+  END IF;
+END
+$$;
+
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '';
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS search_tsv tsvector GENERATED ALWAYS AS (
+  setweight(to_tsvector('pt_unaccent', title), 'A') ||
+  setweight(to_tsvector('pt_unaccent', description), 'B') ||
+  setweight(to_tsvector('pt_unaccent', neighborhood || ' ' || city), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_ads_search_tsv ON ads USING GIN (search_tsv);
+
+COMMIT;
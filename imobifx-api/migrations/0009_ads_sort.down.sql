@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ads_price_per_m2_id;
+DROP INDEX IF EXISTS idx_ads_price_brl_id;
+DROP INDEX IF EXISTS idx_ads_created_at_id;
+ALTER TABLE ads DROP COLUMN IF EXISTS price_per_m2;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS price_per_m2 NUMERIC(14,2)
+  GENERATED ALWAYS AS (round(price_brl / area_m2, 2)) STORED;
+
+-- Every listing order ends in id, so these back the sorts with a stable
+-- tie-breaker.
+CREATE INDEX IF NOT EXISTS idx_ads_created_at_id ON ads (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_ads_price_brl_id ON ads (price_brl, id);
+CREATE INDEX IF NOT EXISTS idx_ads_price_per_m2_id ON ads (price_per_m2, id) WHERE price_per_m2 IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ads_cep;
+DROP INDEX IF EXISTS idx_ads_neighborhood_norm;
+DROP INDEX IF EXISTS idx_ads_city_norm;
+CREATE INDEX IF NOT EXISTS idx_ads_city ON ads (city);
+DROP FUNCTION IF EXISTS immutable_unaccent(text);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- unaccent() is only STABLE because its dictionary could change; naming the
+-- dictionary explicitly lets us declare an IMMUTABLE wrapper for indexes.
+CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text
+  LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+  AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+DROP INDEX IF EXISTS idx_ads_city;
+CREATE INDEX IF NOT EXISTS idx_ads_city_norm ON ads (lower(immutable_unaccent(city)));
+CREATE INDEX IF NOT EXISTS idx_ads_neighborhood_norm ON ads (lower(immutable_unaccent(neighborhood)));
+CREATE INDEX IF NOT EXISTS idx_ads_cep ON ads (cep);
+
+COMMIT;
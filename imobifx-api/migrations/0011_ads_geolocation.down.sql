@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS haversine_km(float8, float8, float8, float8);
+DROP INDEX IF EXISTS idx_ads_location;
+ALTER TABLE ads DROP COLUMN IF EXISTS location;
+ALTER TABLE ads DROP CONSTRAINT IF EXISTS ads_coordinates_check;
+ALTER TABLE ads DROP COLUMN IF EXISTS longitude;
+ALTER TABLE ads DROP COLUMN IF EXISTS latitude;
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION NULL;
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION NULL;
+
+ALTER TABLE ads ADD CONSTRAINT ads_coordinates_check CHECK (
+  (latitude IS NULL AND longitude IS NULL) OR
+  (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180)
+);
+
+-- Box and polygon searches use the GiST operators of the native point type
+-- (x = longitude, y = latitude).
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS location point
+  GENERATED ALWAYS AS (point(longitude, latitude)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_ads_location ON ads USING GIST (location);
+
+-- Great-circle distance in km; domain.DistanceKM uses the same formula.
+CREATE OR REPLACE FUNCTION haversine_km(lat1 float8, lng1 float8, lat2 float8, lng2 float8)
+  RETURNS float8
+  LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+  AS $$
+    SELECT 2 * 6371.0088 * asin(least(1.0, sqrt(
+      power(sin(radians(lat2 - lat1) / 2), 2) +
+      cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lng2 - lng1) / 2), 2)
+    )))
+  $$;
+
+COMMIT;
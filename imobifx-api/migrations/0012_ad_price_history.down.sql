@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS ads_log_price_update ON ads;
+DROP TRIGGER IF EXISTS ads_log_price_insert ON ads;
+DROP TRIGGER IF EXISTS ads_track_price ON ads;
+DROP FUNCTION IF EXISTS ads_log_price();
+DROP FUNCTION IF EXISTS ads_track_price();
+
+DROP INDEX IF EXISTS idx_ads_price_reduced;
+ALTER TABLE ads DROP COLUMN IF EXISTS price_changed_at;
+ALTER TABLE ads DROP COLUMN IF EXISTS previous_price_brl;
+
+DROP TABLE IF EXISTS ad_price_history;
+
+COMMIT;
@@ -0,0 +1,63 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS ad_price_history (
+  id          BIGSERIAL PRIMARY KEY,
+  ad_id       UUID NOT NULL REFERENCES ads (id) ON DELETE CASCADE,
+  price_brl   NUMERIC(14,2) NOT NULL,
+  changed_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ad_price_history_ad ON ad_price_history (ad_id, changed_at, id);
+
+-- The price before the latest change, kept on the row so listings can flag
+-- and filter price drops without reading the history.
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS previous_price_brl NUMERIC(14,2) NULL;
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS price_changed_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ads_price_reduced ON ads (price_changed_at)
+  WHERE previous_price_brl > price_brl;
+
+-- Triggers record every price write, whichever code path performs it. The
+-- change is stamped with the row's updated_at, which every write bumps.
+CREATE OR REPLACE FUNCTION ads_track_price() RETURNS trigger
+  LANGUAGE plpgsql AS $$
+  BEGIN
+    NEW.previous_price_brl := OLD.price_brl;
+    NEW.price_changed_at := NEW.updated_at;
+    RETURN NEW;
+  END
+  $$;
+
+CREATE OR REPLACE FUNCTION ads_log_price() RETURNS trigger
+  LANGUAGE plpgsql AS $$
+  BEGIN
+    INSERT INTO ad_price_history (ad_id, price_brl, changed_at)
+    VALUES (NEW.id, NEW.price_brl, NEW.updated_at);
+    RETURN NULL;
+  END
+  $$;
+
+DROP TRIGGER IF EXISTS ads_track_price ON ads;
+CREATE TRIGGER ads_track_price
+  BEFORE UPDATE OF price_brl ON ads
+  FOR EACH ROW WHEN (OLD.price_brl IS DISTINCT FROM NEW.price_brl)
+  EXECUTE FUNCTION ads_track_price();
+
+DROP TRIGGER IF EXISTS ads_log_price_insert ON ads;
+CREATE TRIGGER ads_log_price_insert
+  AFTER INSERT ON ads
+  FOR EACH ROW EXECUTE FUNCTION ads_log_price();
+
+DROP TRIGGER IF EXISTS ads_log_price_update ON ads;
+CREATE TRIGGER ads_log_price_update
+  AFTER UPDATE OF price_brl ON ads
+  FOR EACH ROW WHEN (OLD.price_brl IS DISTINCT FROM NEW.price_brl)
+  EXECUTE FUNCTION ads_log_price();
+
+-- Existing ads start their history with the price they were created with.
+INSERT INTO ad_price_history (ad_id, price_brl, changed_at)
+SELECT a.id, a.price_brl, a.created_at
+FROM ads a
+WHERE NOT EXISTS (SELECT 1 FROM ad_price_history h WHERE h.ad_id = a.id);
+
+COMMIT;
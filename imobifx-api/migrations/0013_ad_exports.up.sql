@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS ad_exports (
+  id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  format             TEXT NOT NULL CHECK (format IN ('csv', 'jsonl', 'xlsx')),
+  status             TEXT NOT NULL DEFAULT 'PENDING'
+                     CHECK (status IN ('PENDING', 'RUNNING', 'DONE', 'FAILED')),
+  filters            JSONB NOT NULL DEFAULT '{}'::jsonb,
+  file_key           TEXT NULL,
+  row_count          INT NULL,
+  size_bytes         BIGINT NULL,
+  brl_to_usd         NUMERIC(12,6) NULL,
+  quote_effective_at TIMESTAMPTZ NULL,
+  error              TEXT NULL,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  claimed_at         TIMESTAMPTZ NULL,
+  finished_at        TIMESTAMPTZ NULL,
+  expires_at         TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ad_exports_pending
+  ON ad_exports (created_at)
+  WHERE status IN ('PENDING', 'RUNNING');
+
+CREATE INDEX IF NOT EXISTS idx_ad_exports_expires_at
+  ON ad_exports (expires_at)
+  WHERE expires_at IS NOT NULL;
+
+COMMIT;
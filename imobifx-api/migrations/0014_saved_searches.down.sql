@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ads_searches_unmatched;
+ALTER TABLE ads DROP COLUMN IF EXISTS searches_matched_at;
+
+DROP TABLE IF EXISTS saved_search_matches;
+DROP TABLE IF EXISTS saved_searches;
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name        TEXT NOT NULL,
+  email       TEXT NULL,
+  filters     JSONB NOT NULL DEFAULT '{}'::jsonb,
+  query       TEXT NOT NULL DEFAULT '',
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+  saved_search_id  UUID NOT NULL REFERENCES saved_searches (id) ON DELETE CASCADE,
+  ad_id            UUID NOT NULL REFERENCES ads (id) ON DELETE CASCADE,
+  matched_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  notified_at      TIMESTAMPTZ NULL,
+  PRIMARY KEY (saved_search_id, ad_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_matches_list
+  ON saved_search_matches (saved_search_id, matched_at DESC, ad_id);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_matches_pending
+  ON saved_search_matches (saved_search_id, matched_at)
+  WHERE notified_at IS NULL;
+
+-- Set once the saved searches matcher has seen the ad go live. Ads already
+-- on the market are not news to anyone.
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS searches_matched_at TIMESTAMPTZ NULL;
+UPDATE ads SET searches_matched_at = now() WHERE searches_matched_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ads_searches_unmatched
+  ON ads (created_at, id)
+  WHERE searches_matched_at IS NULL AND deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS outbox_events;
+
+COMMIT;
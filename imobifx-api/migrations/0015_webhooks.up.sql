@@ -0,0 +1,56 @@
+BEGIN;
+
+-- Transactional outbox: every ad and quote change writes its event here in
+-- the same transaction as the change itself.
+CREATE TABLE IF NOT EXISTS outbox_events (
+  id             BIGSERIAL PRIMARY KEY,
+  type           TEXT NOT NULL,
+  data           JSONB NOT NULL,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  dispatched_at  TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_undispatched
+  ON outbox_events (id)
+  WHERE dispatched_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at
+  ON outbox_events (created_at);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+  id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  url         TEXT NOT NULL,
+  secret      TEXT NOT NULL,
+  events      TEXT[] NOT NULL,
+  active      BOOLEAN NOT NULL DEFAULT true,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  webhook_id        UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+  event_id          BIGINT NOT NULL REFERENCES outbox_events (id) ON DELETE CASCADE,
+  status            TEXT NOT NULL DEFAULT 'PENDING'
+                    CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+  attempts          INT NOT NULL DEFAULT 0,
+  next_attempt_at   TIMESTAMPTZ NULL DEFAULT now(),
+  last_attempt_at   TIMESTAMPTZ NULL,
+  last_status_code  INT NULL,
+  last_error        TEXT NULL,
+  delivered_at      TIMESTAMPTZ NULL,
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+  ON webhook_deliveries (next_attempt_at)
+  WHERE status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_list
+  ON webhook_deliveries (webhook_id, created_at DESC, id);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event
+  ON webhook_deliveries (event_id);
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+-- Position of each event in the stream, in the order events become visible.
+-- IDs are taken when a transaction inserts its event, so they can commit out
+-- of order; seq is assigned after commit, one sequencing transaction at a
+-- time, so readers resuming after a seq never miss a later one.
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS seq BIGINT NULL;
+
+CREATE SEQUENCE IF NOT EXISTS outbox_events_stream_seq OWNED BY outbox_events.seq;
+
+UPDATE outbox_events SET seq = id WHERE seq IS NULL;
+SELECT setval('outbox_events_stream_seq', COALESCE(max(seq), 0) + 1, false) FROM outbox_events;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_events_seq
+  ON outbox_events (seq);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unsequenced
+  ON outbox_events (id)
+  WHERE seq IS NULL;
+
+COMMIT;